@@ -0,0 +1,126 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// apiKeyRevokeAction revokes an api key immediately, e.g. when the token is leaked, the api key
+// resource keeps the api key until it's revoked in the configuration as well
+type apiKeyRevokeAction struct {
+	factory *providerFactory
+}
+
+type apiKeyRevokeActionModel struct {
+	Organization types.String `tfsdk:"organization"`
+	Name         types.String `tfsdk:"name"`
+}
+
+var _ action.ActionWithConfigure = &apiKeyRevokeAction{}
+
+func NewApiKeyRevokeAction() action.Action {
+	return &apiKeyRevokeAction{}
+}
+
+func (a *apiKeyRevokeAction) Metadata(_ context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_apikey_revoke"
+}
+
+func (a *apiKeyRevokeAction) Schema(_ context.Context, _ action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: descriptions["apikey_revoke"],
+		Attributes: map[string]schema.Attribute{
+			"organization": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["organization"],
+				Validators:  []validator.String{notBlankValidator{}},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["apikey_name"],
+				Validators:  []validator.String{notBlankValidator{}},
+			},
+		},
+	}
+}
+
+func (a *apiKeyRevokeAction) Configure(_ context.Context, req action.ConfigureRequest, _ *action.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	a.factory = getFactoryFromMeta(req.ProviderData)
+}
+
+func (a *apiKeyRevokeAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var data apiKeyRevokeActionModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	namespace := data.Organization.ValueString()
+	name := data.Name.ValueString()
+	clientSet, err := getClientSet(a.factory)
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_REVOKE_API_KEY", err.Error())
+		return
+	}
+	apiKeys := clientSet.CloudV1alpha1().APIKeys(namespace)
+	apiKey, err := apiKeys.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_READ_API_KEY", err.Error())
+		return
+	}
+	if apiKey.Status.RevokedAt != nil {
+		sendActionProgress(resp, fmt.Sprintf("The api key %s/%s is already revoked", namespace, name))
+		return
+	}
+	_, err = retryUpdateOnConflict(ctx, a.factory, name, apiKeys.Get, apiKeys.Update,
+		func(apiKey *cloudv1alpha1.APIKey) error {
+			apiKey.Spec.Revoke = true
+			return nil
+		}, metav1.UpdateOptions{})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_REVOKE_API_KEY", err.Error())
+		return
+	}
+	sendActionProgress(resp, fmt.Sprintf("Revoking the api key %s/%s", namespace, name))
+
+	err = retry.RetryContext(ctx, 10*time.Minute, func() *retry.RetryError {
+		apiKey, err := apiKeys.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return retry.NonRetryableError(fmt.Errorf("ERROR_READ_API_KEY: %w", err))
+		}
+		if apiKey.Status.RevokedAt == nil {
+			return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_REVOKE_API_KEY"))
+		}
+		return nil
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_RETRY_REVOKE_API_KEY", err.Error())
+		return
+	}
+	sendActionProgress(resp, fmt.Sprintf("The api key %s/%s is revoked", namespace, name))
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// pulsarClusterRestartAction restarts the pods of a pulsar cluster, e.g. to apply a custom config
+// which is only loaded on startup, and waits for the cluster to be ready again
+type pulsarClusterRestartAction struct {
+	factory *providerFactory
+}
+
+type pulsarClusterRestartActionModel struct {
+	Organization types.String `tfsdk:"organization"`
+	Name         types.String `tfsdk:"name"`
+}
+
+var _ action.ActionWithConfigure = &pulsarClusterRestartAction{}
+
+func NewPulsarClusterRestartAction() action.Action {
+	return &pulsarClusterRestartAction{}
+}
+
+func (a *pulsarClusterRestartAction) Metadata(
+	_ context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_pulsar_cluster_restart"
+}
+
+func (a *pulsarClusterRestartAction) Schema(_ context.Context, _ action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: descriptions["pulsar_cluster_restart"],
+		Attributes: map[string]schema.Attribute{
+			"organization": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["organization"],
+				Validators:  []validator.String{notBlankValidator{}},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["cluster_name"],
+				Validators:  []validator.String{notBlankValidator{}},
+			},
+		},
+	}
+}
+
+func (a *pulsarClusterRestartAction) Configure(
+	_ context.Context, req action.ConfigureRequest, _ *action.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	a.factory = getFactoryFromMeta(req.ProviderData)
+}
+
+func (a *pulsarClusterRestartAction) Invoke(ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var data pulsarClusterRestartActionModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	namespace := data.Organization.ValueString()
+	name := data.Name.ValueString()
+	clientSet, err := getClientSet(a.factory)
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_RESTART_PULSAR_CLUSTER", err.Error())
+		return
+	}
+	pulsarClusters := clientSet.CloudV1alpha1().PulsarClusters(namespace)
+	// The conditions only keep the seconds of the transition time
+	restartedAt := time.Now().UTC().Truncate(time.Second)
+	_, err = retryUpdateOnConflict(ctx, a.factory, name, pulsarClusters.Get, pulsarClusters.Update,
+		func(pulsarCluster *cloudv1alpha1.PulsarCluster) error {
+			if pulsarCluster.Annotations == nil {
+				pulsarCluster.Annotations = make(map[string]string)
+			}
+			pulsarCluster.Annotations[PulsarClusterRestartedAtAnnotation] = restartedAt.Format(time.RFC3339)
+			return nil
+		}, metav1.UpdateOptions{
+			FieldManager: "terraform-update",
+		})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_RESTART_PULSAR_CLUSTER", err.Error())
+		return
+	}
+	sendActionProgress(resp, fmt.Sprintf("Restarting the pulsar cluster %s/%s", namespace, name))
+
+	// The annotation doesn't change the spec, so the generation of the cluster stays the same. The
+	// cluster is restarted once its Ready condition turns True again after the restart was requested
+	err = retry.RetryContext(ctx, 30*time.Minute, func() *retry.RetryError {
+		pulsarCluster, err := pulsarClusters.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return retry.NonRetryableError(fmt.Errorf("ERROR_READ_PULSAR_CLUSTER: %w", err))
+		}
+		for _, condition := range pulsarCluster.Status.Conditions {
+			if condition.Type == "Ready" && condition.Status == "True" &&
+				!condition.LastTransitionTime.Time.Before(restartedAt) {
+				return nil
+			}
+		}
+		return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_RESTART_PULSAR_CLUSTER"))
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_RETRY_RESTART_PULSAR_CLUSTER", err.Error())
+		return
+	}
+	sendActionProgress(resp, fmt.Sprintf("The pulsar cluster %s/%s is ready", namespace, name))
+}
+
+// sendActionProgress reports the progress of the action, the callback is nil when Terraform
+// doesn't listen to the progress events
+func sendActionProgress(resp *action.InvokeResponse, message string) {
+	if resp.SendProgress != nil {
+		resp.SendProgress(action.InvokeProgressEvent{Message: message})
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/action/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// serviceAccountRotateKeyAction rolls the private key of a service account, the new private key is
+// read by the next refresh of the service account resource and data source
+type serviceAccountRotateKeyAction struct {
+	factory *providerFactory
+}
+
+type serviceAccountRotateKeyActionModel struct {
+	Organization types.String `tfsdk:"organization"`
+	Name         types.String `tfsdk:"name"`
+}
+
+var _ action.ActionWithConfigure = &serviceAccountRotateKeyAction{}
+
+func NewServiceAccountRotateKeyAction() action.Action {
+	return &serviceAccountRotateKeyAction{}
+}
+
+func (a *serviceAccountRotateKeyAction) Metadata(
+	_ context.Context, req action.MetadataRequest, resp *action.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_account_rotate_key"
+}
+
+func (a *serviceAccountRotateKeyAction) Schema(
+	_ context.Context, _ action.SchemaRequest, resp *action.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: descriptions["service_account_rotate_key"],
+		Attributes: map[string]schema.Attribute{
+			"organization": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["organization"],
+				Validators:  []validator.String{notBlankValidator{}},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["service_account_name"],
+				Validators:  []validator.String{notBlankValidator{}},
+			},
+		},
+	}
+}
+
+func (a *serviceAccountRotateKeyAction) Configure(
+	_ context.Context, req action.ConfigureRequest, _ *action.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	a.factory = getFactoryFromMeta(req.ProviderData)
+}
+
+func (a *serviceAccountRotateKeyAction) Invoke(
+	ctx context.Context, req action.InvokeRequest, resp *action.InvokeResponse) {
+	var data serviceAccountRotateKeyActionModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	namespace := data.Organization.ValueString()
+	name := data.Name.ValueString()
+	clientSet, err := getClientSet(a.factory)
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_ROTATE_SERVICE_ACCOUNT_KEY", err.Error())
+		return
+	}
+	serviceAccounts := clientSet.CloudV1alpha1().ServiceAccounts(namespace)
+	serviceAccount, err := serviceAccounts.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_READ_SERVICE_ACCOUNT", err.Error())
+		return
+	}
+	privateKeyData := serviceAccount.Status.PrivateKeyData
+	rotatedAt := time.Now().UTC().Format(time.RFC3339)
+	_, err = retryUpdateOnConflict(ctx, a.factory, name, serviceAccounts.Get, serviceAccounts.Update,
+		func(serviceAccount *cloudv1alpha1.ServiceAccount) error {
+			if serviceAccount.Annotations == nil {
+				serviceAccount.Annotations = make(map[string]string)
+			}
+			serviceAccount.Annotations[ServiceAccountKeyRotatedAtAnnotation] = rotatedAt
+			return nil
+		}, metav1.UpdateOptions{
+			FieldManager: "terraform-update",
+		})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_ROTATE_SERVICE_ACCOUNT_KEY", err.Error())
+		return
+	}
+	sendActionProgress(resp, fmt.Sprintf("Rotating the key of the service account %s/%s", namespace, name))
+
+	// The annotation doesn't change the spec, so the generation of the service account stays the same.
+	// The key is rotated once the API server issues a private key other than the previous one
+	err = retry.RetryContext(ctx, 10*time.Minute, func() *retry.RetryError {
+		serviceAccount, err := serviceAccounts.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return retry.NonRetryableError(fmt.Errorf("ERROR_READ_SERVICE_ACCOUNT: %w", err))
+		}
+		ready := false
+		for _, condition := range serviceAccount.Status.Conditions {
+			if condition.Type == "Ready" && condition.Status == "True" {
+				ready = true
+			}
+		}
+		if !ready || serviceAccount.Status.PrivateKeyData == "" ||
+			serviceAccount.Status.PrivateKeyData == privateKeyData {
+			return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_ROTATE_SERVICE_ACCOUNT_KEY"))
+		}
+		return nil
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_RETRY_ROTATE_SERVICE_ACCOUNT_KEY", err.Error())
+		return
+	}
+	sendActionProgress(resp, fmt.Sprintf("The key of the service account %s/%s is rotated", namespace, name))
+}
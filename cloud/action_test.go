@@ -0,0 +1,154 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	cloudfake "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset/fake"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func invokeTestAction(
+	ctx context.Context, a action.Action, meta interface{}, organization, name string) diag.Diagnostics {
+	a.(action.ActionWithConfigure).Configure(ctx, action.ConfigureRequest{ProviderData: meta}, &action.ConfigureResponse{})
+	schemaResp := &action.SchemaResponse{}
+	a.Schema(ctx, action.SchemaRequest{}, schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx)
+	resp := &action.InvokeResponse{}
+	a.Invoke(ctx, action.InvokeRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: nullObjectValue(objectType, map[string]tftypes.Value{
+			"organization": tftypes.NewValue(tftypes.String, organization),
+			"name":         tftypes.NewValue(tftypes.String, name),
+		})},
+	}, resp)
+	return resp.Diagnostics
+}
+
+// reconcileUpdate is a reactor which starts a new generation of the updated objects like the API server
+// when their spec changes, the controller reconciles the objects and reports them as ready when ready
+// is true
+func reconcileUpdate(
+	clientSet *cloudfake.Clientset, ready bool, reconcile func(runtime.Object)) k8stesting.ReactionFunc {
+	return func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := action.(k8stesting.UpdateAction).GetObject()
+		accessor := obj.(metav1.Object)
+		existing, err := clientSet.Tracker().Get(action.GetResource(), action.GetNamespace(), accessor.GetName())
+		if err != nil {
+			return false, nil, nil
+		}
+		if !equality.Semantic.DeepEqual(specOf(existing), specOf(obj)) {
+			accessor.SetGeneration(existing.(metav1.Object).GetGeneration() + 1)
+		}
+		if !ready {
+			return false, nil, nil
+		}
+		if reconcile != nil {
+			reconcile(obj)
+		}
+		return false, nil, setTestReadyConditionAt(obj, "True", time.Now())
+	}
+}
+
+func specOf(obj runtime.Object) interface{} {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil
+	}
+	return object["spec"]
+}
+
+func newTestPulsarCluster(t *testing.T) *cloudv1alpha1.PulsarCluster {
+	pulsarCluster := &cloudv1alpha1.PulsarCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "sndev", Generation: 1},
+	}
+	assert.NoError(t, setTestReadyCondition(pulsarCluster, "True"))
+	return pulsarCluster
+}
+
+func TestPulsarClusterRestartAction(t *testing.T) {
+	ctx := context.Background()
+	meta, clientSet := newFakeProviderMeta(newTestPulsarCluster(t))
+	clientSet.PrependReactor("update", "pulsarclusters", reconcileUpdate(clientSet, true, nil))
+	diags := invokeTestAction(ctx, NewPulsarClusterRestartAction(), meta, "sndev", "cluster")
+	assert.False(t, diags.HasError(), diags)
+	pulsarCluster, err := clientSet.CloudV1alpha1().PulsarClusters("sndev").Get(ctx, "cluster", metav1.GetOptions{})
+	assert.NoError(t, err)
+	// The annotation doesn't start a new generation of the cluster
+	assert.Equal(t, int64(1), pulsarCluster.Generation)
+	assert.NotEmpty(t, pulsarCluster.Annotations[PulsarClusterRestartedAtAnnotation])
+
+	// The Ready condition from before the restart doesn't mean the cluster is restarted
+	pulsarCluster = newTestPulsarCluster(t)
+	assert.NoError(t, setTestReadyConditionAt(pulsarCluster, "True", time.Now().Add(-time.Hour)))
+	meta, clientSet = newFakeProviderMeta(pulsarCluster)
+	clientSet.PrependReactor("update", "pulsarclusters", reconcileUpdate(clientSet, false, nil))
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	diags = invokeTestAction(timeoutCtx, NewPulsarClusterRestartAction(), meta, "sndev", "cluster")
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "ERROR_RETRY_RESTART_PULSAR_CLUSTER", diags[0].Summary())
+	}
+
+	diags = invokeTestAction(ctx, NewPulsarClusterRestartAction(), meta, "sndev", "missing")
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "ERROR_RESTART_PULSAR_CLUSTER", diags[0].Summary())
+	}
+}
+
+func TestServiceAccountRotateKeyAction(t *testing.T) {
+	ctx := context.Background()
+	newServiceAccount := func() *cloudv1alpha1.ServiceAccount {
+		serviceAccount := &cloudv1alpha1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "service-account", Namespace: "sndev", Generation: 1},
+		}
+		assert.NoError(t, setTestReadyCondition(serviceAccount, "True"))
+		serviceAccount.Status.PrivateKeyData = "old-key"
+		return serviceAccount
+	}
+	meta, clientSet := newFakeProviderMeta(newServiceAccount())
+	clientSet.PrependReactor("update", "serviceaccounts", reconcileUpdate(clientSet, true, func(obj runtime.Object) {
+		obj.(*cloudv1alpha1.ServiceAccount).Status.PrivateKeyData = "new-key"
+	}))
+	diags := invokeTestAction(ctx, NewServiceAccountRotateKeyAction(), meta, "sndev", "service-account")
+	assert.False(t, diags.HasError(), diags)
+	serviceAccount, err := clientSet.CloudV1alpha1().ServiceAccounts("sndev").Get(
+		ctx, "service-account", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "new-key", serviceAccount.Status.PrivateKeyData)
+	assert.Equal(t, int64(1), serviceAccount.Generation)
+	assert.NotEmpty(t, serviceAccount.Annotations[ServiceAccountKeyRotatedAtAnnotation])
+
+	// The key isn't rotated until the service account is ready with a new key
+	meta, clientSet = newFakeProviderMeta(newServiceAccount())
+	clientSet.PrependReactor("update", "serviceaccounts", reconcileUpdate(clientSet, true, nil))
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	diags = invokeTestAction(timeoutCtx, NewServiceAccountRotateKeyAction(), meta, "sndev", "service-account")
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "ERROR_RETRY_ROTATE_SERVICE_ACCOUNT_KEY", diags[0].Summary())
+	}
+}
@@ -17,7 +17,6 @@ package cloud
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"strings"
 	"testing"
 	"time"
@@ -28,13 +27,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var apiKeyGeneratedName = fmt.Sprintf("ak-%d", rand.Intn(1000))
-
 func TestApiKey(t *testing.T) {
+	var apiKeyGeneratedName = fmt.Sprintf("tf-acc-ak-%d", testAccRandomInt(t, 1000))
 	resource.Test(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckApiKeyDestroy,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckApiKeyDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourceApiKey(
@@ -0,0 +1,520 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	"github.com/streamnative/terraform-provider-streamnative/cloud/util"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// cassetteModeEnv records the API calls of the acceptance tests into the cassettes when it's
+	// "record", and replays the cassettes instead of calling the API server when it's "replay"
+	cassetteModeEnv = "STREAMNATIVE_CASSETTE_MODE"
+	cassetteRecord  = "record"
+	cassetteReplay  = "replay"
+	cassetteDir     = "testdata/cassettes"
+	// cassetteEncryptionKeyFile is the fixed key which the tokens of the api keys are encrypted with in
+	// the cassette modes, so the fake tokens of the cassettes can be decrypted when they are replayed
+	cassetteEncryptionKeyFile = "testdata/cassette_encryption_key.pem"
+)
+
+// testAccCassettes is the recorder of the acceptance tests when the cassette mode is set
+var testAccCassettes *cassetteRecorder
+
+func init() {
+	switch mode := os.Getenv(cassetteModeEnv); mode {
+	case "":
+	case cassetteRecord, cassetteReplay:
+		testAccCassettes = &cassetteRecorder{mode: mode}
+		wrapTestTransport = testAccCassettes.wrap
+		generateEncryptionKey = loadCassetteEncryptionKey
+		if mode == cassetteReplay {
+			// The API server is never called, the access token only skips the authentication
+			for _, name := range []string{
+				"KEY_FILE_PATH", "KEY_FILE_DATA", "GLOBAL_DEFAULT_CLIENT_ID", "GLOBAL_DEFAULT_CLIENT_SECRET",
+			} {
+				_ = os.Unsetenv(name)
+			}
+			_ = os.Setenv("STREAMNATIVE_ACCESS_TOKEN", "replayed-access-token")
+		}
+	default:
+		panic(fmt.Sprintf("%s must be %q or %q, got %q", cassetteModeEnv, cassetteRecord, cassetteReplay, mode))
+	}
+}
+
+// cassette is the list of the API calls of a test
+type cassette struct {
+	Interactions []*cassetteInteraction `json:"interactions"`
+}
+
+// cassetteInteraction is an API call, the URL is the path and the query without the host so the
+// cassettes can be replayed against any environment
+type cassetteInteraction struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	RequestBody  interface{} `json:"requestBody,omitempty"`
+	StatusCode   int         `json:"statusCode"`
+	ResponseBody interface{} `json:"responseBody,omitempty"`
+	replayed     bool
+}
+
+// cassetteRecorder records the API calls of the current test into its cassette, or replays them. The
+// calls are matched by the method and the URL in the recorded order, as Terraform changes the resources
+// in parallel, and the request bodies must be the same as the recorded ones.
+type cassetteRecorder struct {
+	mode string
+
+	mu       sync.Mutex
+	path     string
+	cassette *cassette
+	errs     []error
+	// fakes maps the secrets issued by the API server to their fakes, and sent are the secrets sent
+	// by the test, e.g. the data of the secrets, which are recorded as they are
+	fakes map[string]string
+	sent  map[string]bool
+}
+
+// start loads or starts the cassette of the test, the cassette is saved and the requests which don't
+// match the cassette fail the test when the test ends
+func (r *cassetteRecorder) start(t *testing.T) {
+	r.startFile(t, filepath.Join(cassetteDir, strings.ReplaceAll(t.Name(), "/", "_")+".json"))
+}
+
+func (r *cassetteRecorder) startFile(t *testing.T, path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.path = path
+	r.cassette = &cassette{}
+	r.errs = nil
+	r.fakes = map[string]string{}
+	r.sent = map[string]bool{}
+	if r.mode == cassetteReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read the cassette, record it with %s=%s: %v", cassetteModeEnv, cassetteRecord, err)
+		}
+		if err = json.Unmarshal(data, r.cassette); err != nil {
+			t.Fatalf("failed to parse the cassette %s: %v", path, err)
+		}
+	}
+	t.Cleanup(func() {
+		if err := r.stop(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func (r *cassetteRecorder) stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	defer func() {
+		r.cassette = nil
+		r.errs = nil
+	}()
+	if r.mode == cassetteReplay {
+		return joinCassetteErrors(r.errs)
+	}
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0644)
+}
+
+func joinCassetteErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Errorf("the API calls don't match the cassette:\n%s", strings.Join(messages, "\n"))
+}
+
+func (r *cassetteRecorder) wrap(base http.RoundTripper) http.RoundTripper {
+	return &cassetteTransport{base: base, recorder: r}
+}
+
+type cassetteTransport struct {
+	base     http.RoundTripper
+	recorder *cassetteRecorder
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	interaction := &cassetteInteraction{
+		Method:      req.Method,
+		URL:         req.URL.RequestURI(),
+		RequestBody: t.recorder.scrubBody(body, true),
+	}
+	if t.recorder.mode == cassetteReplay {
+		return t.recorder.replay(req, interaction)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	body, err = readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	interaction.StatusCode = resp.StatusCode
+	interaction.ResponseBody = t.recorder.scrubBody(body, false)
+	t.recorder.record(interaction)
+	return resp, nil
+}
+
+func (r *cassetteRecorder) record(interaction *cassetteInteraction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cassette != nil {
+		r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	}
+}
+
+func (r *cassetteRecorder) replay(req *http.Request, interaction *cassetteInteraction) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cassette == nil {
+		return nil, fmt.Errorf("no cassette is replayed for %s %s", interaction.Method, interaction.URL)
+	}
+	for _, recorded := range r.cassette.Interactions {
+		if recorded.replayed || recorded.Method != interaction.Method || recorded.URL != interaction.URL {
+			continue
+		}
+		recorded.replayed = true
+		if !reflect.DeepEqual(recorded.RequestBody, interaction.RequestBody) {
+			requestBody, _ := json.Marshal(interaction.RequestBody)
+			recordedBody, _ := json.Marshal(recorded.RequestBody)
+			err := fmt.Errorf("the request body of %s %s is %s, the cassette expects %s",
+				interaction.Method, interaction.URL, requestBody, recordedBody)
+			r.errs = append(r.errs, err)
+			return nil, err
+		}
+		body, err := json.Marshal(recorded.ResponseBody)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode: recorded.StatusCode,
+			Status:     fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+			Request:    req,
+		}, nil
+	}
+	err := fmt.Errorf("the cassette has no more calls of %s %s", interaction.Method, interaction.URL)
+	r.errs = append(r.errs, err)
+	return nil, err
+}
+
+// scrubBody decodes the JSON body and replaces the secrets with their fakes, any other content is
+// scrubbed entirely as it's not sent by the API clients
+func (r *cassetteRecorder) scrubBody(body []byte, request bool) interface{} {
+	if len(body) == 0 {
+		return nil
+	}
+	var object interface{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return logMaskedValue
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.scrubValue(object, "", request)
+}
+
+// scrubValue scrubs the strings of the sensitive fields, secretKey is the sensitive field which the
+// value belongs to, e.g. "data" for the values of the data of a secret
+func (r *cassetteRecorder) scrubValue(value interface{}, secretKey string, request bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			fieldSecretKey := secretKey
+			if isSensitiveLogField(key) {
+				fieldSecretKey = key
+			}
+			v[key] = r.scrubValue(field, fieldSecretKey, request)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.scrubValue(item, secretKey, request)
+		}
+	case string:
+		if secretKey != "" {
+			return r.fakeSecret(secretKey, v, request)
+		}
+	}
+	return value
+}
+
+// fakeSecret returns the fake of a secret. The secrets sent by the test are kept so the replayed
+// objects match the configurations of the test, and the secrets issued by the API server are replaced
+// with fakes of the same format, e.g. a token encrypted with the cassette encryption key, so the
+// replayed secrets can be used like the recorded ones.
+func (r *cassetteRecorder) fakeSecret(key, value string, request bool) string {
+	if fake, ok := r.fakes[value]; ok {
+		return fake
+	}
+	if value == "" || r.sent[value] {
+		return value
+	}
+	if request {
+		r.sent[value] = true
+		return value
+	}
+	fake, err := newCassetteFakeSecret(key, len(r.fakes)+1)
+	if err != nil {
+		fake = logMaskedValue
+	}
+	r.fakes[value] = fake
+	return fake
+}
+
+// newCassetteFakeSecret returns the index-th fake secret of the field
+func newCassetteFakeSecret(key string, index int) (string, error) {
+	switch key {
+	case "jwe":
+		privateKey, err := loadCassetteEncryptionKey()
+		if err != nil {
+			return "", err
+		}
+		token, err := jwe.Encrypt(
+			[]byte(fmt.Sprintf("fake-token-%d", index)), jwe.WithKey(jwa.RSA_OAEP, &privateKey.PublicKey))
+		return string(token), err
+	case "privateKeyData", "private_key_data":
+		keyFile, err := json.Marshal(map[string]string{
+			"type":          "sn_service_account",
+			"client_id":     fmt.Sprintf("fake-client-id-%d", index),
+			"client_secret": fmt.Sprintf("fake-client-secret-%d", index),
+			"client_email":  fmt.Sprintf("fake-%d@fake.auth.streamnative.cloud", index),
+			"issuer_url":    "https://auth.streamnative.cloud/",
+		})
+		return base64.StdEncoding.EncodeToString(keyFile), err
+	default:
+		return fmt.Sprintf("fake-%s-%d", key, index), nil
+	}
+}
+
+// loadCassetteEncryptionKey returns the key which the api keys are created with in the cassette modes
+func loadCassetteEncryptionKey() (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(cassetteEncryptionKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return util.ImportPrivateKey(string(data))
+}
+
+var (
+	testAccRandMu    sync.Mutex
+	testAccRandCalls = map[string]int64{}
+)
+
+// testAccRandomInt returns a random number in [0, n) for the names of the acceptance tests. In the
+// cassette modes the number is seeded by the name of the test and the index of the call in the test,
+// so the test generates the same names when it's recorded and replayed, whichever tests run before it.
+func testAccRandomInt(t *testing.T, n int) int {
+	if os.Getenv(cassetteModeEnv) == "" {
+		return rand.Intn(n)
+	}
+	testAccRandMu.Lock()
+	defer testAccRandMu.Unlock()
+	name := t.Name()
+	if _, ok := testAccRandCalls[name]; !ok {
+		t.Cleanup(func() {
+			testAccRandMu.Lock()
+			defer testAccRandMu.Unlock()
+			delete(testAccRandCalls, name)
+		})
+	}
+	testAccRandCalls[name]++
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(name))
+	return rand.New(rand.NewSource(int64(hash.Sum64()) + testAccRandCalls[name])).Intn(n)
+}
+
+// testAccRandomString returns a random alphanumeric string of the length for the names of the
+// acceptance tests, the strings are deterministic per test in the cassette modes
+func testAccRandomString(t *testing.T, length int) string {
+	result := make([]byte, length)
+	for i := range result {
+		result[i] = acctest.CharSetAlphaNum[testAccRandomInt(t, len(acctest.CharSetAlphaNum))]
+	}
+	return string(result)
+}
+
+func TestCassetteRecordReplay(t *testing.T) {
+	server := newFakeAPIServer(0)
+	t.Cleanup(server.Close)
+	t.Cleanup(func() {
+		wrapTestTransport = nil
+	})
+	for _, name := range []string{"KEY_FILE_PATH", "KEY_FILE_DATA", "STREAMNATIVE_ACCESS_TOKEN"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("GLOBAL_DEFAULT_API_SERVER", server.URL)
+	t.Setenv("GLOBAL_DEFAULT_ISSUER", server.URL+"/")
+	t.Setenv("GLOBAL_DEFAULT_AUDIENCE", server.URL)
+	t.Setenv("GLOBAL_DEFAULT_CLIENT_ID", fakeAPIServerClientID)
+	t.Setenv("GLOBAL_DEFAULT_CLIENT_SECRET", fakeAPIServerClientSecret)
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ctx := context.Background()
+
+	// run creates and updates an instance with the provider configured from the environment
+	run := func(t *testing.T, mode, poolName string) error {
+		recorder := &cassetteRecorder{mode: mode}
+		wrapTestTransport = recorder.wrap
+		recorder.startFile(t, path)
+		d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{})
+		meta, diags := providerConfigure(ctx, d, "")
+		assert.False(t, diags.HasError(), diags)
+		clientSet, err := getClientSet(getFactoryFromMeta(meta))
+		assert.NoError(t, err)
+		instances := clientSet.CloudV1alpha1().PulsarInstances("sndev")
+		_, err = instances.Create(ctx, &cloudv1alpha1.PulsarInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "cassette-instance"},
+			Spec: cloudv1alpha1.PulsarInstanceSpec{
+				PoolRef: &cloudv1alpha1.PoolRef{Name: poolName, Namespace: "streamnative"},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return err
+		}
+		instance, err := instances.Get(ctx, "cassette-instance", metav1.GetOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, poolName, instance.Spec.PoolRef.Name)
+		return nil
+	}
+
+	t.Run("record", func(t *testing.T) {
+		assert.NoError(t, run(t, cassetteRecord, "shared-gcp-prod"))
+	})
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), fakeAPIServerAccessToken)
+	assert.Contains(t, string(data), "/apis/cloud.streamnative.io/v1alpha1/namespaces/sndev/pulsarinstances")
+
+	// The API server is not called when the cassette is replayed
+	server.Close()
+	t.Setenv("GLOBAL_DEFAULT_CLIENT_ID", "")
+	t.Setenv("GLOBAL_DEFAULT_CLIENT_SECRET", "")
+	t.Setenv("STREAMNATIVE_ACCESS_TOKEN", "replayed-access-token")
+	t.Run("replay", func(t *testing.T) {
+		assert.NoError(t, run(t, cassetteReplay, "shared-gcp-prod"))
+	})
+
+	// A change of the request body is reported
+	recorder := &cassetteRecorder{mode: cassetteReplay}
+	wrapTestTransport = recorder.wrap
+	recorder.startFile(t, path)
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{})
+	meta, diags := providerConfigure(ctx, d, "")
+	assert.False(t, diags.HasError(), diags)
+	clientSet, err := getClientSet(getFactoryFromMeta(meta))
+	assert.NoError(t, err)
+	_, err = clientSet.CloudV1alpha1().PulsarInstances("sndev").Create(ctx, &cloudv1alpha1.PulsarInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "cassette-instance"},
+		Spec: cloudv1alpha1.PulsarInstanceSpec{
+			PoolRef: &cloudv1alpha1.PoolRef{Name: "shared-aws-prod", Namespace: "streamnative"},
+		},
+	}, metav1.CreateOptions{})
+	assert.ErrorContains(t, err, "the cassette expects")
+	assert.ErrorContains(t, recorder.stop(), "shared-aws-prod")
+}
+
+func TestCassetteFakeSecrets(t *testing.T) {
+	recorder := &cassetteRecorder{mode: cassetteRecord}
+	recorder.startFile(t, filepath.Join(t.TempDir(), "cassette.json"))
+	body := func(object interface{}) []byte {
+		data, err := json.Marshal(object)
+		assert.NoError(t, err)
+		return data
+	}
+
+	// The data sent by the test is kept, the secrets issued by the API server are replaced with fakes
+	secret := map[string]interface{}{"data": map[string]interface{}{"key": "value"}}
+	assert.Equal(t, secret, recorder.scrubBody(body(secret), true))
+	assert.Equal(t, secret, recorder.scrubBody(body(secret), false))
+
+	privateKey, err := loadCassetteEncryptionKey()
+	assert.NoError(t, err)
+	recorded := recorder.scrubBody(body(map[string]interface{}{
+		"status": map[string]interface{}{
+			"encryptedToken": map[string]interface{}{"jwe": "real-jwe"},
+			"privateKeyData": "real-private-key-data",
+		},
+	}), false).(map[string]interface{})["status"].(map[string]interface{})
+	fakeJWE := recorded["encryptedToken"].(map[string]interface{})["jwe"].(string)
+	token, err := util.DecryptToken(privateKey, cloudv1alpha1.EncryptedToken{JWE: &fakeJWE})
+	assert.NoError(t, err)
+	assert.Regexp(t, "^fake-token-[0-9]+$", token)
+	keyFile, err := base64.StdEncoding.DecodeString(recorded["privateKeyData"].(string))
+	assert.NoError(t, err)
+	assert.Contains(t, string(keyFile), "fake-client-secret-")
+
+	// The issued secrets sent back by the provider are recorded as the same fakes
+	assert.Equal(t, map[string]interface{}{"privateKeyData": recorded["privateKeyData"]},
+		recorder.scrubBody(body(map[string]interface{}{"privateKeyData": "real-private-key-data"}), true))
+	assert.NoError(t, recorder.stop())
+}
+
+func TestTestAccRandomString(t *testing.T) {
+	t.Setenv(cassetteModeEnv, "")
+	assert.Len(t, testAccRandomString(t, 8), 8)
+
+	t.Setenv(cassetteModeEnv, cassetteReplay)
+	names := map[string][]string{}
+	for _, name := range []string{"a", "b"} {
+		t.Run(name, func(t *testing.T) {
+			names[name] = []string{testAccRandomString(t, 8), testAccRandomString(t, 8)}
+		})
+	}
+	assert.NotEqual(t, names["a"][0], names["a"][1])
+	assert.NotEqual(t, names["a"], names["b"])
+	// The index of the calls restarts when the test runs again, e.g. with -count
+	testAccRandMu.Lock()
+	assert.NotContains(t, testAccRandCalls, t.Name()+"/a")
+	testAccRandMu.Unlock()
+}
@@ -7,7 +7,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/stretchr/testify/assert"
@@ -16,12 +15,11 @@ import (
 )
 
 func TestCatalogS3Table(t *testing.T) {
-	catalogName, err := uuid.NewRandom()
-	assert.NoError(t, err)
+	catalogName := testAccRandomString(t, 16)
 
 	resource.Test(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: testAccProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
 		CheckDestroy: func(state *terraform.State) error {
 			time.Sleep(5 * time.Second)
 			for _, rs := range state.RootModule().Resources {
@@ -67,7 +65,7 @@ data "streamnative_catalog" "s3_table_catalog" {
   organization = streamnative_catalog.s3_table_catalog.organization
   name         = streamnative_catalog.s3_table_catalog.name
 }
-`, catalogName),
+`, "tf-acc-"+catalogName),
 				Check: func(state *terraform.State) error {
 					rs, ok := state.RootModule().Resources["streamnative_catalog.s3_table_catalog"]
 					if !ok {
@@ -0,0 +1,137 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/99designs/keyring"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/streamnative/cloud-cli/pkg/auth"
+	"github.com/streamnative/cloud-cli/pkg/auth/store"
+	"github.com/streamnative/cloud-cli/pkg/config"
+	"golang.org/x/oauth2"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// CLIKeychainName is the keychain where snctl saves the grants
+	CLIKeychainName = "snctl"
+	// CLIConfigDirName is the config directory of snctl under the home directory
+	CLIConfigDirName = ".streamnative"
+)
+
+// getCLIConfigDir returns the snctl config directory configured by 'cli_config_dir', default ~/.streamnative
+func getCLIConfigDir(d *schema.ResourceData) (string, error) {
+	if configDir := d.Get("cli_config_dir").(string); configDir != "" {
+		return configDir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the home directory: %v", err)
+	}
+	return filepath.Join(home, CLIConfigDirName), nil
+}
+
+// loadCLIConfig reads the config saved by snctl in the config directory
+func loadCLIConfig(configDir string) (*config.SnConfig, error) {
+	data, err := os.ReadFile(filepath.Join(configDir, "config"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the snctl config, run 'snctl auth login' first: %v", err)
+	}
+	snConfig := &config.SnConfig{}
+	if err = yaml.Unmarshal(data, snConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse the snctl config: %v", err)
+	}
+	return snConfig, nil
+}
+
+// cliEndpoints returns the endpoints snctl is logged in to, they take the place of the
+// GLOBAL_DEFAULT_* environment variables when the snctl credentials are used
+func cliEndpoints(snConfig *config.SnConfig) environmentEndpoints {
+	return environmentEndpoints{
+		APIServer: snConfig.Server,
+		Issuer:    snConfig.Auth.IssuerEndpoint,
+		Audience:  snConfig.Auth.Audience,
+	}
+}
+
+// cliCertificateAuthorityData returns the PEM encoded certificate authority data of the snctl config
+func cliCertificateAuthorityData(snConfig *config.SnConfig) (string, error) {
+	if snConfig.CertificateAuthorityData == "" {
+		return "", nil
+	}
+	data, err := base64.StdEncoding.DecodeString(snConfig.CertificateAuthorityData)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode the certificate authority data of the snctl config: %v", err)
+	}
+	return string(data), nil
+}
+
+// newCLITokenSource serves the grant saved by 'snctl auth login', either a device code grant of a user
+// or a client credentials grant of a service account. The refreshed grants are saved back to the
+// snctl keyring so snctl keeps working with them.
+func newCLITokenSource(
+	d *schema.ResourceData, snConfig *config.SnConfig, endpoints environmentEndpoints) (oauth2.TokenSource, error) {
+	configDir, err := getCLIConfigDir(d)
+	if err != nil {
+		return nil, err
+	}
+	kr, err := keyring.Open(keyring.Config{
+		ServiceName:              ServiceName,
+		KeychainName:             CLIKeychainName,
+		KeychainTrustApplication: true,
+		FileDir:                  filepath.Join(configDir, "credentials"),
+		FilePasswordFunc:         keyring.FixedStringPrompt(d.Get("keyring_passphrase").(string)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the snctl keyring: %v", err)
+	}
+	grantStore, err := store.NewKeyringStore(kr)
+	if err != nil {
+		return nil, err
+	}
+	grant, err := grantStore.LoadGrant(endpoints.Audience)
+	if err != nil {
+		return nil, fmt.Errorf("no snctl login found for the audience %q, run 'snctl auth login' first: %v",
+			endpoints.Audience, err)
+	}
+	issuer := auth.Issuer{
+		IssuerEndpoint: endpoints.Issuer,
+		ClientID:       snConfig.Auth.ClientID,
+		Audience:       endpoints.Audience,
+	}
+	var refresher grantRefresher
+	switch grant.Type {
+	case auth.GrantTypeDeviceCode:
+		refresher, err = auth.NewDefaultGrantRefresher(issuer, clock.RealClock{})
+	case auth.GrantTypeClientCredentials:
+		refresher, err = auth.NewDefaultClientCredentialsGrantRefresher(issuer, clock.RealClock{})
+	default:
+		return nil, fmt.Errorf("unsupported snctl grant type %q", grant.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &grantTokenSource{
+		audience:  endpoints.Audience,
+		store:     grantStore,
+		refresher: refresher,
+	}, nil
+}
@@ -15,14 +15,33 @@
 package cloud
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"sync"
+	"time"
 
+	"golang.org/x/oauth2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	k8sretry "k8s.io/client-go/util/retry"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 
 	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
 	cloudclient "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset"
+	cloudclientv1alpha1 "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset/typed/cloud/v1alpha1"
+	"github.com/streamnative/cloud-cli/pkg/auth"
+	"github.com/streamnative/cloud-cli/pkg/auth/store"
 )
 
 func init() {
@@ -31,11 +50,56 @@ func init() {
 	}
 }
 
-func getFactoryFromMeta(meta interface{}) cmdutil.Factory {
-	return meta.(cmdutil.Factory)
+// providerFactory is the meta of a provider instance, the API clients are created once from the
+// rest config of the cmdutil.Factory and shared by all the resources of the provider instance
+type providerFactory struct {
+	cmdutil.Factory
+	// backoff is used to retry the updates conflicting with a concurrent change
+	backoff wait.Backoff
+
+	mu            sync.Mutex
+	clientSet     cloudclient.Interface
+	dynamicClient dynamic.Interface
+}
+
+// wrapTestTransport is set by the tests to record or replay the API calls, it wraps the transport
+// closest to the network so the requests are seen as they are sent to the API server
+var wrapTestTransport func(http.RoundTripper) http.RoundTripper
+
+func getFactoryFromMeta(meta interface{}) *providerFactory {
+	return meta.(*providerFactory)
+}
+
+// newProviderFactoryWithClients returns the meta of a provider instance which serves the given
+// clients instead of building them from the rest config, e.g. the fake clientsets of the unit tests
+func newProviderFactoryWithClients(clientSet cloudclient.Interface, dynamicClient dynamic.Interface) *providerFactory {
+	return &providerFactory{
+		backoff:       newBackoff(5),
+		clientSet:     clientSet,
+		dynamicClient: dynamicClient,
+	}
+}
+
+// CloudV1alpha1 returns the client of the cloud.streamnative.io/v1alpha1 API
+func (f *providerFactory) CloudV1alpha1() (cloudclientv1alpha1.CloudV1alpha1Interface, error) {
+	clientSet, err := getClientSet(f)
+	if err != nil {
+		return nil, err
+	}
+	return clientSet.CloudV1alpha1(), nil
+}
+
+// DynamicClient returns the dynamic client of the API server
+func (f *providerFactory) DynamicClient() (dynamic.Interface, error) {
+	return getDynamicClient(f)
 }
 
-func getClientSet(factory cmdutil.Factory) (*cloudclient.Clientset, error) {
+func getClientSet(factory *providerFactory) (cloudclient.Interface, error) {
+	factory.mu.Lock()
+	defer factory.mu.Unlock()
+	if factory.clientSet != nil {
+		return factory.clientSet, nil
+	}
 	config, err := factory.ToRESTConfig()
 	if err != nil {
 		return nil, fmt.Errorf("ToRESTConfig: %v", err)
@@ -44,10 +108,16 @@ func getClientSet(factory cmdutil.Factory) (*cloudclient.Clientset, error) {
 	if err != nil {
 		return nil, fmt.Errorf("ClientSet NewForConfig: %v", err)
 	}
+	factory.clientSet = clientSet
 	return clientSet, nil
 }
 
-func getDynamicClient(factory cmdutil.Factory) (dynamic.Interface, error) {
+func getDynamicClient(factory *providerFactory) (dynamic.Interface, error) {
+	factory.mu.Lock()
+	defer factory.mu.Unlock()
+	if factory.dynamicClient != nil {
+		return factory.dynamicClient, nil
+	}
 	config, err := factory.ToRESTConfig()
 	if err != nil {
 		return nil, fmt.Errorf("ToRESTConfig: %v", err)
@@ -57,5 +127,136 @@ func getDynamicClient(factory cmdutil.Factory) (dynamic.Interface, error) {
 	if err != nil {
 		return nil, fmt.Errorf("DynamicClient NewForConfig: %v", err)
 	}
+	factory.dynamicClient = dynamicClient
 	return dynamicClient, nil
 }
+
+// retryUpdateOnConflict reads the latest object, applies mutate to it and updates it, if the update
+// conflicts with a concurrent change of the object, e.g. the status is updated by the controller, the
+// object is read and mutated again so the concurrent change is kept, the update is retried with the
+// backoff of the provider like retry.RetryOnConflict of client-go
+func retryUpdateOnConflict[T metav1.Object](
+	ctx context.Context,
+	factory *providerFactory,
+	name string,
+	get func(context.Context, string, metav1.GetOptions) (T, error),
+	update func(context.Context, T, metav1.UpdateOptions) (T, error),
+	mutate func(T) error,
+	opts metav1.UpdateOptions,
+) (T, error) {
+	var updated T
+	err := k8sretry.OnError(factory.backoff, apierrors.IsConflict, func() error {
+		obj, err := get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if err = mutate(obj); err != nil {
+			return err
+		}
+		updated, err = update(ctx, obj, opts)
+		return err
+	})
+	return updated, err
+}
+
+// restClientGetter serves the rest config built by the provider to the cmdutil.Factory,
+// the rest config is built on the first call so the authentication happens on the first API call
+type restClientGetter struct {
+	mu     sync.Mutex
+	init   func() (*rest.Config, error)
+	config *rest.Config
+}
+
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.config == nil {
+		config, err := g.init()
+		if err != nil {
+			return nil, err
+		}
+		g.config = config
+	}
+	return rest.CopyConfig(g.config), nil
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	config, err := g.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return clientcmd.NewDefaultClientConfig(*clientcmdapi.NewConfig(), &clientcmd.ConfigOverrides{})
+}
+
+// tokenRefreshSkew is how long before the expiry the token is refreshed, so the requests sent
+// right before the expiry are not rejected
+const tokenRefreshSkew = 2 * time.Minute
+
+// grantRefresher obtains a new grant, it's implemented by the client credentials grant refresher
+// of cloud-cli and the workload identity exchanger
+type grantRefresher interface {
+	Refresh(grant *auth.AuthorizationGrant) (*auth.AuthorizationGrant, error)
+}
+
+// grantTokenSource is an oauth2.TokenSource which serves the token of the grant
+// saved in the grant store for the audience, the grant is refreshed before it expires
+// so the long-running applies keep authenticated
+type grantTokenSource struct {
+	mu        sync.Mutex
+	audience  string
+	store     store.Store
+	refresher grantRefresher
+	now       func() time.Time
+}
+
+func (s *grantTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	grant, err := s.store.LoadGrant(s.audience)
+	if err != nil {
+		return nil, fmt.Errorf("LoadGrant: %v", err)
+	}
+	if s.refresher != nil && s.shouldRefresh(grant.Token) {
+		grant, err = s.refresher.Refresh(grant)
+		if err != nil {
+			return nil, fmt.Errorf("failed to refresh the access token: %v", err)
+		}
+		if err = s.store.SaveGrant(s.audience, *grant); err != nil {
+			return nil, fmt.Errorf("SaveGrant: %v", err)
+		}
+	}
+	if grant.Token == nil {
+		return nil, fmt.Errorf("no access token available for the audience %q", s.audience)
+	}
+	return grant.Token, nil
+}
+
+func (s *grantTokenSource) shouldRefresh(token *oauth2.Token) bool {
+	if token == nil || token.AccessToken == "" {
+		return true
+	}
+	if token.Expiry.IsZero() {
+		return false
+	}
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	return !now().Add(tokenRefreshSkew).Before(token.Expiry)
+}
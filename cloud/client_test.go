@@ -0,0 +1,180 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	cloudfake "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset/fake"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeProviderMeta returns the meta of a provider instance backed by the fake clientsets, the
+// reactors of the returned clientset can be used to inject the errors and the status of the objects
+func newFakeProviderMeta(objects ...runtime.Object) (*providerFactory, *cloudfake.Clientset) {
+	clientSet := cloudfake.NewSimpleClientset(objects...)
+	factory := newProviderFactoryWithClients(clientSet, dynamicfake.NewSimpleDynamicClient(scheme.Scheme))
+	factory.backoff = wait.Backoff{Steps: 3}
+	return factory, clientSet
+}
+
+// setTestReadyCondition sets the Ready condition of the object like the controllers of the API server,
+// the condition is observed at the current generation of the object
+func setTestReadyCondition(obj runtime.Object, status string) error {
+	return setTestReadyConditionAt(obj, status, time.Time{})
+}
+
+// setTestReadyConditionAt sets the Ready condition of the object like setTestReadyCondition, the
+// condition transitioned at the given time unless it is zero
+func setTestReadyConditionAt(obj runtime.Object, status string, transitionTime time.Time) error {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	condition := map[string]interface{}{
+		"type":               "Ready",
+		"status":             status,
+		"observedGeneration": obj.(metav1.Object).GetGeneration(),
+	}
+	if !transitionTime.IsZero() {
+		condition["lastTransitionTime"] = transitionTime.UTC().Format(time.RFC3339)
+	}
+	err = unstructured.SetNestedSlice(object, []interface{}{condition}, "status", "conditions")
+	if err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(object, obj)
+}
+
+// markCreatedReady is a reactor of the fake clientset which sets the Ready condition of the created
+// objects like the controllers of the API server
+func markCreatedReady(action k8stesting.Action) (bool, runtime.Object, error) {
+	return false, nil, setTestReadyCondition(action.(k8stesting.CreateAction).GetObject(), "True")
+}
+
+func TestProviderFactoryWithClients(t *testing.T) {
+	factory, clientSet := newFakeProviderMeta(&cloudv1alpha1.PulsarInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "instance", Namespace: "sndev"},
+	})
+	cloudClient, err := factory.CloudV1alpha1()
+	assert.NoError(t, err)
+	instance, err := cloudClient.PulsarInstances("sndev").Get(context.Background(), "instance", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "instance", instance.Name)
+
+	injected, err := getClientSet(getFactoryFromMeta(factory))
+	assert.NoError(t, err)
+	assert.Same(t, clientSet, injected)
+	dynamicClient, err := factory.DynamicClient()
+	assert.NoError(t, err)
+	assert.NotNil(t, dynamicClient)
+}
+
+func TestProviderFactorySharedRateLimiter(t *testing.T) {
+	server := newFakeAPIServer(0)
+	t.Cleanup(server.Close)
+	server.setTestEnv(t)
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"requests_per_second": 5.0,
+		"burst":               10,
+	})
+	meta, diags := providerConfigure(context.Background(), d, "")
+	assert.False(t, diags.HasError(), diags)
+	factory := getFactoryFromMeta(meta)
+	config, err := factory.ToRESTConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, float32(5), config.RateLimiter.QPS())
+	other, err := factory.ToRESTConfig()
+	assert.NoError(t, err)
+	assert.Same(t, config.RateLimiter, other.RateLimiter)
+
+	// The clients of the provider take the tokens from the same bucket
+	clientSet, err := getClientSet(factory)
+	assert.NoError(t, err)
+	assert.Same(t, config.RateLimiter, clientSet.CloudV1alpha1().RESTClient().GetRateLimiter())
+	_, err = getDynamicClient(factory)
+	assert.NoError(t, err)
+}
+
+func TestRetryUpdateOnConflict(t *testing.T) {
+	factory, clientSet := newFakeProviderMeta(&cloudv1alpha1.APIKey{
+		ObjectMeta: metav1.ObjectMeta{Name: "apikey", Namespace: "sndev", ResourceVersion: "1"},
+	})
+	apiKeysResource := cloudv1alpha1.SchemeGroupVersion.WithResource("apikeys").GroupResource()
+	ctx := context.Background()
+	apiKeys := clientSet.CloudV1alpha1().APIKeys("sndev")
+	conflicts := 0
+	clientSet.PrependReactor("update", "apikeys", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			return false, nil, nil
+		}
+		conflicts++
+		// A concurrent writer changes the description before the first update is applied, the tracker
+		// is used directly as the clientset is locked while the reactors run
+		apiKeysVersionResource := cloudv1alpha1.SchemeGroupVersion.WithResource("apikeys")
+		obj, err := clientSet.Tracker().Get(apiKeysVersionResource, "sndev", "apikey")
+		if err != nil {
+			return true, nil, err
+		}
+		concurrent := obj.(*cloudv1alpha1.APIKey).DeepCopy()
+		concurrent.Spec.Description = "concurrent"
+		if err = clientSet.Tracker().Update(apiKeysVersionResource, concurrent, "sndev"); err != nil {
+			return true, nil, err
+		}
+		return true, nil, apierrors.NewConflict(apiKeysResource, "apikey", fmt.Errorf("injected"))
+	})
+	mutations := 0
+	_, err := retryUpdateOnConflict(ctx, factory, "apikey", apiKeys.Get, apiKeys.Update,
+		func(apiKey *cloudv1alpha1.APIKey) error {
+			mutations++
+			apiKey.Spec.Revoke = true
+			return nil
+		}, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, conflicts)
+	assert.Equal(t, 2, mutations)
+	apiKey, err := apiKeys.Get(ctx, "apikey", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, apiKey.Spec.Revoke)
+	assert.Equal(t, "concurrent", apiKey.Spec.Description)
+
+	// The errors of the mutation and the errors other than the conflicts are not retried
+	_, err = retryUpdateOnConflict(ctx, factory, "apikey", apiKeys.Get, apiKeys.Update,
+		func(apiKey *cloudv1alpha1.APIKey) error {
+			return fmt.Errorf("invalid")
+		}, metav1.UpdateOptions{})
+	assert.EqualError(t, err, "invalid")
+	clientSet.PrependReactor("update", "apikeys", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(apiKeysResource, "apikey", fmt.Errorf("injected"))
+	})
+	_, err = retryUpdateOnConflict(ctx, factory, "apikey", apiKeys.Get, apiKeys.Update,
+		func(apiKey *cloudv1alpha1.APIKey) error {
+			return nil
+		}, metav1.UpdateOptions{})
+	assert.True(t, apierrors.IsForbidden(err), err)
+}
@@ -41,7 +41,7 @@ func dataSourceApiKey() *schema.Resource {
 				organizationApiKey := strings.Split(d.Id(), "/")
 				_ = d.Set("organization", organizationApiKey[0])
 				_ = d.Set("name", organizationApiKey[1])
-				err := resourceApiKeyRead(ctx, d, meta)
+				err := DataSourceApiKeyRead(ctx, d, meta)
 				if err.HasError() {
 					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
 				}
@@ -208,18 +208,25 @@ func DataSourceApiKeyRead(ctx context.Context, d *schema.ResourceData, meta inte
 }
 
 func setPrincipalName(apiKey *v1alpha1.APIKey, d *schema.ResourceData) diag.Diagnostics {
+	principalName, err := apiKeyPrincipalName(apiKey)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if err = d.Set("principal_name", principalName); err != nil {
+		return diag.FromErr(fmt.Errorf("ERROR_SET_PRINCIPAL_NAME: %w", err))
+	}
+	return nil
+}
+
+// apiKeyPrincipalName returns the principal name of the service account of the api key
+func apiKeyPrincipalName(apiKey *v1alpha1.APIKey) (string, error) {
 	defaultIssuer := os.Getenv("GLOBAL_DEFAULT_ISSUER")
 	if defaultIssuer == "" {
 		defaultIssuer = GlobalDefaultIssuer
 	}
 	u, err := url.Parse(defaultIssuer)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_PARSE_DEFAULT_ISSUER: %w", err))
+		return "", fmt.Errorf("ERROR_PARSE_DEFAULT_ISSUER: %w", err)
 	}
-	err = d.Set("principal_name", fmt.Sprintf(
-		"%s@%s.%s", apiKey.Spec.ServiceAccountName, apiKey.Namespace, u.Host))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_PRINCIPAL_NAME: %w", err))
-	}
-	return nil
+	return fmt.Sprintf("%s@%s.%s", apiKey.Spec.ServiceAccountName, apiKey.Namespace, u.Host), nil
 }
@@ -33,7 +33,7 @@ func dataSourceCloudConnection() *schema.Resource {
 				organizationInstance := strings.Split(d.Id(), "/")
 				_ = d.Set("organization", organizationInstance[0])
 				_ = d.Set("name", organizationInstance[1])
-				err := resourceCloudConnectionRead(ctx, d, meta)
+				err := cloudConnectionEngine.read(ctx, d, meta)
 				if err.HasError() {
 					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
 				}
@@ -34,7 +34,7 @@ func dataSourceCloudEnvironment() *schema.Resource {
 				organizationInstance := strings.Split(d.Id(), "/")
 				_ = d.Set("organization", organizationInstance[0])
 				_ = d.Set("name", organizationInstance[1])
-				err := resourceCloudEnvironmentRead(ctx, d, meta)
+				err := dataSourceCloudEnvironmentRead(ctx, d, meta)
 				if err.HasError() {
 					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
 				}
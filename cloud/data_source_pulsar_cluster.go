@@ -33,6 +33,19 @@ const (
 	UrsaEngineValue        = "ursa"
 )
 
+// The protocols of the service endpoints of the pulsar cluster
+const (
+	ServiceProtocolHTTP      = "http"
+	ServiceProtocolPulsar    = "pulsar"
+	ServiceProtocolWebsocket = "websocket"
+	ServiceProtocolKafka     = "kafka"
+	ServiceProtocolMQTT      = "mqtt"
+)
+
+var serviceProtocols = []string{
+	ServiceProtocolHTTP, ServiceProtocolPulsar, ServiceProtocolWebsocket, ServiceProtocolKafka, ServiceProtocolMQTT,
+}
+
 func dataSourcePulsarCluster() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: dataSourcePulsarClusterRead,
@@ -41,7 +54,7 @@ func dataSourcePulsarCluster() *schema.Resource {
 				organizationCluster := strings.Split(d.Id(), "/")
 				_ = d.Set("organization", organizationCluster[0])
 				_ = d.Set("name", organizationCluster[1])
-				err := resourcePulsarClusterRead(ctx, d, meta)
+				err := dataSourcePulsarClusterRead(ctx, d, meta)
 				if err.HasError() {
 					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
 				}
@@ -362,22 +375,23 @@ func dataSourcePulsarClusterRead(ctx context.Context, d *schema.ResourceData, me
 	var mqttServiceUrls []string
 	for _, endpoint := range pulsarCluster.Spec.ServiceEndpoints {
 		if endpoint.Type == "service" {
-			httpTlsServiceUrls = append(httpTlsServiceUrls, fmt.Sprintf("https://%s", endpoint.DnsName))
-			pulsarTlsServiceUrls = append(pulsarTlsServiceUrls, fmt.Sprintf("pulsar+ssl://%s:6651", endpoint.DnsName))
+			httpTlsServiceUrls = append(httpTlsServiceUrls,
+				formatServiceURL(endpoint.DnsName, ServiceProtocolHTTP, istioEnabled))
+			pulsarTlsServiceUrls = append(pulsarTlsServiceUrls,
+				formatServiceURL(endpoint.DnsName, ServiceProtocolPulsar, istioEnabled))
 			if pulsarCluster.Spec.Config != nil {
 				if pulsarCluster.Spec.Config.WebsocketEnabled != nil && *pulsarCluster.Spec.Config.WebsocketEnabled {
-					if istioEnabled {
-						websocketServiceUrls = append(websocketServiceUrls, fmt.Sprintf("wss://%s", endpoint.DnsName))
-					} else {
-						websocketServiceUrls = append(websocketServiceUrls, fmt.Sprintf("ws://%s:9443", endpoint.DnsName))
-					}
+					websocketServiceUrls = append(websocketServiceUrls,
+						formatServiceURL(endpoint.DnsName, ServiceProtocolWebsocket, istioEnabled))
 				}
 				if pulsarCluster.Spec.Config.Protocols != nil {
 					if pulsarCluster.Spec.Config.Protocols.Kafka != nil && istioEnabled {
-						kafkaServiceUrls = append(kafkaServiceUrls, fmt.Sprintf("%s:9093", endpoint.DnsName))
+						kafkaServiceUrls = append(kafkaServiceUrls,
+							formatServiceURL(endpoint.DnsName, ServiceProtocolKafka, istioEnabled))
 					}
 					if pulsarCluster.Spec.Config.Protocols.Mqtt != nil {
-						mqttServiceUrls = append(mqttServiceUrls, fmt.Sprintf("mqtts://%s:8883", endpoint.DnsName))
+						mqttServiceUrls = append(mqttServiceUrls,
+							formatServiceURL(endpoint.DnsName, ServiceProtocolMQTT, istioEnabled))
 					}
 				}
 			}
@@ -500,3 +514,24 @@ func dataSourcePulsarClusterRead(ctx context.Context, d *schema.ResourceData, me
 	d.SetId(fmt.Sprintf("%s/%s", pulsarCluster.Namespace, pulsarCluster.Name))
 	return nil
 }
+
+// formatServiceURL formats the URL of the protocol served on the DNS name of the service endpoint,
+// the websocket is served on the default port of wss when istio is enabled
+func formatServiceURL(dnsName string, protocol string, istioEnabled bool) string {
+	switch protocol {
+	case ServiceProtocolHTTP:
+		return fmt.Sprintf("https://%s", dnsName)
+	case ServiceProtocolPulsar:
+		return fmt.Sprintf("pulsar+ssl://%s:6651", dnsName)
+	case ServiceProtocolWebsocket:
+		if istioEnabled {
+			return fmt.Sprintf("wss://%s", dnsName)
+		}
+		return fmt.Sprintf("ws://%s:9443", dnsName)
+	case ServiceProtocolKafka:
+		return fmt.Sprintf("%s:9093", dnsName)
+	case ServiceProtocolMQTT:
+		return fmt.Sprintf("mqtts://%s:8883", dnsName)
+	}
+	return ""
+}
@@ -33,7 +33,7 @@ func dataSourcePulsarInstance() *schema.Resource {
 				organizationInstance := strings.Split(d.Id(), "/")
 				_ = d.Set("organization", organizationInstance[0])
 				_ = d.Set("name", organizationInstance[1])
-				err := resourcePulsarInstanceRead(ctx, d, meta)
+				err := pulsarInstanceEngine.read(ctx, d, meta)
 				if err.HasError() {
 					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
 				}
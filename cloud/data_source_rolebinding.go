@@ -24,7 +24,7 @@ func dataSourceRoleBinding() *schema.Resource {
 				organizationRoleBinding := strings.Split(d.Id(), "/")
 				_ = d.Set("organization", organizationRoleBinding[0])
 				_ = d.Set("name", organizationRoleBinding[1])
-				err := resourceRoleBindingRead(ctx, d, meta)
+				err := roleBindingEngine.read(ctx, d, meta)
 				if err.HasError() {
 					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
 				}
@@ -168,9 +168,30 @@ func DataSourceRoleBindingRead(ctx context.Context, d *schema.ResourceData, meta
 		return diag.FromErr(fmt.Errorf("ERROR_SET_NAME: %w", err))
 	}
 
+	if err = setRoleBindingSpec(organization, roleBinding, d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if len(roleBinding.Status.Conditions) >= 1 {
+		for _, condition := range roleBinding.Status.Conditions {
+			if condition.Type == "Ready" && condition.Status == "True" {
+				if err = d.Set("ready", true); err != nil {
+					return diag.FromErr(fmt.Errorf("ERROR_SET_READY: %w", err))
+				}
+			}
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", roleBinding.Namespace, roleBinding.Name))
+	return nil
+}
+
+// setRoleBindingSpec sets the role, subjects and conditions of the role binding, it's shared by the data
+// source and the import of the resource
+func setRoleBindingSpec(organization string, roleBinding *v1alpha1.RoleBinding, d *schema.ResourceData) error {
 	if roleBinding.Spec.RoleRef.Kind == "ClusterRole" {
-		if err = d.Set("cluster_role_name", roleBinding.Spec.RoleRef.Name); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_CLUSTER_ROLE_NAME: %w", err))
+		if err := d.Set("cluster_role_name", roleBinding.Spec.RoleRef.Name); err != nil {
+			return fmt.Errorf("ERROR_SET_CLUSTER_ROLE_NAME: %w", err)
 		}
 	}
 
@@ -184,39 +205,27 @@ func DataSourceRoleBindingRead(ctx context.Context, d *schema.ResourceData, meta
 		}
 	}
 	if serviceAccountNames != nil {
-		if err = d.Set("service_account_names", serviceAccountNames); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_SERVICE_ACCOUNT_NAMES: %w", err))
+		if err := d.Set("service_account_names", serviceAccountNames); err != nil {
+			return fmt.Errorf("ERROR_SET_SERVICE_ACCOUNT_NAMES: %w", err)
 		}
 	}
 	if userNames != nil {
-		if err = d.Set("user_names", userNames); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_USER_NAMES: %w", err))
+		if err := d.Set("user_names", userNames); err != nil {
+			return fmt.Errorf("ERROR_SET_USER_NAMES: %w", err)
 		}
 	}
 
-	if err = conditionParse(organization, roleBinding, d); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_CONDITION: %w", err))
+	if err := conditionParse(organization, roleBinding, d); err != nil {
+		return fmt.Errorf("ERROR_SET_CONDITION: %w", err)
 	}
 
 	if roleBinding.Spec.ResourceNameRestriction != nil {
 		if rawData, updated := rbac.ParseToRaw(roleBinding.Spec.ResourceNameRestriction); updated {
-			if err = d.Set("resource_name_restriction", []interface{}{rawData}); err != nil {
-				return diag.FromErr(fmt.Errorf("ERROR_SET_RESOURCE_NAME_RESTRICTION: %w", err))
-			}
-		}
-	}
-
-	if len(roleBinding.Status.Conditions) >= 1 {
-		for _, condition := range roleBinding.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				if err = d.Set("ready", true); err != nil {
-					return diag.FromErr(fmt.Errorf("ERROR_SET_READY: %w", err))
-				}
+			if err := d.Set("resource_name_restriction", []interface{}{rawData}); err != nil {
+				return fmt.Errorf("ERROR_SET_RESOURCE_NAME_RESTRICTION: %w", err)
 			}
 		}
 	}
-
-	d.SetId(fmt.Sprintf("%s/%s", roleBinding.Namespace, roleBinding.Name))
 	return nil
 }
 
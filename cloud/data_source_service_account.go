@@ -34,7 +34,7 @@ func dataSourceServiceAccount() *schema.Resource {
 				organizationServiceAccount := strings.Split(d.Id(), "/")
 				_ = d.Set("organization", organizationServiceAccount[0])
 				_ = d.Set("name", organizationServiceAccount[1])
-				err := resourceServiceAccountRead(ctx, d, meta)
+				err := serviceAccountEngine.read(ctx, d, meta)
 				if err.HasError() {
 					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
 				}
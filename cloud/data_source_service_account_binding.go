@@ -34,7 +34,7 @@ func dataSourceServiceAccountBinding() *schema.Resource {
 				organizationServiceAccount := strings.Split(d.Id(), "/")
 				_ = d.Set("organization", organizationServiceAccount[0])
 				_ = d.Set("name", organizationServiceAccount[1])
-				err := resourceServiceAccountBindingRead(ctx, d, meta)
+				err := serviceAccountBindingEngine.read(ctx, d, meta)
 				if err.HasError() {
 					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
 				}
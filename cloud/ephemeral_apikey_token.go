@@ -0,0 +1,288 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	"github.com/streamnative/terraform-provider-streamnative/cloud/util"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// apiKeyTokenPrivateKey is the key of the private data which keeps the api key created by Open,
+// the api key is revoked and deleted by Close
+const apiKeyTokenPrivateKey = "apikey"
+
+// apiKeyTokenEphemeralResource returns the token of a short-lived api key without saving it in the state,
+// the token is encrypted with a key which is generated on every open and only kept in memory
+type apiKeyTokenEphemeralResource struct {
+	factory *providerFactory
+}
+
+type apiKeyTokenEphemeralResourceModel struct {
+	Organization       types.String `tfsdk:"organization"`
+	InstanceName       types.String `tfsdk:"instance_name"`
+	ServiceAccountName types.String `tfsdk:"service_account_name"`
+	ExpirationTime     types.String `tfsdk:"expiration_time"`
+	Description        types.String `tfsdk:"description"`
+	Name               types.String `tfsdk:"name"`
+	Token              types.String `tfsdk:"token"`
+	KeyID              types.String `tfsdk:"key_id"`
+	ExpiresAt          types.String `tfsdk:"expires_at"`
+	PrincipalName      types.String `tfsdk:"principal_name"`
+}
+
+// apiKeyTokenPrivateData is the api key created by Open
+type apiKeyTokenPrivateData struct {
+	Organization string `json:"organization"`
+	Name         string `json:"name"`
+}
+
+var _ ephemeral.EphemeralResourceWithConfigure = &apiKeyTokenEphemeralResource{}
+var _ ephemeral.EphemeralResourceWithClose = &apiKeyTokenEphemeralResource{}
+
+func NewApiKeyTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &apiKeyTokenEphemeralResource{}
+}
+
+func (r *apiKeyTokenEphemeralResource) Metadata(
+	_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_apikey_token"
+}
+
+func (r *apiKeyTokenEphemeralResource) Schema(
+	_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: descriptions["apikey_token"],
+		Attributes: map[string]schema.Attribute{
+			"organization": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["organization"],
+				Validators:  []validator.String{notBlankValidator{}},
+			},
+			"instance_name": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["instance_name"],
+				Validators:  []validator.String{notBlankValidator{}},
+			},
+			"service_account_name": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["service_account_name"],
+				Validators:  []validator.String{notBlankValidator{}},
+			},
+			"expiration_time": schema.StringAttribute{
+				Optional:    true,
+				Description: descriptions["apikey_token_expiration_time"],
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: descriptions["apikey_description"],
+			},
+			"name": schema.StringAttribute{
+				Computed:    true,
+				Description: descriptions["apikey_token_name"],
+			},
+			"token": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: descriptions["token"],
+			},
+			"key_id": schema.StringAttribute{
+				Computed:    true,
+				Description: descriptions["key_id"],
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:    true,
+				Description: descriptions["expires_at"],
+			},
+			"principal_name": schema.StringAttribute{
+				Computed:    true,
+				Description: descriptions["principal_name"],
+			},
+		},
+	}
+}
+
+func (r *apiKeyTokenEphemeralResource) Configure(
+	_ context.Context, req ephemeral.ConfigureRequest, _ *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	r.factory = getFactoryFromMeta(req.ProviderData)
+}
+
+// Open creates a short-lived api key whose token is encrypted with a throwaway key, the existing api
+// keys are never changed. The api key is kept in the private data until Close revokes and deletes it
+func (r *apiKeyTokenEphemeralResource) Open(
+	ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data apiKeyTokenEphemeralResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	namespace := data.Organization.ValueString()
+	clientSet, err := getClientSet(r.factory)
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_OPEN_API_KEY_TOKEN", err.Error())
+		return
+	}
+	expirationTime := data.ExpirationTime.ValueString()
+	if expirationTime == "" {
+		expirationTime = "1h"
+	}
+	apiKeyExpirationTime, err := parseApiKeyExpirationTime(expirationTime, time.Now())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("expiration_time"), "ERROR_PARSE_EXPIRATION_TIME", err.Error())
+		return
+	}
+	privateKey, err := util.GenerateEncryptionKey()
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_GENERATE_RSA_PRIVATE_KEY", err.Error())
+		return
+	}
+	encryptionKey, err := util.ExportPublicKey(privateKey)
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_EXPORT_PUBLIC_KEY", err.Error())
+		return
+	}
+	name := "tf-ephemeral-" + uuid.New().String()[:8]
+	apiKeys := clientSet.CloudV1alpha1().APIKeys(namespace)
+	_, err = apiKeys.Create(ctx, &v1alpha1.APIKey{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "APIKey",
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: v1alpha1.APIKeySpec{
+			InstanceName:       data.InstanceName.ValueString(),
+			ServiceAccountName: data.ServiceAccountName.ValueString(),
+			Description:        data.Description.ValueString(),
+			ExpirationTime:     apiKeyExpirationTime,
+			EncryptionKey:      &v1alpha1.EncryptionKey{PEM: encryptionKey.PEM},
+		},
+	}, metav1.CreateOptions{
+		FieldManager: "terraform-create",
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_CREATE_API_KEY", err.Error())
+		return
+	}
+	// The api key is cleaned up by Close even when it's never issued
+	privateData, err := json.Marshal(apiKeyTokenPrivateData{Organization: namespace, Name: name})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_OPEN_API_KEY_TOKEN", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, apiKeyTokenPrivateKey, privateData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	err = retry.RetryContext(ctx, 10*time.Minute, func() *retry.RetryError {
+		apiKey, err := apiKeys.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return retry.NonRetryableError(fmt.Errorf("ERROR_READ_API_KEY: %w", err))
+		}
+		token, ok := decryptIssuedApiKeyToken(apiKey, privateKey)
+		if !ok {
+			return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_OPEN_API_KEY_TOKEN"))
+		}
+		principalName, err := apiKeyPrincipalName(apiKey)
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		data.Name = types.StringValue(name)
+		data.Token = types.StringValue(token)
+		data.KeyID = types.StringValue(apiKey.Status.KeyId)
+		data.ExpiresAt = types.StringValue(apiKey.Status.ExpiresAt.String())
+		data.PrincipalName = types.StringValue(principalName)
+		return nil
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_RETRY_OPEN_API_KEY_TOKEN", err.Error())
+		return
+	}
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
+
+// Close revokes the api key created by Open so the token stops working, and deletes it
+func (r *apiKeyTokenEphemeralResource) Close(
+	ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	privateData, diags := req.Private.GetKey(ctx, apiKeyTokenPrivateKey)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || privateData == nil {
+		return
+	}
+	var created apiKeyTokenPrivateData
+	if err := json.Unmarshal(privateData, &created); err != nil {
+		resp.Diagnostics.AddError("ERROR_CLOSE_API_KEY_TOKEN", err.Error())
+		return
+	}
+	clientSet, err := getClientSet(r.factory)
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_CLOSE_API_KEY_TOKEN", err.Error())
+		return
+	}
+	apiKeys := clientSet.CloudV1alpha1().APIKeys(created.Organization)
+	_, err = retryUpdateOnConflict(ctx, r.factory, created.Name, apiKeys.Get, apiKeys.Update,
+		func(apiKey *v1alpha1.APIKey) error {
+			apiKey.Spec.Revoke = true
+			return nil
+		}, metav1.UpdateOptions{
+			FieldManager: "terraform-update",
+		})
+	if apierrors.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_REVOKE_API_KEY", err.Error())
+		return
+	}
+	err = apiKeys.Delete(ctx, created.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		resp.Diagnostics.AddError("ERROR_DELETE_API_KEY", err.Error())
+	}
+}
+
+// decryptIssuedApiKeyToken decrypts the token once the api key is issued, the token can't be decrypted
+// until the API server encrypts it with the public key of the private key
+func decryptIssuedApiKeyToken(apiKey *v1alpha1.APIKey, privateKey *rsa.PrivateKey) (string, bool) {
+	for _, condition := range apiKey.Status.Conditions {
+		if condition.Type == "Issued" && condition.Status == "True" {
+			token, err := util.DecryptToken(privateKey, apiKey.Status.EncryptedToken)
+			if err != nil {
+				return "", false
+			}
+			return token, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newPrivateData returns an empty private data like the framework passes it to Open, the type of the
+// private data is internal to the framework
+func newPrivateData[T any](_ *T) *T {
+	return new(T)
+}
+
+func openTestApiKeyToken(
+	t *testing.T, r ephemeral.EphemeralResource, expirationTime string) *ephemeral.OpenResponse {
+	ctx := context.Background()
+	schemaResp := &ephemeral.SchemaResponse{}
+	r.Schema(ctx, ephemeral.SchemaRequest{}, schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx)
+	resp := &ephemeral.OpenResponse{
+		Result: tfsdk.EphemeralResultData{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)},
+	}
+	resp.Private = newPrivateData(resp.Private)
+	attributes := map[string]tftypes.Value{
+		"organization":         tftypes.NewValue(tftypes.String, "sndev"),
+		"instance_name":        tftypes.NewValue(tftypes.String, "instance"),
+		"service_account_name": tftypes.NewValue(tftypes.String, "service-account"),
+	}
+	if expirationTime != "" {
+		attributes["expiration_time"] = tftypes.NewValue(tftypes.String, expirationTime)
+	}
+	r.Open(ctx, ephemeral.OpenRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: nullObjectValue(objectType, attributes)},
+	}, resp)
+	return resp
+}
+
+func TestApiKeyTokenEphemeralResource(t *testing.T) {
+	server := newFakeAPIServer(0)
+	t.Cleanup(server.Close)
+	server.setTestEnv(t)
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{})
+	meta, diags := providerConfigure(context.Background(), d, "")
+	assert.False(t, diags.HasError(), diags)
+	clientSet, err := getClientSet(getFactoryFromMeta(meta))
+	assert.NoError(t, err)
+	apiKeys := clientSet.CloudV1alpha1().APIKeys("sndev")
+	ctx := context.Background()
+	r := NewApiKeyTokenEphemeralResource().(ephemeral.EphemeralResourceWithClose)
+	r.(ephemeral.EphemeralResourceWithConfigure).Configure(ctx,
+		ephemeral.ConfigureRequest{ProviderData: meta}, &ephemeral.ConfigureResponse{})
+
+	// Every open creates a short-lived api key whose token is decrypted with the throwaway key
+	resp := openTestApiKeyToken(t, r, "")
+	assert.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	var data apiKeyTokenEphemeralResourceModel
+	assert.False(t, resp.Result.Get(ctx, &data).HasError())
+	name := data.Name.ValueString()
+	assert.True(t, strings.HasPrefix(name, "tf-ephemeral-"), name)
+	assert.Equal(t, "fake-token-"+name, data.Token.ValueString())
+	assert.NotEmpty(t, data.KeyID.ValueString())
+	assert.Contains(t, data.PrincipalName.ValueString(), "service-account@sndev.")
+	apiKey, err := apiKeys.Get(ctx, name, metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.False(t, apiKey.Spec.Revoke)
+	assert.WithinDuration(t, apiKey.CreationTimestamp.Time.Add(time.Hour), apiKey.Spec.ExpirationTime.Time, time.Minute)
+
+	other := openTestApiKeyToken(t, r, "")
+	assert.False(t, other.Diagnostics.HasError(), other.Diagnostics)
+	var otherData apiKeyTokenEphemeralResourceModel
+	assert.False(t, other.Result.Get(ctx, &otherData).HasError())
+	assert.NotEqual(t, name, otherData.Name.ValueString())
+
+	// Close deletes the api key, and the api key which is already deleted is ignored
+	closeResp := &ephemeral.CloseResponse{}
+	r.Close(ctx, ephemeral.CloseRequest{Private: resp.Private}, closeResp)
+	assert.False(t, closeResp.Diagnostics.HasError(), closeResp.Diagnostics)
+	_, err = apiKeys.Get(ctx, name, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), err)
+	r.Close(ctx, ephemeral.CloseRequest{Private: resp.Private}, closeResp)
+	assert.False(t, closeResp.Diagnostics.HasError(), closeResp.Diagnostics)
+
+	// The api key is revoked before it's deleted, so the token stops working even when the delete fails
+	server.injectError(http.MethodDelete, "apikeys", http.StatusForbidden)
+	closeResp = &ephemeral.CloseResponse{}
+	r.Close(ctx, ephemeral.CloseRequest{Private: other.Private}, closeResp)
+	if assert.True(t, closeResp.Diagnostics.HasError()) {
+		assert.Equal(t, "ERROR_DELETE_API_KEY", closeResp.Diagnostics[0].Summary())
+	}
+	apiKey, err = apiKeys.Get(ctx, otherData.Name.ValueString(), metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.True(t, apiKey.Spec.Revoke)
+
+	resp = openTestApiKeyToken(t, r, "invalid")
+	if assert.True(t, resp.Diagnostics.HasError()) {
+		assert.Equal(t, "ERROR_PARSE_EXPIRATION_TIME", resp.Diagnostics[0].Summary())
+	}
+}
@@ -0,0 +1,610 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwe"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	"github.com/streamnative/terraform-provider-streamnative/cloud/util"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// fakeAPIServerEnv runs the acceptance tests against the in-process fake API server instead of
+	// StreamNative Cloud, the value is the delay before the created resources become ready, e.g. "2s",
+	// other values such as "1" use a delay of one second
+	fakeAPIServerEnv = "STREAMNATIVE_FAKE_API_SERVER"
+
+	fakeAPIServerClientID     = "fake-client-id"
+	fakeAPIServerClientSecret = "fake-client-secret"
+	fakeAPIServerAccessToken  = "fake-access-token"
+	fakeAPIServerPathPrefix   = "/apis/cloud.streamnative.io/v1alpha1/namespaces/"
+)
+
+// fakeAPIServerKinds maps the resources served by the fake API server to their kinds
+var fakeAPIServerKinds = map[string]string{
+	"apikeys":                "APIKey",
+	"catalogs":               "Catalog",
+	"cloudconnections":       "CloudConnection",
+	"cloudenvironments":      "CloudEnvironment",
+	"poolmembers":            "PoolMember",
+	"pooloptions":            "PoolOption",
+	"pools":                  "Pool",
+	"pulsarclusters":         "PulsarCluster",
+	"pulsargateways":         "PulsarGateway",
+	"pulsarinstances":        "PulsarInstance",
+	"rolebindings":           "RoleBinding",
+	"secrets":                "Secret",
+	"serviceaccountbindings": "ServiceAccountBinding",
+	"serviceaccounts":        "ServiceAccount",
+	"volumes":                "Volume",
+}
+
+type fakeObjectKey struct {
+	resource  string
+	namespace string
+	name      string
+}
+
+type fakeObject struct {
+	object *unstructured.Unstructured
+	// readyAt is when the object becomes ready, zero once the object is ready
+	readyAt time.Time
+}
+
+type fakeError struct {
+	method   string
+	resource string
+	code     int
+}
+
+// fakeAPIServer is an in-memory implementation of the cloud.streamnative.io/v1alpha1 API used by the
+// provider, it also serves as the OAuth issuer of the client credentials of the provider. The objects
+// become ready after the ready delay, and the errors injected with injectError are returned once.
+type fakeAPIServer struct {
+	*httptest.Server
+	readyDelay time.Duration
+
+	mu              sync.Mutex
+	objects         map[fakeObjectKey]*fakeObject
+	errors          []fakeError
+	resourceVersion int64
+	listRequests    int
+}
+
+func newFakeAPIServer(readyDelay time.Duration) *fakeAPIServer {
+	s := &fakeAPIServer{
+		readyDelay: readyDelay,
+		objects:    map[fakeObjectKey]*fakeObject{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.serveOpenIDConfiguration)
+	mux.HandleFunc("/oauth/token", s.serveToken)
+	mux.HandleFunc(fakeAPIServerPathPrefix, s.serveAPI)
+	s.Server = httptest.NewServer(mux)
+	s.seed()
+	return s
+}
+
+// seed creates the objects managed by StreamNative which the tests expect to exist
+func (s *fakeAPIServer) seed() {
+	s.put("pools", "streamnative", "shared-gcp-prod", map[string]interface{}{
+		"type": "gcloud",
+	})
+	s.put("pooloptions", "sndev", "streamnative-shared-gcp-prod", map[string]interface{}{
+		"poolRef": map[string]interface{}{
+			"name":      "shared-gcp-prod",
+			"namespace": "streamnative",
+		},
+		"deploymentType": "hosted",
+	})
+	s.put("poolmembers", "streamnative", "gcp-shared-usce1", map[string]interface{}{
+		"type":     "gcloud",
+		"poolName": "shared-gcp-prod",
+		"gcloud": map[string]interface{}{
+			"location": "us-central1",
+		},
+	})
+}
+
+func (s *fakeAPIServer) put(resource, namespace, name string, spec map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	object := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	object.SetName(name)
+	object.SetNamespace(namespace)
+	s.initObject(resource, object)
+	s.setReady(resource, object)
+	s.objects[fakeObjectKey{resource, namespace, name}] = &fakeObject{object: object}
+}
+
+// setEnv points the provider at the fake API server, the credentials of StreamNative Cloud are unset
+// so the tests can't change the real resources by mistake
+func (s *fakeAPIServer) setEnv() {
+	for _, name := range []string{"KEY_FILE_PATH", "KEY_FILE_DATA", "STREAMNATIVE_ACCESS_TOKEN"} {
+		_ = os.Unsetenv(name)
+	}
+	_ = os.Setenv("GLOBAL_DEFAULT_API_SERVER", s.URL)
+	_ = os.Setenv("GLOBAL_DEFAULT_ISSUER", s.URL+"/")
+	_ = os.Setenv("GLOBAL_DEFAULT_AUDIENCE", s.URL)
+	_ = os.Setenv("GLOBAL_DEFAULT_CLIENT_ID", fakeAPIServerClientID)
+	_ = os.Setenv("GLOBAL_DEFAULT_CLIENT_SECRET", fakeAPIServerClientSecret)
+}
+
+// setTestEnv points the provider at the fake API server for the test only
+func (s *fakeAPIServer) setTestEnv(t *testing.T) {
+	for _, name := range []string{"KEY_FILE_PATH", "KEY_FILE_DATA", "STREAMNATIVE_ACCESS_TOKEN"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("GLOBAL_DEFAULT_API_SERVER", s.URL)
+	t.Setenv("GLOBAL_DEFAULT_ISSUER", s.URL+"/")
+	t.Setenv("GLOBAL_DEFAULT_AUDIENCE", s.URL)
+	t.Setenv("GLOBAL_DEFAULT_CLIENT_ID", fakeAPIServerClientID)
+	t.Setenv("GLOBAL_DEFAULT_CLIENT_SECRET", fakeAPIServerClientSecret)
+}
+
+// injectError makes the next request with the method to the resource fail with the status code
+func (s *fakeAPIServer) injectError(method, resource string, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = append(s.errors, fakeError{method: method, resource: resource, code: code})
+}
+
+func (s *fakeAPIServer) serveOpenIDConfiguration(w http.ResponseWriter, _ *http.Request) {
+	writeFakeJSON(w, http.StatusOK, map[string]string{
+		"issuer":         s.URL + "/",
+		"token_endpoint": s.URL + "/oauth/token",
+	})
+}
+
+func (s *fakeAPIServer) serveToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeFakeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != fakeAPIServerClientID || clientSecret != fakeAPIServerClientSecret {
+		writeFakeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": fakeAPIServerAccessToken,
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+func (s *fakeAPIServer) serveAPI(w http.ResponseWriter, r *http.Request) {
+	// The path is {namespace}/{resource}[/{name}]
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, fakeAPIServerPathPrefix), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		writeFakeStatus(w, apierrors.NewBadRequest(fmt.Sprintf("unsupported path %s", r.URL.Path)))
+		return
+	}
+	namespace, resource := parts[0], parts[1]
+	name := ""
+	if len(parts) == 3 {
+		name = parts[2]
+	}
+	groupResource := k8sschema.GroupResource{Group: "cloud.streamnative.io", Resource: resource}
+	if _, ok := fakeAPIServerKinds[resource]; !ok {
+		writeFakeStatus(w, apierrors.NewNotFound(groupResource, name))
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+fakeAPIServerAccessToken {
+		writeFakeStatus(w, apierrors.NewUnauthorized("invalid access token"))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if code, ok := s.takeError(r.Method, resource); ok {
+		writeFakeStatus(w, apierrors.NewGenericServerResponse(
+			code, r.Method, groupResource, name, "injected error", 0, false))
+		return
+	}
+	key := fakeObjectKey{resource: resource, namespace: namespace, name: name}
+	switch {
+	case r.Method == http.MethodGet && name == "":
+		s.list(w, r, resource, namespace)
+	case r.Method == http.MethodPost && name == "":
+		s.create(w, r, groupResource, key)
+	case r.Method == http.MethodGet:
+		s.get(w, groupResource, key)
+	case r.Method == http.MethodPut:
+		s.update(w, r, groupResource, key)
+	case r.Method == http.MethodDelete:
+		s.delete(w, groupResource, key)
+	default:
+		writeFakeStatus(w, apierrors.NewMethodNotSupported(groupResource, r.Method))
+	}
+}
+
+func (s *fakeAPIServer) takeError(method, resource string) (int, bool) {
+	for i, e := range s.errors {
+		if e.method == method && e.resource == resource {
+			s.errors = append(s.errors[:i], s.errors[i+1:]...)
+			return e.code, true
+		}
+	}
+	return 0, false
+}
+
+func (s *fakeAPIServer) list(w http.ResponseWriter, r *http.Request, resource, namespace string) {
+	selector, err := labels.Parse(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		writeFakeStatus(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	items := []interface{}{}
+	for key, object := range s.objects {
+		if key.resource != resource || key.namespace != namespace ||
+			!selector.Matches(labels.Set(object.object.GetLabels())) {
+			continue
+		}
+		s.refresh(resource, object)
+		items = append(items, object.object.Object)
+	}
+	itemName := func(i int) string {
+		return items[i].(map[string]interface{})["metadata"].(map[string]interface{})["name"].(string)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return itemName(i) < itemName(j)
+	})
+	// The continue token is the name of the last object of the previous page
+	if continueName := r.URL.Query().Get("continue"); continueName != "" {
+		start := sort.Search(len(items), func(i int) bool {
+			return itemName(i) > continueName
+		})
+		items = items[start:]
+	}
+	metadata := map[string]interface{}{"resourceVersion": strconv.FormatInt(s.resourceVersion, 10)}
+	if limit, _ := strconv.Atoi(r.URL.Query().Get("limit")); limit > 0 && len(items) > limit {
+		items = items[:limit]
+		metadata["continue"] = itemName(limit - 1)
+	}
+	s.listRequests++
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"apiVersion": "cloud.streamnative.io/v1alpha1",
+		"kind":       fakeAPIServerKinds[resource] + "List",
+		"metadata":   metadata,
+		"items":      items,
+	})
+}
+
+func (s *fakeAPIServer) create(
+	w http.ResponseWriter, r *http.Request, groupResource k8sschema.GroupResource, key fakeObjectKey) {
+	object, err := decodeFakeObject(r)
+	if err != nil {
+		writeFakeStatus(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	object.SetNamespace(key.namespace)
+	if object.GetName() == "" {
+		object.SetName(object.GetGenerateName() + strings.ToLower(uuid.New().String()[:5]))
+	}
+	key.name = object.GetName()
+	if _, ok := s.objects[key]; ok {
+		writeFakeStatus(w, apierrors.NewAlreadyExists(groupResource, key.name))
+		return
+	}
+	s.initObject(key.resource, object)
+	s.setNotReady(object)
+	s.objects[key] = &fakeObject{object: object, readyAt: time.Now().Add(s.readyDelay)}
+	writeFakeJSON(w, http.StatusCreated, object.Object)
+}
+
+func (s *fakeAPIServer) get(w http.ResponseWriter, groupResource k8sschema.GroupResource, key fakeObjectKey) {
+	object, ok := s.objects[key]
+	if !ok {
+		writeFakeStatus(w, apierrors.NewNotFound(groupResource, key.name))
+		return
+	}
+	s.refresh(key.resource, object)
+	writeFakeJSON(w, http.StatusOK, object.object.Object)
+}
+
+func (s *fakeAPIServer) update(
+	w http.ResponseWriter, r *http.Request, groupResource k8sschema.GroupResource, key fakeObjectKey) {
+	existing, ok := s.objects[key]
+	if !ok {
+		writeFakeStatus(w, apierrors.NewNotFound(groupResource, key.name))
+		return
+	}
+	object, err := decodeFakeObject(r)
+	if err != nil {
+		writeFakeStatus(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	if object.GetResourceVersion() != existing.object.GetResourceVersion() {
+		writeFakeStatus(w, apierrors.NewConflict(groupResource, key.name, fmt.Errorf(
+			"the object has been modified; please apply your changes to the latest version and try again")))
+		return
+	}
+	// The status is owned by the server, and the objects are reconciled again after the change. Like
+	// the API server, only the changes of the spec start a new generation
+	object.SetNamespace(key.namespace)
+	object.SetUID(existing.object.GetUID())
+	object.SetCreationTimestamp(existing.object.GetCreationTimestamp())
+	object.SetGeneration(existing.object.GetGeneration())
+	if !equality.Semantic.DeepEqual(object.Object["spec"], existing.object.Object["spec"]) {
+		object.SetGeneration(existing.object.GetGeneration() + 1)
+	}
+	s.resourceVersion++
+	object.SetResourceVersion(strconv.FormatInt(s.resourceVersion, 10))
+	if status, ok := existing.object.Object["status"]; ok {
+		object.Object["status"] = status
+	}
+	s.setDefaults(key.resource, object)
+	s.setNotReady(object)
+	s.objects[key] = &fakeObject{object: object, readyAt: time.Now().Add(s.readyDelay)}
+	writeFakeJSON(w, http.StatusOK, object.Object)
+}
+
+func (s *fakeAPIServer) delete(w http.ResponseWriter, groupResource k8sschema.GroupResource, key fakeObjectKey) {
+	if _, ok := s.objects[key]; !ok {
+		writeFakeStatus(w, apierrors.NewNotFound(groupResource, key.name))
+		return
+	}
+	delete(s.objects, key)
+	writeFakeJSON(w, http.StatusOK, map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Status",
+		"status":     "Success",
+	})
+}
+
+func (s *fakeAPIServer) initObject(resource string, object *unstructured.Unstructured) {
+	object.SetAPIVersion("cloud.streamnative.io/v1alpha1")
+	object.SetKind(fakeAPIServerKinds[resource])
+	object.SetUID(types.UID(uuid.New().String()))
+	object.SetCreationTimestamp(metav1.Now())
+	object.SetGeneration(1)
+	s.resourceVersion++
+	object.SetResourceVersion(strconv.FormatInt(s.resourceVersion, 10))
+	s.setDefaults(resource, object)
+}
+
+// setDefaults fills in the spec fields which are set by the API server
+func (s *fakeAPIServer) setDefaults(resource string, object *unstructured.Unstructured) {
+	if resource != "pulsarclusters" {
+		return
+	}
+	if _, ok, _ := unstructured.NestedSlice(object.Object, "spec", "serviceEndpoints"); !ok {
+		_ = unstructured.SetNestedSlice(object.Object, []interface{}{
+			map[string]interface{}{
+				"dnsName": fmt.Sprintf("%s.%s.fake.streamnative.test", object.GetName(), object.GetNamespace()),
+				"type":    "service",
+			},
+		}, "spec", "serviceEndpoints")
+	}
+	if image, _, _ := unstructured.NestedString(object.Object, "spec", "broker", "image"); image == "" {
+		_ = unstructured.SetNestedField(object.Object, "streamnative/sn-platform:3.0.0", "spec", "broker", "image")
+	}
+	if _, ok, _ := unstructured.NestedMap(object.Object, "spec", "bookkeeper"); ok {
+		if image, _, _ := unstructured.NestedString(object.Object, "spec", "bookkeeper", "image"); image == "" {
+			_ = unstructured.SetNestedField(object.Object,
+				"streamnative/sn-platform:3.0.0", "spec", "bookkeeper", "image")
+		}
+	}
+}
+
+// refresh makes the object ready once the ready delay has passed
+func (s *fakeAPIServer) refresh(resource string, object *fakeObject) {
+	if object.readyAt.IsZero() || time.Now().Before(object.readyAt) {
+		return
+	}
+	object.readyAt = time.Time{}
+	s.setReady(resource, object.object)
+}
+
+func (s *fakeAPIServer) setNotReady(object *unstructured.Unstructured) {
+	_ = unstructured.SetNestedSlice(object.Object, []interface{}{
+		fakeCondition("Ready", "False", object.GetGeneration()),
+	}, "status", "conditions")
+}
+
+func (s *fakeAPIServer) setReady(resource string, object *unstructured.Unstructured) {
+	conditions := []interface{}{fakeCondition("Ready", "True", object.GetGeneration())}
+	switch resource {
+	case "apikeys":
+		s.issueAPIKey(object)
+		conditions = append(conditions, fakeCondition("Issued", "True", object.GetGeneration()))
+	case "serviceaccounts":
+		// The private key changes on every update of the service account, which includes the key rotation
+		privateKeyData, _ := json.Marshal(map[string]string{
+			"type":          "sn_service_account",
+			"client_id":     fakeAPIServerClientID,
+			"client_secret": fakeAPIServerClientSecret + "-" + object.GetResourceVersion(),
+			"client_email":  fmt.Sprintf("%s@%s.auth.streamnative.cloud", object.GetName(), object.GetNamespace()),
+			"issuer_url":    s.URL + "/",
+		})
+		_ = unstructured.SetNestedField(object.Object,
+			base64.StdEncoding.EncodeToString(privateKeyData), "status", "privateKeyData")
+	}
+	_ = unstructured.SetNestedSlice(object.Object, conditions, "status", "conditions")
+}
+
+// issueAPIKey sets the status of an issued or revoked api key, the token is encrypted with the
+// encryption key of the spec like the API server does
+func (s *fakeAPIServer) issueAPIKey(object *unstructured.Unstructured) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	if revoke, _, _ := unstructured.NestedBool(object.Object, "spec", "revoke"); revoke {
+		if revokedAt, _, _ := unstructured.NestedString(object.Object, "status", "revokedAt"); revokedAt == "" {
+			_ = unstructured.SetNestedField(object.Object, now, "status", "revokedAt")
+		}
+		return
+	}
+	if keyID, _, _ := unstructured.NestedString(object.Object, "status", "keyId"); keyID == "" {
+		_ = unstructured.SetNestedField(object.Object, uuid.New().String(), "status", "keyId")
+		_ = unstructured.SetNestedField(object.Object, now, "status", "issuedAt")
+	}
+	expiresAt, _, _ := unstructured.NestedString(object.Object, "spec", "expirationTime")
+	if expiresAt == "" {
+		expiresAt = time.Now().Add(30 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	}
+	_ = unstructured.SetNestedField(object.Object, expiresAt, "status", "expiresAt")
+	pemKey, _, _ := unstructured.NestedString(object.Object, "spec", "encryptionKey", "pem")
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return
+	}
+	token, err := jwe.Encrypt([]byte("fake-token-"+object.GetName()), jwe.WithKey(jwa.RSA_OAEP, publicKey))
+	if err != nil {
+		return
+	}
+	_ = unstructured.SetNestedField(object.Object, string(token), "status", "encryptedToken", "jwe")
+}
+
+func fakeCondition(conditionType, status string, observedGeneration int64) interface{} {
+	return map[string]interface{}{
+		"type":               conditionType,
+		"status":             status,
+		"reason":             conditionType,
+		"observedGeneration": observedGeneration,
+		"lastTransitionTime": time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+func decodeFakeObject(r *http.Request) (*unstructured.Unstructured, error) {
+	object := &unstructured.Unstructured{}
+	if err := json.NewDecoder(r.Body).Decode(&object.Object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func writeFakeStatus(w http.ResponseWriter, err *apierrors.StatusError) {
+	status := err.Status()
+	status.APIVersion = "v1"
+	status.Kind = "Status"
+	writeFakeJSON(w, int(status.Code), status)
+}
+
+func writeFakeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestFakeAPIServer(t *testing.T) {
+	server := newFakeAPIServer(0)
+	t.Cleanup(server.Close)
+	server.setTestEnv(t)
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{})
+	meta, diags := providerConfigure(context.Background(), d, "")
+	assert.False(t, diags.HasError(), diags)
+	clientSet, err := getClientSet(getFactoryFromMeta(meta))
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	poolOption, err := clientSet.CloudV1alpha1().PoolOptions("sndev").Get(
+		ctx, "streamnative-shared-gcp-prod", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, cloudv1alpha1.PoolDeploymentTypeHosted, poolOption.Spec.DeploymentType)
+
+	instances := clientSet.CloudV1alpha1().PulsarInstances("sndev")
+	instance, err := instances.Create(ctx, &cloudv1alpha1.PulsarInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "fake-instance"},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	_, err = instances.Create(ctx, instance, metav1.CreateOptions{})
+	assert.True(t, apierrors.IsAlreadyExists(err), err)
+	instance, err = instances.Get(ctx, "fake-instance", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Ready", instance.Status.Conditions[0].Type)
+	assert.Equal(t, "True", string(instance.Status.Conditions[0].Status))
+
+	// The update is rejected when the object changed since it was read
+	stale := instance.DeepCopy()
+	instance.Labels = map[string]string{"team": "a"}
+	_, err = instances.Update(ctx, instance, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	_, err = instances.Update(ctx, stale, metav1.UpdateOptions{})
+	assert.True(t, apierrors.IsConflict(err), err)
+	list, err := instances.List(ctx, metav1.ListOptions{LabelSelector: "team=a"})
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 1)
+	list, err = instances.List(ctx, metav1.ListOptions{LabelSelector: "team=b"})
+	assert.NoError(t, err)
+	assert.Empty(t, list.Items)
+
+	// The server errors are retried by the provider, the other errors are returned
+	server.injectError(http.MethodGet, "pulsarinstances", http.StatusServiceUnavailable)
+	_, err = instances.Get(ctx, "fake-instance", metav1.GetOptions{})
+	assert.NoError(t, err)
+	server.injectError(http.MethodGet, "pulsarinstances", http.StatusForbidden)
+	_, err = instances.Get(ctx, "fake-instance", metav1.GetOptions{})
+	assert.True(t, apierrors.IsForbidden(err), err)
+
+	assert.NoError(t, instances.Delete(ctx, "fake-instance", metav1.DeleteOptions{}))
+	_, err = instances.Get(ctx, "fake-instance", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), err)
+
+	// The token of the api key is encrypted with the encryption key of the spec
+	privateKey, err := util.GenerateEncryptionKey()
+	assert.NoError(t, err)
+	encryptionKey, err := util.ExportPublicKey(privateKey)
+	assert.NoError(t, err)
+	apiKeys := clientSet.CloudV1alpha1().APIKeys("sndev")
+	_, err = apiKeys.Create(ctx, &cloudv1alpha1.APIKey{
+		ObjectMeta: metav1.ObjectMeta{Name: "fake-apikey"},
+		Spec: cloudv1alpha1.APIKeySpec{
+			InstanceName:       "fake-instance",
+			ServiceAccountName: "fake-service-account",
+			EncryptionKey:      encryptionKey,
+		},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	apiKey, err := apiKeys.Get(ctx, "fake-apikey", metav1.GetOptions{})
+	assert.NoError(t, err)
+	token, ok := decryptIssuedApiKeyToken(apiKey, privateKey)
+	assert.True(t, ok)
+	assert.Equal(t, "fake-token-fake-apikey", token)
+	assert.NotEmpty(t, apiKey.Status.KeyId)
+}
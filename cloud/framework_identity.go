@@ -0,0 +1,125 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/identityschema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// instanceResourceIdentityModel is the identity of the framework resources which belong to a pulsar
+// instance, it matches the identity of the SDK resources built with instanceResourceIdentity
+type instanceResourceIdentityModel struct {
+	Organization types.String `tfsdk:"organization"`
+	Name         types.String `tfsdk:"name"`
+	InstanceName types.String `tfsdk:"instance_name"`
+}
+
+func instanceResourceIdentitySchema() identityschema.Schema {
+	return identityschema.Schema{
+		Version: 1,
+		Attributes: map[string]identityschema.Attribute{
+			"organization": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       descriptions["identity_organization"],
+			},
+			"name": identityschema.StringAttribute{
+				RequiredForImport: true,
+				Description:       descriptions["identity_name"],
+			},
+			"instance_name": identityschema.StringAttribute{
+				OptionalForImport: true,
+				Description:       descriptions["identity_instance_name"],
+			},
+		},
+	}
+}
+
+// setInstanceResourceIdentity sets the identity after the create, read or update, the identity is nil
+// when Terraform doesn't support the resource identity. The identity stored in the state must match
+// the object which is read
+func setInstanceResourceIdentity(
+	ctx context.Context, identity *tfsdk.ResourceIdentity, organization, name, instanceName types.String) diag.Diagnostics {
+	if identity == nil {
+		return nil
+	}
+	if !identity.Raw.IsNull() {
+		var stored instanceResourceIdentityModel
+		if diags := identity.Get(ctx, &stored); diags.HasError() {
+			return diags
+		}
+		err := checkResourceIdentity(map[string]string{
+			"organization":  stored.Organization.ValueString(),
+			"name":          stored.Name.ValueString(),
+			"instance_name": stored.InstanceName.ValueString(),
+		}, map[string]string{
+			"organization":  organization.ValueString(),
+			"name":          name.ValueString(),
+			"instance_name": instanceName.ValueString(),
+		})
+		if err != nil {
+			var diags diag.Diagnostics
+			diags.AddError("ERROR_RESOURCE_IDENTITY_CHANGED", err.Error())
+			return diags
+		}
+	}
+	return identity.Set(ctx, instanceResourceIdentityModel{
+		Organization: organization,
+		Name:         name,
+		InstanceName: instanceName,
+	})
+}
+
+// importInstanceResourceState sets the id, organization and name of the imported resource, either from
+// the import ID parsed by parseImportID or from the identity of the import block
+func importInstanceResourceState(
+	ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse, summary string) {
+	identity := instanceResourceIdentityModel{}
+	if req.ID != "" {
+		id, err := parseImportID(req.ID, true)
+		if err != nil {
+			resp.Diagnostics.AddError(summary, err.Error())
+			return
+		}
+		identity.Organization = types.StringValue(id.Organization)
+		identity.Name = types.StringValue(id.Name)
+		if id.InstanceName != "" {
+			identity.InstanceName = types.StringValue(id.InstanceName)
+		}
+	} else if req.Identity != nil {
+		resp.Diagnostics.Append(req.Identity.Get(ctx, &identity)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+	if identity.Organization.ValueString() == "" || identity.Name.ValueString() == "" {
+		resp.Diagnostics.AddError(summary, "the identity must contain the organization and the name")
+		return
+	}
+	id := fmt.Sprintf("%s/%s", identity.Organization.ValueString(), identity.Name.ValueString())
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("organization"), identity.Organization)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("name"), identity.Name)...)
+	if !identity.InstanceName.IsNull() && !identity.InstanceName.IsUnknown() {
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("instance_name"), identity.InstanceName)...)
+	}
+}
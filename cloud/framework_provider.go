@@ -0,0 +1,271 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty/msgpack"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/action"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-mux/tf5muxserver"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+// NewProviderServer combines the SDKv2 provider with the framework provider, the resources are moved
+// to the framework provider one at a time and both providers share the same configuration and clients
+func NewProviderServer(ctx context.Context, sdkProvider *schema.Provider) (func() tfprotov5.ProviderServer, error) {
+	muxServer, err := tf5muxserver.NewMuxServer(ctx,
+		// The SDKv2 provider must be configured first, the framework provider reuses its meta
+		sdkProvider.GRPCProvider,
+		func() tfprotov5.ProviderServer {
+			return &blockLimitsProviderServer{
+				ProviderServer: providerserver.NewProtocol5(NewFrameworkProvider(sdkProvider)())(),
+				sdkSchema:      sdkProvider.Schema,
+			}
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return muxServer.ProviderServer, nil
+}
+
+// blockLimitsProviderServer reports the MinItems and MaxItems of the SDKv2 provider blocks in the framework
+// provider schema, the framework never reports the limits of the blocks and the mux server requires the
+// provider schemas to be identical
+type blockLimitsProviderServer struct {
+	tfprotov5.ProviderServer
+	sdkSchema map[string]*schema.Schema
+}
+
+func (s *blockLimitsProviderServer) GetProviderSchema(
+	ctx context.Context, req *tfprotov5.GetProviderSchemaRequest) (*tfprotov5.GetProviderSchemaResponse, error) {
+	resp, err := s.ProviderServer.GetProviderSchema(ctx, req)
+	if err != nil || resp.Provider == nil || resp.Provider.Block == nil {
+		return resp, err
+	}
+	for _, block := range resp.Provider.Block.BlockTypes {
+		if sdkSchema, ok := s.sdkSchema[block.TypeName]; ok {
+			block.MinItems = int64(sdkSchema.MinItems)
+			block.MaxItems = int64(sdkSchema.MaxItems)
+		}
+	}
+	return resp, nil
+}
+
+// frameworkProvider serves the resources migrated to terraform-plugin-framework
+type frameworkProvider struct {
+	sdkProvider *schema.Provider
+}
+
+var (
+	_ provider.Provider                       = &frameworkProvider{}
+	_ provider.ProviderWithEphemeralResources = &frameworkProvider{}
+	_ provider.ProviderWithFunctions          = &frameworkProvider{}
+	_ provider.ProviderWithListResources      = &frameworkProvider{}
+	_ provider.ProviderWithActions            = &frameworkProvider{}
+)
+
+func NewFrameworkProvider(sdkProvider *schema.Provider) func() provider.Provider {
+	return func() provider.Provider {
+		return &frameworkProvider{
+			sdkProvider: sdkProvider,
+		}
+	}
+}
+
+func (p *frameworkProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "streamnative"
+	resp.Version = ProviderVersion
+}
+
+// Schema is converted from the SDKv2 provider schema, the schemas of the muxed providers must be identical
+func (p *frameworkProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	attributes, blocks := frameworkProviderSchema(p.sdkProvider.Schema)
+	resp.Schema = providerschema.Schema{
+		Attributes: attributes,
+		Blocks:     blocks,
+	}
+}
+
+// Configure shares the meta of the SDKv2 provider, the provider is only configured here when
+// the framework provider is served alone
+func (p *frameworkProvider) Configure(
+	ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	meta := p.sdkProvider.Meta()
+	if meta == nil {
+		p.sdkProvider.TerraformVersion = req.TerraformVersion
+		if err := configureSDKProvider(ctx, p.sdkProvider, req.Config.Raw); err != nil {
+			resp.Diagnostics.AddError("ERROR_CONFIGURE_PROVIDER", err.Error())
+			return
+		}
+		meta = p.sdkProvider.Meta()
+	}
+	resp.ResourceData = meta
+	resp.DataSourceData = meta
+	resp.EphemeralResourceData = meta
+	resp.ListResourceData = meta
+	resp.ActionData = meta
+}
+
+func (p *frameworkProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		NewApiKeyResource,
+		NewSecretResource,
+	}
+}
+
+func (p *frameworkProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+func (p *frameworkProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewApiKeyTokenEphemeralResource,
+	}
+}
+
+// ListResources lists the objects of an organization for `terraform query`, the list resources of the
+// SDKv2 resources take their schemas from the SDKv2 provider
+func (p *frameworkProvider) ListResources(_ context.Context) []func() list.ListResource {
+	return []func() list.ListResource{
+		newSDKListResource("pulsar_cluster", "pulsarclusters", resourcePulsarCluster, "spec", "instanceName"),
+		newSDKListResource("pulsar_instance", "pulsarinstances", resourcePulsarInstance),
+		newSDKListResource("pulsar_gateway", "pulsargateways", resourcePulsarGateway),
+		newSDKListResource("service_account", "serviceaccounts", resourceServiceAccount),
+		newSDKListResource("rolebinding", "rolebindings", resourceRoleBinding),
+		newSDKListResource("volume", "volumes", resourceVolume),
+		newSDKListResource("catalog", "catalogs", resourceCatalog),
+		newListResource("apikey", "apikeys", NewApiKeyResource, "spec", "instanceName"),
+		newListResource("secret", "secrets", NewSecretResource, "instanceName"),
+	}
+}
+
+func (p *frameworkProvider) Actions(_ context.Context) []func() action.Action {
+	return []func() action.Action{
+		NewPulsarClusterRestartAction,
+		NewApiKeyRevokeAction,
+		NewServiceAccountRotateKeyAction,
+	}
+}
+
+func (p *frameworkProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewComputeUnitsToResourcesFunction,
+		NewServiceURLsFunction,
+		NewParseClusterIDFunction,
+		NewS3TableRegionFunction,
+	}
+}
+
+// configureSDKProvider configures the SDKv2 provider with the raw provider configuration
+func configureSDKProvider(ctx context.Context, sdkProvider *schema.Provider, config tftypes.Value) error {
+	dynamicValue, err := tfprotov5.NewDynamicValue(config.Type(), config)
+	if err != nil {
+		return err
+	}
+	schemaBlock := schema.InternalMap(sdkProvider.Schema).CoreConfigSchema()
+	configValue, err := msgpack.Unmarshal(dynamicValue.MsgPack, schemaBlock.ImpliedType())
+	if err != nil {
+		return err
+	}
+	resourceConfig := terraform.NewResourceConfigShimmed(configValue, schemaBlock)
+	resourceConfig.CtyValue = configValue
+	for _, d := range sdkProvider.Configure(ctx, resourceConfig) {
+		if d.Severity == diag.Error {
+			return fmt.Errorf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+	return nil
+}
+
+// frameworkProviderSchema converts the SDKv2 provider schema, only the types used by the provider
+// configuration are supported
+func frameworkProviderSchema(
+	sdkSchema map[string]*schema.Schema) (map[string]providerschema.Attribute, map[string]providerschema.Block) {
+	attributes := map[string]providerschema.Attribute{}
+	blocks := map[string]providerschema.Block{}
+	for name, s := range sdkSchema {
+		switch s.Type {
+		case schema.TypeString:
+			attributes[name] = providerschema.StringAttribute{
+				Optional:    s.Optional,
+				Required:    s.Required,
+				Sensitive:   s.Sensitive,
+				Description: s.Description,
+			}
+		case schema.TypeBool:
+			attributes[name] = providerschema.BoolAttribute{
+				Optional:    s.Optional,
+				Required:    s.Required,
+				Sensitive:   s.Sensitive,
+				Description: s.Description,
+			}
+		case schema.TypeInt:
+			attributes[name] = providerschema.Int64Attribute{
+				Optional:    s.Optional,
+				Required:    s.Required,
+				Sensitive:   s.Sensitive,
+				Description: s.Description,
+			}
+		case schema.TypeFloat:
+			attributes[name] = providerschema.Float64Attribute{
+				Optional:    s.Optional,
+				Required:    s.Required,
+				Sensitive:   s.Sensitive,
+				Description: s.Description,
+			}
+		case schema.TypeList:
+			elem, ok := s.Elem.(*schema.Resource)
+			if !ok {
+				panic(fmt.Sprintf("unsupported provider attribute %q", name))
+			}
+			nestedAttributes, nestedBlocks := frameworkProviderSchema(elem.Schema)
+			var validators []validator.List
+			if s.MaxItems > 0 {
+				validators = append(validators, listvalidator.SizeAtMost(s.MaxItems))
+			}
+			if s.MinItems > 0 {
+				validators = append(validators, listvalidator.SizeAtLeast(s.MinItems))
+			}
+			blocks[name] = providerschema.ListNestedBlock{
+				Description: s.Description,
+				NestedObject: providerschema.NestedBlockObject{
+					Attributes: nestedAttributes,
+					Blocks:     nestedBlocks,
+				},
+				Validators: validators,
+			}
+		default:
+			panic(fmt.Sprintf("unsupported provider attribute %q", name))
+		}
+	}
+	return attributes, blocks
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type computeUnitsToResourcesFunction struct{}
+
+type computeUnitsToResourcesResult struct {
+	CPU    types.String `tfsdk:"cpu"`
+	Memory types.String `tfsdk:"memory"`
+}
+
+var _ function.Function = &computeUnitsToResourcesFunction{}
+
+func NewComputeUnitsToResourcesFunction() function.Function {
+	return &computeUnitsToResourcesFunction{}
+}
+
+func (f *computeUnitsToResourcesFunction) Metadata(
+	_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "compute_units_to_resources"
+}
+
+func (f *computeUnitsToResourcesFunction) Definition(
+	_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Converts the compute units or the storage units to CPU and memory",
+		Description: descriptions["function_compute_units_to_resources"],
+		Parameters: []function.Parameter{
+			function.Float64Parameter{
+				Name:        "units",
+				Description: "The compute_unit_per_broker or the storage_unit_per_bookie of the pulsar cluster",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"cpu":    types.StringType,
+				"memory": types.StringType,
+			},
+		},
+	}
+}
+
+func (f *computeUnitsToResourcesFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var units float64
+	resp.Error = req.Arguments.Get(ctx, &units)
+	if resp.Error != nil {
+		return
+	}
+	if units <= 0 {
+		resp.Error = function.NewArgumentFuncError(0, "units must be greater than 0")
+		return
+	}
+	cpu, memory := convertUnitToCpuAndMemory(units)
+	resp.Error = resp.Result.Set(ctx, computeUnitsToResourcesResult{
+		CPU:    types.StringValue(cpu.String()),
+		Memory: types.StringValue(memory.String()),
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type parseClusterIDFunction struct{}
+
+type parseClusterIDResult struct {
+	Organization types.String `tfsdk:"organization"`
+	Name         types.String `tfsdk:"name"`
+}
+
+var _ function.Function = &parseClusterIDFunction{}
+
+func NewParseClusterIDFunction() function.Function {
+	return &parseClusterIDFunction{}
+}
+
+func (f *parseClusterIDFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "parse_cluster_id"
+}
+
+func (f *parseClusterIDFunction) Definition(
+	_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Parses the ID of a pulsar cluster",
+		Description: descriptions["function_parse_cluster_id"],
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "id",
+				Description: "The ID of the pulsar cluster in the format <organization>/<name>",
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"organization": types.StringType,
+				"name":         types.StringType,
+			},
+		},
+	}
+}
+
+func (f *parseClusterIDFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var id string
+	resp.Error = req.Arguments.Get(ctx, &id)
+	if resp.Error != nil {
+		return
+	}
+	parts := strings.Split(id, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		resp.Error = function.NewArgumentFuncError(0,
+			fmt.Sprintf("invalid cluster id %q, expected <organization>/<name>", id))
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, parseClusterIDResult{
+		Organization: types.StringValue(parts[0]),
+		Name:         types.StringValue(parts[1]),
+	})
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+type s3TableRegionFunction struct{}
+
+var _ function.Function = &s3TableRegionFunction{}
+
+func NewS3TableRegionFunction() function.Function {
+	return &s3TableRegionFunction{}
+}
+
+func (f *s3TableRegionFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "s3table_region"
+}
+
+func (f *s3TableRegionFunction) Definition(
+	_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Extracts the region of an S3 table bucket",
+		Description: descriptions["function_s3table_region"],
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "arn",
+				Description: "The ARN of the S3 table bucket, e.g. arn:aws:s3tables:ap-northeast-1:592060915564:bucket/name",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *s3TableRegionFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var arn string
+	resp.Error = req.Arguments.Get(ctx, &arn)
+	if resp.Error != nil {
+		return
+	}
+	region, err := extractS3TableRegion(arn)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, err.Error())
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, region)
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+type serviceURLsFunction struct{}
+
+var _ function.Function = &serviceURLsFunction{}
+
+func NewServiceURLsFunction() function.Function {
+	return &serviceURLsFunction{}
+}
+
+func (f *serviceURLsFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "service_urls"
+}
+
+func (f *serviceURLsFunction) Definition(
+	_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Formats the service URL of a pulsar cluster endpoint",
+		Description: descriptions["function_service_urls"],
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "dns_name",
+				Description: "The DNS name of the service endpoint",
+			},
+			function.StringParameter{
+				Name:        "protocol",
+				Description: fmt.Sprintf("The protocol of the service URL, one of %s", strings.Join(serviceProtocols, ", ")),
+			},
+			function.BoolParameter{
+				Name:        "istio_enabled",
+				Description: "Whether istio is enabled on the pulsar instance",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *serviceURLsFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var dnsName, protocol string
+	var istioEnabled bool
+	resp.Error = req.Arguments.Get(ctx, &dnsName, &protocol, &istioEnabled)
+	if resp.Error != nil {
+		return
+	}
+	if dnsName == "" {
+		resp.Error = function.NewArgumentFuncError(0, "dns_name cannot be empty")
+		return
+	}
+	if !slices.Contains(serviceProtocols, protocol) {
+		resp.Error = function.NewArgumentFuncError(1, fmt.Sprintf(
+			"unsupported protocol %q, supported protocols are %s", protocol, strings.Join(serviceProtocols, ", ")))
+		return
+	}
+	resp.Error = resp.Result.Set(ctx, formatServiceURL(dnsName, protocol, istioEnabled))
+}
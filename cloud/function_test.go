@@ -0,0 +1,135 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/stretchr/testify/assert"
+)
+
+func callTestFunction(
+	t *testing.T, name string, returnType tftypes.Type, args ...tftypes.Value) (tftypes.Value, *tfprotov5.FunctionError) {
+	server, err := NewProviderServer(context.Background(), Provider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	arguments := make([]*tfprotov5.DynamicValue, 0, len(args))
+	for _, arg := range args {
+		value, err := tfprotov5.NewDynamicValue(arg.Type(), arg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arguments = append(arguments, &value)
+	}
+	resp, err := server().CallFunction(context.Background(), &tfprotov5.CallFunctionRequest{
+		Name:      name,
+		Arguments: arguments,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error != nil {
+		return tftypes.Value{}, resp.Error
+	}
+	result, err := resp.Result.Unmarshal(returnType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result, nil
+}
+
+func TestComputeUnitsToResourcesFunction(t *testing.T) {
+	resourcesType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"cpu":    tftypes.String,
+		"memory": tftypes.String,
+	}}
+	result, funcErr := callTestFunction(t, "compute_units_to_resources", resourcesType,
+		tftypes.NewValue(tftypes.Number, 0.5))
+	assert.Nil(t, funcErr)
+	assert.True(t, tftypes.NewValue(resourcesType, map[string]tftypes.Value{
+		"cpu":    tftypes.NewValue(tftypes.String, "1"),
+		"memory": tftypes.NewValue(tftypes.String, "4294967296"),
+	}).Equal(result), result.String())
+
+	_, funcErr = callTestFunction(t, "compute_units_to_resources", resourcesType,
+		tftypes.NewValue(tftypes.Number, 0))
+	assert.NotNil(t, funcErr)
+}
+
+func TestServiceURLsFunction(t *testing.T) {
+	dnsName := "pc-test.gcp-shared-usce1.g.snio.cloud"
+	result, funcErr := callTestFunction(t, "service_urls", tftypes.String,
+		tftypes.NewValue(tftypes.String, dnsName), tftypes.NewValue(tftypes.String, "pulsar"),
+		tftypes.NewValue(tftypes.Bool, false))
+	assert.Nil(t, funcErr)
+	assert.True(t, tftypes.NewValue(tftypes.String, "pulsar+ssl://"+dnsName+":6651").Equal(result), result.String())
+
+	result, funcErr = callTestFunction(t, "service_urls", tftypes.String,
+		tftypes.NewValue(tftypes.String, dnsName), tftypes.NewValue(tftypes.String, "websocket"),
+		tftypes.NewValue(tftypes.Bool, true))
+	assert.Nil(t, funcErr)
+	assert.True(t, tftypes.NewValue(tftypes.String, "wss://"+dnsName).Equal(result), result.String())
+
+	_, funcErr = callTestFunction(t, "service_urls", tftypes.String,
+		tftypes.NewValue(tftypes.String, dnsName), tftypes.NewValue(tftypes.String, "amqp"),
+		tftypes.NewValue(tftypes.Bool, false))
+	assert.NotNil(t, funcErr)
+
+	// istio_enabled is required
+	_, funcErr = callTestFunction(t, "service_urls", tftypes.String,
+		tftypes.NewValue(tftypes.String, dnsName), tftypes.NewValue(tftypes.String, "pulsar"))
+	assert.NotNil(t, funcErr)
+}
+
+func TestFormatServiceURL(t *testing.T) {
+	dnsName := "pc-test.gcp-shared-usce1.g.snio.cloud"
+	assert.Equal(t, "https://"+dnsName, formatServiceURL(dnsName, ServiceProtocolHTTP, false))
+	assert.Equal(t, "pulsar+ssl://"+dnsName+":6651", formatServiceURL(dnsName, ServiceProtocolPulsar, false))
+	assert.Equal(t, "ws://"+dnsName+":9443", formatServiceURL(dnsName, ServiceProtocolWebsocket, false))
+	assert.Equal(t, "wss://"+dnsName, formatServiceURL(dnsName, ServiceProtocolWebsocket, true))
+	assert.Equal(t, dnsName+":9093", formatServiceURL(dnsName, ServiceProtocolKafka, true))
+	assert.Equal(t, "mqtts://"+dnsName+":8883", formatServiceURL(dnsName, ServiceProtocolMQTT, false))
+}
+
+func TestParseClusterIDFunction(t *testing.T) {
+	idType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"organization": tftypes.String,
+		"name":         tftypes.String,
+	}}
+	result, funcErr := callTestFunction(t, "parse_cluster_id", idType,
+		tftypes.NewValue(tftypes.String, "sndev/test-cluster"))
+	assert.Nil(t, funcErr)
+	assert.True(t, tftypes.NewValue(idType, map[string]tftypes.Value{
+		"organization": tftypes.NewValue(tftypes.String, "sndev"),
+		"name":         tftypes.NewValue(tftypes.String, "test-cluster"),
+	}).Equal(result), result.String())
+
+	_, funcErr = callTestFunction(t, "parse_cluster_id", idType, tftypes.NewValue(tftypes.String, "test-cluster"))
+	assert.NotNil(t, funcErr)
+}
+
+func TestS3TableRegionFunction(t *testing.T) {
+	result, funcErr := callTestFunction(t, "s3table_region", tftypes.String,
+		tftypes.NewValue(tftypes.String, "arn:aws:s3tables:ap-northeast-1:577003581484:bucket/s3-table-test"))
+	assert.Nil(t, funcErr)
+	assert.True(t, tftypes.NewValue(tftypes.String, "ap-northeast-1").Equal(result), result.String())
+
+	_, funcErr = callTestFunction(t, "s3table_region", tftypes.String, tftypes.NewValue(tftypes.String, "invalid-arn"))
+	assert.NotNil(t, funcErr)
+}
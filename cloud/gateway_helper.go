@@ -56,6 +56,16 @@ func convertEndpointAccess(val interface{}) []cloudv1alpha1.EndpointAccess {
 	return endpointAccess
 }
 
+func flattenEndpointAccess(in []cloudv1alpha1.EndpointAccess) []interface{} {
+	endpointAccess := make([]interface{}, 0, len(in))
+	for _, v := range in {
+		endpointAccess = append(endpointAccess, map[string]interface{}{
+			"gateway": v.Gateway,
+		})
+	}
+	return endpointAccess
+}
+
 func convertGateway(val interface{}) *cloudv1alpha1.Gateway {
 	gatewayRaw := val.([]interface{})
 	if len(gatewayRaw) > 0 {
@@ -1,5 +1,14 @@
 package cloud
 
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
 func convertToStringMap(m map[string]interface{}) map[string]string {
 	result := make(map[string]string)
 	for k, v := range m {
@@ -7,3 +16,25 @@ func convertToStringMap(m map[string]interface{}) map[string]string {
 	}
 	return result
 }
+
+// stringMapFromValue converts the map attribute of the framework resources, null is converted to nil
+func stringMapFromValue(ctx context.Context, value types.Map) (map[string]string, diag.Diagnostics) {
+	if value.IsNull() || value.IsUnknown() {
+		return nil, nil
+	}
+	result := map[string]string{}
+	diags := value.ElementsAs(ctx, &result, false)
+	return result, diags
+}
+
+// requiresReplaceIfMapChanged replaces the resource when the map changes, null and empty maps are
+// considered equal as the SDKv2 resources saved the unset maps as empty maps in the state
+func requiresReplaceIfMapChanged() planmodifier.Map {
+	return mapplanmodifier.RequiresReplaceIf(
+		func(_ context.Context, req planmodifier.MapRequest, resp *mapplanmodifier.RequiresReplaceIfFuncResponse) {
+			resp.RequiresReplace = len(req.StateValue.Elements()) > 0 || len(req.PlanValue.Elements()) > 0
+		},
+		"The resource is replaced when the value changes.",
+		"The resource is replaced when the value changes.",
+	)
+}
@@ -0,0 +1,301 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/list"
+	listschema "github.com/hashicorp/terraform-plugin-framework/list/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// listPageSize is the number of objects listed by one request, the next page is only listed when
+// Terraform expects more results
+var listPageSize int64 = 100
+
+// cloudListResource lists the objects of an organization for `terraform query`, every object is read
+// by the read function of its managed resource so the listed resources match the imported ones
+type cloudListResource struct {
+	typeName string
+	// resource is the plural name of the objects in the API
+	resource string
+	// instanceName is the field path of the pulsar instance name in the object, it's nil when the
+	// identity of the resource doesn't contain the instance name
+	instanceName []string
+	newResource  func() resource.Resource
+	meta         interface{}
+}
+
+// sdkListResource lists the objects of the resources served by the SDKv2 provider, the framework
+// takes the schemas of the resources from the SDKv2 provider
+type sdkListResource struct {
+	cloudListResource
+	sdkResource func() *schema.Resource
+}
+
+type cloudListResourceModel struct {
+	Organization  types.String `tfsdk:"organization"`
+	LabelSelector types.String `tfsdk:"label_selector"`
+}
+
+var (
+	_ list.ListResourceWithConfigure    = &cloudListResource{}
+	_ list.ListResourceWithConfigure    = &sdkListResource{}
+	_ list.ListResourceWithRawV5Schemas = &sdkListResource{}
+)
+
+func newListResource(
+	typeName, apiResource string, newResource func() resource.Resource, instanceName ...string) func() list.ListResource {
+	return func() list.ListResource {
+		return &cloudListResource{
+			typeName:     typeName,
+			resource:     apiResource,
+			instanceName: instanceName,
+			newResource:  newResource,
+		}
+	}
+}
+
+func newSDKListResource(
+	typeName, apiResource string, sdkResource func() *schema.Resource, instanceName ...string) func() list.ListResource {
+	return func() list.ListResource {
+		return &sdkListResource{
+			cloudListResource: cloudListResource{
+				typeName:     typeName,
+				resource:     apiResource,
+				instanceName: instanceName,
+			},
+			sdkResource: sdkResource,
+		}
+	}
+}
+
+func (r *cloudListResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_" + r.typeName
+}
+
+func (r *cloudListResource) ListResourceConfigSchema(
+	_ context.Context, _ list.ListResourceSchemaRequest, resp *list.ListResourceSchemaResponse) {
+	resp.Schema = listschema.Schema{
+		Description: fmt.Sprintf(descriptions["list_resource"], strings.ReplaceAll(r.typeName, "_", " ")),
+		Attributes: map[string]listschema.Attribute{
+			"organization": listschema.StringAttribute{
+				Required:    true,
+				Description: descriptions["organization"],
+			},
+			"label_selector": listschema.StringAttribute{
+				Optional:    true,
+				Description: descriptions["label_selector"],
+			},
+		},
+	}
+}
+
+func (r *cloudListResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	r.meta = req.ProviderData
+}
+
+func (r *sdkListResource) RawV5Schemas(ctx context.Context, _ list.RawV5SchemaRequest, resp *list.RawV5SchemaResponse) {
+	sdkResource := r.sdkResource()
+	resp.ProtoV5Schema = sdkResource.ProtoSchema(ctx)()
+	resp.ProtoV5IdentitySchema = sdkResource.ProtoIdentitySchema(ctx)()
+}
+
+func (r *cloudListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	r.list(ctx, req, stream, r.frameworkListResult)
+}
+
+func (r *sdkListResource) List(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream) {
+	r.list(ctx, req, stream, r.sdkListResult)
+}
+
+// list lists the objects matching the label selector page by page, the objects removed after being
+// listed are skipped
+func (r *cloudListResource) list(ctx context.Context, req list.ListRequest, stream *list.ListResultsStream,
+	listResult func(context.Context, list.ListRequest, *unstructured.Unstructured) (list.ListResult, bool)) {
+	var config cloudListResourceModel
+	diags := req.Config.Get(ctx, &config)
+	if diags.HasError() {
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+	if r.meta == nil {
+		diags.AddError("ERROR_INIT_CLIENT_ON_LIST_RESOURCES", "the provider is not configured")
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+	dynamicClient, err := getDynamicClient(getFactoryFromMeta(r.meta))
+	if err != nil {
+		diags.AddError("ERROR_INIT_CLIENT_ON_LIST_RESOURCES", err.Error())
+		stream.Results = list.ListResultsStreamDiagnostics(diags)
+		return
+	}
+	objects := dynamicClient.Resource(k8sschema.GroupVersionResource{
+		Group:    cloudv1alpha1.ApiVersion.GroupVersion.Group,
+		Version:  cloudv1alpha1.ApiVersion.GroupVersion.Version,
+		Resource: r.resource,
+	}).Namespace(config.Organization.ValueString())
+	options := metav1.ListOptions{
+		LabelSelector: config.LabelSelector.ValueString(),
+		Limit:         listPageSize,
+	}
+	stream.Results = func(push func(list.ListResult) bool) {
+		var count int64
+		for {
+			page, err := objects.List(ctx, options)
+			if err != nil {
+				result := list.ListResult{}
+				result.Diagnostics.AddError("ERROR_LIST_RESOURCES", err.Error())
+				push(result)
+				return
+			}
+			for i := range page.Items {
+				if req.Limit > 0 && count >= req.Limit {
+					return
+				}
+				result, ok := listResult(ctx, req, &page.Items[i])
+				if !ok {
+					continue
+				}
+				count++
+				if !push(result) {
+					return
+				}
+			}
+			options.Continue = page.GetContinue()
+			if options.Continue == "" {
+				return
+			}
+		}
+	}
+}
+
+// sdkListResult reads the object with the read function of the SDKv2 resource, only the identity is set
+// when the resource isn't included in the results
+func (r *sdkListResource) sdkListResult(
+	ctx context.Context, req list.ListRequest, object *unstructured.Unstructured) (list.ListResult, bool) {
+	result := req.NewListResult(ctx)
+	result.DisplayName = fmt.Sprintf("%s/%s", object.GetNamespace(), object.GetName())
+	sdkResource := r.sdkResource()
+	d := sdkResource.Data(&terraform.InstanceState{})
+	d.SetId(result.DisplayName)
+	_ = d.Set("organization", object.GetNamespace())
+	_ = d.Set("name", object.GetName())
+	attributes := organizationNameIdentity
+	if r.instanceName != nil {
+		attributes = instanceResourceIdentity
+		_ = d.Set("instance_name", r.objectInstanceName(object))
+	}
+	if req.IncludeResource {
+		diags := sdkResource.ReadContext(ctx, d, r.meta)
+		for _, diagnostic := range diags {
+			if diagnostic.Severity == diag.Error {
+				result.Diagnostics.AddError(diagnostic.Summary, diagnostic.Detail)
+			} else {
+				result.Diagnostics.AddWarning(diagnostic.Summary, diagnostic.Detail)
+			}
+		}
+		if diags.HasError() {
+			return result, true
+		}
+		if d.Id() == "" {
+			return result, false
+		}
+	} else if err := setResourceIdentity(d, attributes); err != nil {
+		result.Diagnostics.AddError("ERROR_SET_RESOURCE_IDENTITY", err.Error())
+		return result, true
+	}
+
+	identity, err := d.TfTypeIdentityState()
+	if err != nil {
+		result.Diagnostics.AddError("ERROR_SET_RESOURCE_IDENTITY", err.Error())
+		return result, true
+	}
+	result.Diagnostics.Append(result.Identity.Set(ctx, *identity)...)
+	if req.IncludeResource {
+		state, err := d.TfTypeResourceState()
+		if err != nil {
+			result.Diagnostics.AddError("ERROR_SET_RESOURCE_STATE", err.Error())
+			return result, true
+		}
+		result.Diagnostics.Append(result.Resource.Set(ctx, *state)...)
+	}
+	return result, true
+}
+
+// frameworkListResult reads the object with the Read method of the framework resource, only the
+// identity is set when the resource isn't included in the results
+func (r *cloudListResource) frameworkListResult(
+	ctx context.Context, req list.ListRequest, object *unstructured.Unstructured) (list.ListResult, bool) {
+	result := req.NewListResult(ctx)
+	result.DisplayName = fmt.Sprintf("%s/%s", object.GetNamespace(), object.GetName())
+	if !req.IncludeResource {
+		result.Diagnostics.Append(setInstanceResourceIdentity(ctx, result.Identity,
+			types.StringValue(object.GetNamespace()), types.StringValue(object.GetName()),
+			types.StringValue(r.objectInstanceName(object)))...)
+		return result, true
+	}
+
+	res := r.newResource()
+	if configurable, ok := res.(resource.ResourceWithConfigure); ok {
+		configurable.Configure(ctx, resource.ConfigureRequest{ProviderData: r.meta}, &resource.ConfigureResponse{})
+	}
+	state := tfsdk.State{
+		Schema: req.ResourceSchema,
+		Raw:    tftypes.NewValue(req.ResourceSchema.Type().TerraformType(ctx), nil),
+	}
+	result.Diagnostics.Append(state.SetAttribute(ctx, path.Root("id"), result.DisplayName)...)
+	result.Diagnostics.Append(state.SetAttribute(ctx, path.Root("organization"), object.GetNamespace())...)
+	result.Diagnostics.Append(state.SetAttribute(ctx, path.Root("name"), object.GetName())...)
+	if result.Diagnostics.HasError() {
+		return result, true
+	}
+	resp := resource.ReadResponse{
+		State:    state,
+		Identity: result.Identity,
+	}
+	res.Read(ctx, resource.ReadRequest{State: state}, &resp)
+	result.Diagnostics.Append(resp.Diagnostics...)
+	if resp.Diagnostics.HasError() {
+		return result, true
+	}
+	if resp.State.Raw.IsNull() {
+		return result, false
+	}
+	result.Resource.Raw = resp.State.Raw
+	return result, true
+}
+
+func (r *cloudListResource) objectInstanceName(object *unstructured.Unstructured) string {
+	if r.instanceName == nil {
+		return ""
+	}
+	instanceName, _, _ := unstructured.NestedString(object.Object, r.instanceName...)
+	return instanceName
+}
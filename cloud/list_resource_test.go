@@ -0,0 +1,168 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// nullObjectValue returns the object of the type whose attributes are null except the given attributes
+func nullObjectValue(objectType tftypes.Type, attributes map[string]tftypes.Value) tftypes.Value {
+	values := map[string]tftypes.Value{}
+	for name, attributeType := range objectType.(tftypes.Object).AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+	}
+	for name, value := range attributes {
+		values[name] = value
+	}
+	return tftypes.NewValue(objectType, values)
+}
+
+func nullObjectDynamicValue(
+	t *testing.T, objectType tftypes.Type, attributes map[string]tftypes.Value) *tfprotov5.DynamicValue {
+	dynamicValue, err := tfprotov5.NewDynamicValue(objectType, nullObjectValue(objectType, attributes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dynamicValue
+}
+
+func TestListResource(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeAPIServer(0)
+	t.Cleanup(server.Close)
+	server.setTestEnv(t)
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{})
+	meta, diags := providerConfigure(ctx, d, "")
+	assert.False(t, diags.HasError(), diags)
+	clientSet, err := getClientSet(getFactoryFromMeta(meta))
+	assert.NoError(t, err)
+	for _, volume := range []struct {
+		organization, name, env string
+	}{
+		{"sndev", "volume-a", "prod"},
+		{"sndev", "volume-b", "dev"},
+		{"sndev", "volume-c", "prod"},
+		{"sndev", "volume-d", "prod"},
+		{"other", "volume-e", "prod"},
+	} {
+		_, err = clientSet.CloudV1alpha1().Volumes(volume.organization).Create(ctx, &v1alpha1.Volume{
+			ObjectMeta: metav1.ObjectMeta{Name: volume.name, Labels: map[string]string{"env": volume.env}},
+			Spec: v1alpha1.VolumeSpec{
+				Bucket: "bucket-" + volume.name,
+				Path:   "path",
+				Region: "us-east-1",
+				Type:   "aws",
+				AWS:    &v1alpha1.AWSSpec{Region: "us-east-1", RoleArn: "arn:aws:iam::123456789012:role/volume"},
+			},
+		}, metav1.CreateOptions{})
+		assert.NoError(t, err)
+	}
+
+	providerServer, err := NewProviderServer(ctx, Provider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas, err := providerServer().GetProviderSchema(ctx, &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configured, err := providerServer().ConfigureProvider(ctx, &tfprotov5.ConfigureProviderRequest{
+		Config: nullObjectDynamicValue(t, schemas.Provider.ValueType(), nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, configured.Diagnostics)
+
+	listVolumes := func(labelSelector string, includeResource bool, limit int64) []tfprotov5.ListResourceResult {
+		config := map[string]tftypes.Value{"organization": tftypes.NewValue(tftypes.String, "sndev")}
+		if labelSelector != "" {
+			config["label_selector"] = tftypes.NewValue(tftypes.String, labelSelector)
+		}
+		stream, err := providerServer().ListResource(ctx, &tfprotov5.ListResourceRequest{
+			TypeName:        "streamnative_volume",
+			Config:          nullObjectDynamicValue(t, schemas.ListResourceSchemas["streamnative_volume"].ValueType(), config),
+			IncludeResource: includeResource,
+			Limit:           limit,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var results []tfprotov5.ListResourceResult
+		for result := range stream.Results {
+			assert.Empty(t, result.Diagnostics)
+			results = append(results, result)
+		}
+		return results
+	}
+	displayNames := func(results []tfprotov5.ListResourceResult) []string {
+		var names []string
+		for _, result := range results {
+			names = append(names, result.DisplayName)
+		}
+		return names
+	}
+
+	// The objects of the other organizations are never listed, the objects are listed page by page
+	defer func(pageSize int64) { listPageSize = pageSize }(listPageSize)
+	listPageSize = 2
+	server.mu.Lock()
+	server.listRequests = 0
+	server.mu.Unlock()
+	results := listVolumes("", false, 0)
+	assert.Equal(t, []string{"sndev/volume-a", "sndev/volume-b", "sndev/volume-c", "sndev/volume-d"},
+		displayNames(results))
+	server.mu.Lock()
+	assert.Equal(t, 2, server.listRequests)
+	server.mu.Unlock()
+	for _, result := range results {
+		assert.Nil(t, result.Resource)
+		assert.NotNil(t, result.Identity)
+	}
+
+	assert.Equal(t, []string{"sndev/volume-a", "sndev/volume-c", "sndev/volume-d"},
+		displayNames(listVolumes("env=prod", false, 0)))
+	assert.Empty(t, listVolumes("env=test", false, 0))
+
+	// The next page isn't listed once the limit is reached
+	server.mu.Lock()
+	server.listRequests = 0
+	server.mu.Unlock()
+	assert.Equal(t, []string{"sndev/volume-a"}, displayNames(listVolumes("", false, 1)))
+	server.mu.Lock()
+	assert.Equal(t, 1, server.listRequests)
+	server.mu.Unlock()
+
+	// The resource is read by the read function of the resource when it's included
+	results = listVolumes("env=dev", true, 0)
+	if assert.Len(t, results, 1) && assert.NotNil(t, results[0].Resource) {
+		value, err := results[0].Resource.Unmarshal(schemas.ResourceSchemas["streamnative_volume"].ValueType())
+		assert.NoError(t, err)
+		attributes := map[string]tftypes.Value{}
+		assert.NoError(t, value.As(&attributes))
+		var bucket string
+		assert.NoError(t, attributes["bucket"].As(&bucket))
+		assert.Equal(t, "bucket-volume-b", bucket)
+	}
+}
@@ -16,24 +16,25 @@ package cloud
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/99designs/keyring"
+	"github.com/google/uuid"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/streamnative/cloud-cli/pkg/auth"
 	"github.com/streamnative/cloud-cli/pkg/auth/store"
-	"github.com/streamnative/cloud-cli/pkg/cmd"
 	"github.com/streamnative/cloud-cli/pkg/config"
-	"github.com/streamnative/cloud-cli/pkg/plugin"
-	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"golang.org/x/oauth2"
 	"k8s.io/client-go/rest"
-	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/flowcontrol"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
 	"k8s.io/utils/clock"
 )
@@ -44,20 +45,84 @@ const (
 	GlobalDefaultAPIServer                = "https://api.streamnative.cloud"
 	GlobalDefaultCertificateAuthorityData = ``
 	ServiceAccountAdminAnnotation         = "annotations.cloud.streamnative.io/service-account-role"
+	ServiceAccountKeyRotatedAtAnnotation  = "annotations.cloud.streamnative.io/key-rotated-at"
+	PulsarClusterRestartedAtAnnotation    = "annotations.cloud.streamnative.io/restarted-at"
 	ServiceName                           = "StreamNative"
 	KeychainName                          = "terraform"
+	CorrelationIDHeader                   = "X-Correlation-ID"
 )
 
+// ProviderVersion is the version of the provider reported in the User-Agent of the API calls
+var ProviderVersion = "dev"
+
+// environmentEndpoints is the set of endpoints used to talk to a StreamNative Cloud environment
+type environmentEndpoints struct {
+	APIServer string
+	Issuer    string
+	Audience  string
+}
+
+// environments are the presets which can be selected by the provider 'environment' attribute
+var environments = map[string]environmentEndpoints{
+	"production": {
+		APIServer: GlobalDefaultAPIServer,
+		Issuer:    GlobalDefaultIssuer,
+		Audience:  GlobalDefaultAudience,
+	},
+	"test": {
+		APIServer: "https://api.test.cloud.gcp.streamnative.dev",
+		Issuer:    "https://auth.test.cloud.gcp.streamnative.dev/",
+		Audience:  "https://api.test.cloud.gcp.streamnative.dev",
+	},
+}
+
 var descriptions map[string]string
 
 func init() {
 	descriptions = map[string]string{
 		"key_file_path": "The path of the private key file, you can set it to 'KEY_FILE_PATH' " +
 			"environment variable, find it in the cloud console under the service account with admin permission",
+		"key_file_data": "The content of the private key file in JSON format, you can set it to 'KEY_FILE_DATA' " +
+			"environment variable, use it instead of 'key_file_path' to avoid writing the key file to disk",
+		"access_token": "A StreamNative Cloud access token minted outside of the provider, you can set it to " +
+			"'STREAMNATIVE_ACCESS_TOKEN' environment variable, the other credentials are ignored when it is set",
 		"client_id": "Client ID of the service account, " +
 			"you can set it to 'GLOBAL_DEFAULT_CLIENT_ID' environment variable",
 		"client_secret": "Client Secret of the service account, " +
 			"you can set it to 'GLOBAL_DEFAULT_CLIENT_SECRET' environment variable",
+		"environment": "The StreamNative Cloud environment preset, one of 'production' and 'test', " +
+			"it sets the default value of 'api_server', 'issuer_url' and 'audience'",
+		"api_server": "The StreamNative Cloud API server url, you can set it to 'GLOBAL_DEFAULT_API_SERVER' " +
+			"environment variable, default is https://api.streamnative.cloud",
+		"issuer_url": "The OAuth2 issuer url, you can set it to 'GLOBAL_DEFAULT_ISSUER' " +
+			"environment variable, default is https://auth.streamnative.cloud/",
+		"audience": "The OAuth2 audience of the API server, you can set it to 'GLOBAL_DEFAULT_AUDIENCE' " +
+			"environment variable, default is https://api.streamnative.cloud",
+		"ca_certificate_data": "The PEM encoded certificate authority data used to verify the API server and the workload identity issuer, " +
+			"you can set it to 'GLOBAL_DEFAULT_CERTIFICATE_AUTHORITY_DATA' environment variable",
+		"config_dir": "The directory to persist the credentials of the provider, " +
+			"by default the credentials are only kept in memory",
+		"keyring_backend": "The keyring backend to persist the credentials in 'config_dir', " +
+			"one of 'file', 'keychain', 'secret-service', 'kwallet', 'pass', 'wincred' and 'keyctl', default 'file'",
+		"keyring_passphrase": "The passphrase to encrypt the credentials with the 'file' keyring backend, " +
+			"you can set it to 'STREAMNATIVE_KEYRING_PASSPHRASE' environment variable",
+		"use_cli_credentials": "Authenticate with the credentials of 'snctl auth login', the endpoints snctl is " +
+			"logged in to are used unless they are configured, " +
+			"the credentials other than 'access_token' are ignored when it is set",
+		"cli_config_dir": "The config directory of snctl, default is ~/.streamnative",
+		"workload_identity": "Authenticate with the JWT issued to the workload by an external identity provider, " +
+			"e.g. GitHub Actions or Kubernetes, the JWT is exchanged for a StreamNative Cloud access token at the issuer",
+		"workload_identity_token_file": "The path of the file containing the workload JWT, it is read again on every exchange",
+		"workload_identity_token_env":  "The name of the environment variable containing the workload JWT",
+		"workload_identity_grant_type": "The grant type used to exchange the workload JWT, " +
+			"'token-exchange'(RFC 8693) or 'jwt-bearer'(RFC 7523), default 'token-exchange'",
+		"workload_identity_client_id": "The client ID sent to the issuer with the workload JWT",
+		"skip_credentials_validation": "Skip the authentication when configuring the provider, " +
+			"the credentials are validated on the first API call instead",
+		"max_retries": "The maximum number of retries of the API calls throttled or failed by the API server, " +
+			"and of the updates conflicting with a concurrent change, default 5",
+		"requests_per_second":          "The maximum number of API calls per second of the provider, default 20",
+		"burst":                        "The maximum burst of API calls of the provider, default 40",
 		"organization":                 "The organization name",
 		"service_account_name":         "The service account name",
 		"service_account_binding_name": "The service account binding name",
@@ -68,6 +133,9 @@ func init() {
 		"secret_name":                  "The secret name",
 		"secret_data":                  "The secret data map",
 		"secret_string_data":           "Write-only string data that will be stored encrypted by the API server",
+		"secret_data_wo":               "The secret data map, it's never saved in the state and requires Terraform 1.11 or later, change 'data_wo_version' to update it",
+		"secret_string_data_wo":        "The string data stored encrypted by the API server, it's never saved in the state and requires Terraform 1.11 or later, change 'data_wo_version' to update it",
+		"secret_data_wo_version":       "The version of 'data_wo' and 'string_data_wo', the write-only values are updated in place when the version changes",
 		"secret_type":                  "The Kubernetes secret type",
 		"availability-mode":            "The availability mode, supporting 'zonal' and 'regional'",
 		"pool_name":                    "The infrastructure pool name",
@@ -132,6 +200,22 @@ func init() {
 		"default_gateway":        "The default gateway of the cloud environment",
 		"apikey_name":            "The name of the api key",
 		"apikey_description":     "The description of the api key",
+		"list_resource":          "Lists the %s resources of an organization, requires Terraform 1.14 or later",
+		"label_selector":         "The label selector to filter the objects, e.g. env=prod,team!=data",
+		"pulsar_cluster_restart": "Restarts the pulsar cluster and waits until it's ready, requires Terraform 1.14 or later",
+		"apikey_revoke":          "Revokes the api key immediately and waits until it's revoked, requires Terraform 1.14 or later",
+		"service_account_rotate_key": "Rotates the private key of the service account and waits until the new key is issued, " +
+			"the new key is read by the next refresh, requires Terraform 1.14 or later",
+		"identity_organization":  "The organization of the resource",
+		"identity_name":          "The name of the resource",
+		"identity_instance_name": "The pulsar instance name of the resource",
+		"apikey_token": "Returns the token of a short-lived api key without saving it in the state, " +
+			"the api key is created on every run with a key which is only kept in memory and it's revoked " +
+			"and deleted once Terraform doesn't need the token anymore, requires Terraform 1.10 or later",
+		"apikey_token_name": "The name of the short-lived api key, it's generated on every run",
+		"apikey_token_expiration_time": "The expiration time of the short-lived api key, you can set it to " +
+			"1m(one minute), 1h(one hour), 1d(one day) or this time format 2025-05-08T15:30:00Z, " +
+			"if you don't set it, it will be set to 1h(one hour) by default",
 		"revoke": "Whether to revoke the api key, if set to true, the api key will be revoked." +
 			" By default, after revoking an apikey object, all connections using that apikey will" +
 			" fail after 1 minute due to an authentication exception." +
@@ -149,6 +233,7 @@ func init() {
 			"if you set it '0', it will never expire, " +
 			"if you don't set it, it will be set to 30d(30 days) by default",
 		"wait_for_completion":     "If true, will block until the status of resource has a Ready condition",
+		"allow_replacement":       "If true, changing the attributes which can't be updated in place replaces the resource instead of failing the plan",
 		"resource_name":           fmt.Sprintf("The name of StreamNative Cloud resource, should be plural format, valid values are %q.", strings.Join(validResourceNames, ", ")),
 		"gateway_name":            "The name of the pulsar gateway",
 		"gateway_access":          "The access type of the pulsar gateway, valid values are 'public' and 'private'",
@@ -218,6 +303,13 @@ func init() {
 		"customized_metadata":           "The custom metadata in the api key token",
 		"enable_iam_account_creation":   "Whether to create an IAM account for the service account binding",
 		"aws_assume_role_arns":          "A list of AWS IAM role ARNs which can be assumed by the AWS IAM role created for the service account binding",
+		"function_compute_units_to_resources": "Converts the compute units per broker or the storage units per bookie of the pulsar cluster " +
+			"to the CPU and memory quantities sent to the API server, one unit is 2 CPU and 8 GiB memory",
+		"function_service_urls": "Formats the service URL of the protocol for the DNS name of a pulsar cluster service endpoint " +
+			"in the same way as the service URLs of the pulsar cluster",
+		"function_parse_cluster_id": "Parses the ID of a pulsar cluster in the format <organization>/<name> " +
+			"and returns the organization and the name",
+		"function_s3table_region": "Extracts the AWS region from the ARN of an S3 table bucket",
 	}
 }
 
@@ -230,6 +322,25 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("KEY_FILE_PATH", nil),
 				Description: descriptions["key_file_path"],
 			},
+			"key_file_data": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Sensitive:     true,
+				DefaultFunc:   schema.EnvDefaultFunc("KEY_FILE_DATA", nil),
+				Description:   descriptions["key_file_data"],
+				ConflictsWith: []string{"key_file_path"},
+				ValidateFunc:  validation.StringIsJSON,
+			},
+			"access_token": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("STREAMNATIVE_ACCESS_TOKEN", nil),
+				Description: descriptions["access_token"],
+				ConflictsWith: []string{
+					"key_file_path", "key_file_data", "client_id", "client_secret", "workload_identity",
+				},
+			},
 			"client_id": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -242,6 +353,125 @@ func Provider() *schema.Provider {
 				DefaultFunc: schema.EnvDefaultFunc("GLOBAL_DEFAULT_CLIENT_SECRET", nil),
 				Description: descriptions["client_secret"],
 			},
+			"environment": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  descriptions["environment"],
+				ValidateFunc: validation.StringInSlice([]string{"production", "test"}, false),
+			},
+			"api_server": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  descriptions["api_server"],
+				ValidateFunc: validation.IsURLWithHTTPS,
+			},
+			"issuer_url": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  descriptions["issuer_url"],
+				ValidateFunc: validation.IsURLWithHTTPS,
+			},
+			"audience": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["audience"],
+			},
+			"ca_certificate_data": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["ca_certificate_data"],
+			},
+			"config_dir": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["config_dir"],
+			},
+			"keyring_backend": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  descriptions["keyring_backend"],
+				RequiredWith: []string{"config_dir"},
+				ValidateFunc: validation.StringInSlice([]string{
+					"file", "keychain", "secret-service", "kwallet", "pass", "wincred", "keyctl",
+				}, false),
+			},
+			"keyring_passphrase": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				DefaultFunc: schema.EnvDefaultFunc("STREAMNATIVE_KEYRING_PASSPHRASE", nil),
+				Description: descriptions["keyring_passphrase"],
+			},
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: descriptions["skip_credentials_validation"],
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				Description:  descriptions["max_retries"],
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"requests_per_second": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      20.0,
+				Description:  descriptions["requests_per_second"],
+				ValidateFunc: validation.FloatAtLeast(0.1),
+			},
+			"burst": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      40,
+				Description:  descriptions["burst"],
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"use_cli_credentials": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: descriptions["use_cli_credentials"],
+			},
+			"cli_config_dir": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: descriptions["cli_config_dir"],
+			},
+			"workload_identity": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: descriptions["workload_identity"],
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"token_file": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: descriptions["workload_identity_token_file"],
+						},
+						"token_env": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: descriptions["workload_identity_token_env"],
+						},
+						"grant_type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "token-exchange",
+							Description:  descriptions["workload_identity_grant_type"],
+							ValidateFunc: validation.StringInSlice([]string{"token-exchange", "jwt-bearer"}, false),
+						},
+						"client_id": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: descriptions["workload_identity_client_id"],
+						},
+					},
+				},
+			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"streamnative_service_account":         resourceServiceAccount(),
@@ -250,12 +480,10 @@ func Provider() *schema.Provider {
 			"streamnative_pulsar_cluster":          resourcePulsarCluster(),
 			"streamnative_cloud_connection":        resourceCloudConnection(),
 			"streamnative_cloud_environment":       resourceCloudEnvironment(),
-			"streamnative_apikey":                  resourceApiKey(),
 			"streamnative_pulsar_gateway":          resourcePulsarGateway(),
 			"streamnative_rolebinding":             resourceRoleBinding(),
 			"streamnative_volume":                  resourceVolume(),
 			"streamnative_catalog":                 resourceCatalog(),
-			"streamnative_secret":                  resourceSecret(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
 			"streamnative_service_account":         dataSourceServiceAccount(),
@@ -275,44 +503,163 @@ func Provider() *schema.Provider {
 			"streamnative_secret":                  dataSourceSecret(),
 		},
 	}
-	provider.ConfigureContextFunc = func(_ context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-		return providerConfigure(d, provider.TerraformVersion)
+	provider.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+		return providerConfigure(ctx, d, provider.TerraformVersion)
 	}
 	return provider
 }
 
-func providerConfigure(d *schema.ResourceData, terraformVersion string) (interface{}, diag.Diagnostics) {
-	_ = terraformVersion
+func providerConfigure(
+	ctx context.Context, d *schema.ResourceData, terraformVersion string) (interface{}, diag.Diagnostics) {
+	if terraformVersion == "" {
+		terraformVersion = "unknown"
+	}
+	userAgent := fmt.Sprintf("terraform-provider-streamnative/%s terraform/%s", ProviderVersion, terraformVersion)
+	// The correlation id is sent with all the API calls of the provider instance,
+	// share it with the StreamNative support to find the requests of an apply
+	correlationID := os.Getenv("STREAMNATIVE_CORRELATION_ID")
+	if correlationID == "" {
+		correlationID = uuid.New().String()
+	}
+	tflog.Info(ctx, "Configured the StreamNative Cloud provider", map[string]interface{}{
+		"correlation_id": correlationID,
+		"user_agent":     userAgent,
+	})
 
-	defaultIssuer := os.Getenv("GLOBAL_DEFAULT_ISSUER")
-	if defaultIssuer == "" {
-		defaultIssuer = GlobalDefaultIssuer
+	endpoints := getEndpoints(d)
+	certificateAuthorityData := d.Get("ca_certificate_data").(string)
+	var snConfig *config.SnConfig
+	if d.Get("use_cli_credentials").(bool) {
+		configDir, err := getCLIConfigDir(d)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		snConfig, err = loadCLIConfig(configDir)
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		endpoints = resolveEndpoints(d, cliEndpoints(snConfig))
+		if certificateAuthorityData == "" {
+			certificateAuthorityData, err = cliCertificateAuthorityData(snConfig)
+			if err != nil {
+				return nil, diag.FromErr(err)
+			}
+		}
+	}
+	if certificateAuthorityData == "" {
+		certificateAuthorityData = os.Getenv("GLOBAL_DEFAULT_CERTIFICATE_AUTHORITY_DATA")
+	}
+	if certificateAuthorityData == "" {
+		certificateAuthorityData = GlobalDefaultCertificateAuthorityData
+	}
+	maxRetries := d.Get("max_retries").(int)
+	getter := &restClientGetter{
+		init: func() (*rest.Config, error) {
+			var tokenSource oauth2.TokenSource
+			var err error
+			if snConfig != nil && d.Get("access_token").(string) == "" {
+				tokenSource, err = newCLITokenSource(d, snConfig, endpoints)
+			} else {
+				tokenSource, err = newTokenSource(d, endpoints, certificateAuthorityData)
+			}
+			if err != nil {
+				return nil, err
+			}
+			qps := float32(d.Get("requests_per_second").(float64))
+			burst := d.Get("burst").(int)
+			restConfig := &rest.Config{
+				Host: endpoints.APIServer,
+				TLSClientConfig: rest.TLSClientConfig{
+					CAData: []byte(certificateAuthorityData),
+				},
+				QPS:   qps,
+				Burst: burst,
+				// The clients built from the copies of the config share the rate limiter, so the limit
+				// applies to all the API calls of the provider instead of each client
+				RateLimiter: flowcontrol.NewTokenBucketRateLimiter(qps, burst),
+				UserAgent:   userAgent,
+			}
+			if wrapTestTransport != nil {
+				restConfig.Wrap(wrapTestTransport)
+			}
+			restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+				return &oauth2.Transport{Source: tokenSource, Base: rt}
+			})
+			restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+				return newHeaderTransport(rt, map[string]string{
+					CorrelationIDHeader: correlationID,
+				})
+			})
+			restConfig.Wrap(newLoggingTransport)
+			restConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+				return newRetryTransport(rt, maxRetries)
+			})
+			return restConfig, nil
+		},
 	}
-	defaultAudience := os.Getenv("GLOBAL_DEFAULT_AUDIENCE")
-	if defaultAudience == "" {
-		defaultAudience = GlobalDefaultAudience
+	// The authentication is deferred to the first API call when the credentials are unknown
+	// at plan time, e.g. they come from another resource, or the validation is skipped
+	if !d.Get("skip_credentials_validation").(bool) && d.GetRawConfig().IsWhollyKnown() {
+		if _, err := getter.ToRESTConfig(); err != nil {
+			return nil, diag.FromErr(err)
+		}
 	}
-	defaultAPIServer := os.Getenv("GLOBAL_DEFAULT_API_SERVER")
-	if defaultAPIServer == "" {
-		defaultAPIServer = GlobalDefaultAPIServer
+	factory := &providerFactory{
+		Factory: cmdutil.NewFactory(getter),
+		backoff: newBackoff(maxRetries),
 	}
+	return factory, nil
+}
+
+// newTokenSource authenticates with the credentials configured in the provider, and returns
+// the source of the access tokens used to call the API server
+func newTokenSource(
+	d *schema.ResourceData, endpoints environmentEndpoints, certificateAuthorityData string) (oauth2.TokenSource, error) {
+	if accessToken := d.Get("access_token").(string); accessToken != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{
+			AccessToken: accessToken,
+			TokenType:   "Bearer",
+		}), nil
+	}
+	defaultIssuer := endpoints.Issuer
+	defaultAudience := endpoints.Audience
 	clientId := d.Get("client_id").(string)
 	clientSecret := d.Get("client_secret").(string)
 	keyFilePath := d.Get("key_file_path").(string)
-	configDir, err := getConfigDir(clientId, clientSecret, keyFilePath)
-	if err != nil {
-		return nil, diag.FromErr(err)
-	}
-
+	keyFileData := d.Get("key_file_data").(string)
+	var err error
 	var keyFile *auth.KeyFile
 	var flow *auth.ClientCredentialsFlow
 	var grant *auth.AuthorizationGrant
 	var issuer auth.Issuer
-	if clientId != "" && clientSecret != "" {
+	var refresher grantRefresher
+	workloadIdentity := d.Get("workload_identity").([]interface{})
+	if len(workloadIdentity) > 0 && workloadIdentity[0] != nil {
+		httpClient, err := newHTTPClient(certificateAuthorityData)
+		if err != nil {
+			return nil, err
+		}
+		exchanger, err := newWorkloadIdentityExchanger(
+			workloadIdentity[0].(map[string]interface{}), defaultIssuer, defaultAudience, httpClient)
+		if err != nil {
+			return nil, err
+		}
+		issuer = exchanger.issuer
+		refresher = exchanger
+		grant, err = exchanger.Refresh(nil)
+		if err != nil {
+			return nil, err
+		}
+	} else if (clientId != "" && clientSecret != "") || keyFileData != "" {
 		keyFile = &auth.KeyFile{
 			ClientID:     clientId,
 			ClientSecret: clientSecret,
 		}
+		if clientId == "" || clientSecret == "" {
+			if keyFile, err = parseKeyFileData(keyFileData); err != nil {
+				return nil, err
+			}
+		}
 		issuer = auth.Issuer{
 			IssuerEndpoint: defaultIssuer,
 			ClientID:       keyFile.ClientID,
@@ -323,19 +670,19 @@ func providerConfigure(d *schema.ResourceData, terraformVersion string) (interfa
 			ClientCredentials: keyFile,
 		}
 
-		refresher, err := auth.NewDefaultClientCredentialsGrantRefresher(issuer, clock.RealClock{})
+		refresher, err = auth.NewDefaultClientCredentialsGrantRefresher(issuer, clock.RealClock{})
 		if err != nil {
-			return nil, diag.FromErr(err)
+			return nil, err
 		}
 		grant, err = refresher.Refresh(authorizationGrant)
 		if err != nil {
-			return nil, diag.FromErr(err)
+			return nil, err
 		}
 	} else {
 		credsProvider := auth.NewClientCredentialsProviderFromKeyFile(keyFilePath)
 		keyFile, err = credsProvider.GetClientCredentials()
 		if err != nil {
-			return nil, diag.FromErr(err)
+			return nil, err
 		}
 		issuer = auth.Issuer{
 			IssuerEndpoint: defaultIssuer,
@@ -344,110 +691,116 @@ func providerConfigure(d *schema.ResourceData, terraformVersion string) (interfa
 		}
 		flow, err = auth.NewDefaultClientCredentialsFlow(issuer, keyFilePath)
 		if err != nil {
-			return nil, diag.FromErr(err)
+			return nil, err
 		}
 		grant, err = flow.Authorize()
 		if err != nil {
-			return nil, diag.FromErr(err)
-		}
-	}
-	streams := genericclioptions.IOStreams{
-		In:     os.Stdin,
-		Out:    os.Stdout,
-		ErrOut: os.Stderr,
-	}
-	options := cmd.NewOptions(streams)
-	options.ConfigDir = configDir
-	options.ConfigPath = filepath.Join(configDir, "config")
-	options.BackendOverride = "file"
-	snConfig := &config.SnConfig{
-		Server:                   defaultAPIServer,
-		CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte(GlobalDefaultCertificateAuthorityData)),
-		Auth: config.Auth{
-			IssuerEndpoint: defaultIssuer,
-			Audience:       defaultAudience,
-			ClientID:       keyFile.ClientID,
-		},
-	}
-	err = options.SaveConfig(snConfig)
-	if err != nil {
-		return nil, diag.FromErr(err)
-	}
-	apc := &clientcmdapi.AuthProviderConfig{
-		Name: "streamnative",
-	}
-	// Pre-check if the auth provider is already exist for avoid issue
-	// auth Provider Plugin streamnative was registered twice
-	provider, _ := rest.GetAuthProvider("", apc, nil)
-	if provider == nil {
-		err = options.Complete()
-		if err != nil {
-			return nil, diag.FromErr(err)
-		}
-	} else {
-		kr, err := makeKeyring(options.BackendOverride, options.ConfigDir)
-		if err != nil {
-			return nil, diag.FromErr(err)
-		}
-		options.Store, err = store.NewKeyringStore(kr)
-		if err != nil {
-			return nil, diag.FromErr(err)
+			return nil, err
 		}
-		options.Factory, err = plugin.NewDefaultFactory(options.Store, func() (auth.Issuer, error) {
-			return issuer, nil
-		})
-		if err != nil {
-			return nil, diag.FromErr(err)
+		// The key file is kept in the grant so it can be refreshed without reading the file again
+		if grant.ClientCredentials == nil {
+			grant.ClientCredentials = keyFile
 		}
-		err = options.ServerOptions.Complete(options)
+		refresher, err = auth.NewDefaultClientCredentialsGrantRefresher(issuer, clock.RealClock{})
 		if err != nil {
-			return nil, diag.FromErr(err)
+			return nil, err
 		}
 	}
-	err = options.Store.SaveGrant(issuer.Audience, *grant)
+	grantStore, err := makeGrantStore(d)
 	if err != nil {
-		return nil, diag.FromErr(err)
+		return nil, err
 	}
-	factory := cmdutil.NewFactory(options)
-	return factory, nil
+	err = grantStore.SaveGrant(issuer.Audience, *grant)
+	if err != nil {
+		return nil, err
+	}
+	return &grantTokenSource{
+		audience:  issuer.Audience,
+		store:     grantStore,
+		refresher: refresher,
+	}, nil
 }
 
-func makeKeyring(backendOverride string, configDir string) (keyring.Keyring, error) {
-	var backends []keyring.BackendType
-	if backendOverride != "" {
-		backends = append(backends, keyring.BackendType(backendOverride))
+// parseKeyFileData parses the content of a service account key file
+func parseKeyFileData(keyFileData string) (*auth.KeyFile, error) {
+	keyFile := &auth.KeyFile{}
+	if err := json.Unmarshal([]byte(keyFileData), keyFile); err != nil {
+		return nil, fmt.Errorf("failed to parse the key file data: %v", err)
 	}
+	if keyFile.ClientID == "" || keyFile.ClientSecret == "" {
+		return nil, fmt.Errorf("failed to parse the key file data: client_id and client_secret are required")
+	}
+	return keyFile, nil
+}
 
-	return keyring.Open(keyring.Config{
-		ServiceName:              ServiceName,
-		KeychainName:             KeychainName,
-		KeychainTrustApplication: true,
-		AllowedBackends:          backends,
-		FileDir:                  filepath.Join(configDir, "credentials"),
-		FilePasswordFunc:         keyringPrompt,
+// getEndpoints resolves the endpoints of the provider, the explicit attributes take precedence
+// over the 'environment' preset, then the GLOBAL_DEFAULT_* environment variables and the production defaults
+func getEndpoints(d *schema.ResourceData) environmentEndpoints {
+	return resolveEndpoints(d, environmentEndpoints{
+		APIServer: os.Getenv("GLOBAL_DEFAULT_API_SERVER"),
+		Issuer:    os.Getenv("GLOBAL_DEFAULT_ISSUER"),
+		Audience:  os.Getenv("GLOBAL_DEFAULT_AUDIENCE"),
 	})
 }
 
-func keyringPrompt(prompt string) (string, error) {
-	return "", nil
+// resolveEndpoints applies the 'environment' preset, the explicit attributes and the production defaults
+// to the base endpoints
+func resolveEndpoints(d *schema.ResourceData, endpoints environmentEndpoints) environmentEndpoints {
+	if preset, ok := environments[d.Get("environment").(string)]; ok {
+		endpoints = preset
+	}
+	if apiServer := d.Get("api_server").(string); apiServer != "" {
+		endpoints.APIServer = apiServer
+	}
+	if issuer := d.Get("issuer_url").(string); issuer != "" {
+		endpoints.Issuer = issuer
+	}
+	if audience := d.Get("audience").(string); audience != "" {
+		endpoints.Audience = audience
+	}
+	if endpoints.APIServer == "" {
+		endpoints.APIServer = GlobalDefaultAPIServer
+	}
+	if endpoints.Issuer == "" {
+		endpoints.Issuer = GlobalDefaultIssuer
+	}
+	if endpoints.Audience == "" {
+		endpoints.Audience = endpoints.APIServer
+	}
+	return endpoints
 }
 
-// getConfigDir generate a unique configuration directory based on the provided arguments
-func getConfigDir(clientId, clientSecret, keyFilePath string) (string, error) {
-	home, err := os.Getwd()
+// makeGrantStore creates the store of the authorization grants, the grants are only kept in memory
+// unless the 'config_dir' is configured
+func makeGrantStore(d *schema.ResourceData) (store.Store, error) {
+	configDir := d.Get("config_dir").(string)
+	if configDir == "" {
+		return store.NewKeyringStore(keyring.NewArrayKeyring(nil))
+	}
+	kr, err := makeKeyring(d.Get("keyring_backend").(string), configDir, d.Get("keyring_passphrase").(string))
 	if err != nil {
-		return "", fmt.Errorf("failed to get current working directory: %v", err)
+		return nil, err
 	}
-	combined := fmt.Sprintf("%s|%s|%s", keyFilePath, clientId, clientSecret)
-	hash := sha256.Sum256([]byte(combined))
-	dirName := fmt.Sprintf(".streamnative_%x", hash[:8])
-
-	configDir := filepath.Join(home, dirName)
+	return store.NewKeyringStore(kr)
+}
 
-	if _, err := os.Stat(configDir); os.IsNotExist(err) {
-		if err = os.MkdirAll(configDir, 0755); err != nil {
-			return "", fmt.Errorf("failed to create config directory: %v", err)
+func makeKeyring(backendOverride string, configDir string, passphrase string) (keyring.Keyring, error) {
+	if backendOverride == "" || backendOverride == string(keyring.FileBackend) {
+		if passphrase == "" {
+			return nil, fmt.Errorf("'keyring_passphrase' is required to persist the credentials with the file keyring")
 		}
+		backendOverride = string(keyring.FileBackend)
+	}
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %v", err)
 	}
-	return configDir, nil
+
+	return keyring.Open(keyring.Config{
+		ServiceName:              ServiceName,
+		KeychainName:             KeychainName,
+		KeychainTrustApplication: true,
+		AllowedBackends:          []keyring.BackendType{keyring.BackendType(backendOverride)},
+		FileDir:                  filepath.Join(configDir, "credentials"),
+		FilePasswordFunc:         keyring.FixedStringPrompt(passphrase),
+	})
 }
@@ -15,22 +15,49 @@
 package cloud
 
 import (
+	"context"
+	"encoding/base64"
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/99designs/keyring"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/streamnative/cloud-cli/pkg/auth"
+	"github.com/streamnative/cloud-cli/pkg/auth/store"
+	"github.com/streamnative/cloud-cli/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+	"sigs.k8s.io/yaml"
 )
 
 var (
-	testAccProvider          *schema.Provider
-	testAccProviderFactories map[string]func() (*schema.Provider, error)
+	testAccProvider                 *schema.Provider
+	testAccProtoV5ProviderFactories map[string]func() (tfprotov5.ProviderServer, error)
 )
 
 func init() {
+	// The fake API server is shared by all the tests of the package, and is closed when the process exits
+	if readyDelay := os.Getenv(fakeAPIServerEnv); readyDelay != "" {
+		delay, err := time.ParseDuration(readyDelay)
+		if err != nil {
+			delay = time.Second
+		}
+		newFakeAPIServer(delay).setEnv()
+	}
 	testAccProvider = Provider()
-	testAccProviderFactories = map[string]func() (*schema.Provider, error){
-		"streamnative": func() (*schema.Provider, error) {
-			return testAccProvider, nil
+	testAccProtoV5ProviderFactories = map[string]func() (tfprotov5.ProviderServer, error){
+		"streamnative": func() (tfprotov5.ProviderServer, error) {
+			server, err := NewProviderServer(context.Background(), testAccProvider)
+			if err != nil {
+				return nil, err
+			}
+			return server(), nil
 		},
 	}
 }
@@ -45,12 +72,314 @@ func TestProvider_impl(t *testing.T) {
 	var _ = Provider()
 }
 
+func TestProviderServer(t *testing.T) {
+	server, err := NewProviderServer(context.Background(), Provider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server().GetProviderSchema(context.Background(), &tfprotov5.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, resp.Diagnostics)
+	assert.Contains(t, resp.ResourceSchemas, "streamnative_apikey")
+	assert.Contains(t, resp.ResourceSchemas, "streamnative_secret")
+	assert.Contains(t, resp.ResourceSchemas, "streamnative_pulsar_cluster")
+	assert.Contains(t, resp.DataSourceSchemas, "streamnative_apikey")
+	assert.Contains(t, resp.EphemeralResourceSchemas, "streamnative_apikey_token")
+	assert.Contains(t, resp.ActionSchemas, "streamnative_pulsar_cluster_restart")
+	assert.Contains(t, resp.ActionSchemas, "streamnative_apikey_revoke")
+	assert.Contains(t, resp.ActionSchemas, "streamnative_service_account_rotate_key")
+	for _, name := range []string{
+		"streamnative_pulsar_cluster", "streamnative_pulsar_instance", "streamnative_pulsar_gateway",
+		"streamnative_service_account", "streamnative_apikey", "streamnative_rolebinding",
+		"streamnative_secret", "streamnative_volume", "streamnative_catalog",
+	} {
+		assert.Contains(t, resp.ListResourceSchemas, name)
+	}
+	// The mux server only serves the provider schema when the schemas of both providers are identical
+	var workloadIdentity *tfprotov5.SchemaNestedBlock
+	for _, block := range resp.Provider.Block.BlockTypes {
+		if block.TypeName == "workload_identity" {
+			workloadIdentity = block
+		}
+	}
+	if assert.NotNil(t, workloadIdentity) {
+		assert.Equal(t, int64(1), workloadIdentity.MaxItems)
+	}
+
+	_, blocks := frameworkProviderSchema(Provider().Schema)
+	assert.Len(t, blocks["workload_identity"].(providerschema.ListNestedBlock).Validators, 1)
+}
+
+func TestProviderEndpoints(t *testing.T) {
+	t.Setenv("GLOBAL_DEFAULT_API_SERVER", "")
+	t.Setenv("GLOBAL_DEFAULT_ISSUER", "")
+	t.Setenv("GLOBAL_DEFAULT_AUDIENCE", "")
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{})
+	assert.Equal(t, environments["production"], getEndpoints(d))
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"environment": "test",
+	})
+	assert.Equal(t, environments["test"], getEndpoints(d))
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"environment": "test",
+		"api_server":  "https://api.example.com",
+		"issuer_url":  "https://auth.example.com/",
+	})
+	assert.Equal(t, environmentEndpoints{
+		APIServer: "https://api.example.com",
+		Issuer:    "https://auth.example.com/",
+		Audience:  environments["test"].Audience,
+	}, getEndpoints(d))
+
+	t.Setenv("GLOBAL_DEFAULT_API_SERVER", "https://api.env.example.com")
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{})
+	assert.Equal(t, environmentEndpoints{
+		APIServer: "https://api.env.example.com",
+		Issuer:    GlobalDefaultIssuer,
+		Audience:  "https://api.env.example.com",
+	}, getEndpoints(d))
+}
+
+func TestMakeGrantStore(t *testing.T) {
+	t.Setenv("STREAMNATIVE_KEYRING_PASSPHRASE", "")
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{})
+	grantStore, err := makeGrantStore(d)
+	assert.NoError(t, err)
+	grant := auth.AuthorizationGrant{
+		Type: auth.GrantTypeClientCredentials,
+		ClientCredentials: &auth.KeyFile{
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+		},
+	}
+	assert.NoError(t, grantStore.SaveGrant(GlobalDefaultAudience, grant))
+	loaded, err := grantStore.LoadGrant(GlobalDefaultAudience)
+	assert.NoError(t, err)
+	assert.Equal(t, "client-id", loaded.ClientCredentials.ClientID)
+
+	configDir := t.TempDir()
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"config_dir": configDir,
+	})
+	_, err = makeGrantStore(d)
+	assert.Error(t, err)
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"config_dir":         configDir,
+		"keyring_passphrase": "passphrase",
+	})
+	grantStore, err = makeGrantStore(d)
+	assert.NoError(t, err)
+	assert.NoError(t, grantStore.SaveGrant(GlobalDefaultAudience, grant))
+	assert.DirExists(t, filepath.Join(configDir, "credentials"))
+}
+
+func TestProviderAccessToken(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"access_token": "external-token",
+	})
+	tokenSource, err := newTokenSource(d, getEndpoints(d), "")
+	assert.NoError(t, err)
+	token, err := tokenSource.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "external-token", token.AccessToken)
+	assert.Equal(t, "Bearer", token.TokenType)
+}
+
+func TestProviderAccessTokenConflicts(t *testing.T) {
+	for _, name := range []string{"key_file_path", "key_file_data", "client_id", "client_secret"} {
+		t.Run(name, func(t *testing.T) {
+			value := "value"
+			if name == "key_file_data" {
+				value = `{"client_id":"id","client_secret":"secret"}`
+			}
+			diags := Provider().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+				"access_token": "external-token",
+				name:           value,
+			}))
+			assert.True(t, diags.HasError())
+			assert.Contains(t, diags[0].Summary, "conflicts with")
+		})
+	}
+	diags := Provider().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"access_token":      "external-token",
+		"workload_identity": []interface{}{map[string]interface{}{"token_env": "TOKEN"}},
+	}))
+	assert.True(t, diags.HasError())
+
+	diags = Provider().Validate(terraform.NewResourceConfigRaw(map[string]interface{}{
+		"access_token": "external-token",
+	}))
+	assert.False(t, diags.HasError(), diags)
+}
+
+func TestProviderKeyFileData(t *testing.T) {
+	keyFile, err := parseKeyFileData(`{"type":"sn_service_account","client_id":"id","client_secret":"secret"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "id", keyFile.ClientID)
+	assert.Equal(t, "secret", keyFile.ClientSecret)
+
+	for _, data := range []string{`{"client_id":`, `[]`, `{"client_id":"id"}`} {
+		_, err = parseKeyFileData(data)
+		assert.ErrorContains(t, err, "failed to parse the key file data", data)
+	}
+	_, errs := Provider().Schema["key_file_data"].ValidateFunc(`{"client_id":`, "key_file_data")
+	assert.NotEmpty(t, errs)
+
+	server := newFakeAPIServer(0)
+	defer server.Close()
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"api_server": server.URL,
+		"issuer_url": server.URL + "/",
+		"key_file_data": fmt.Sprintf(`{"client_id":%q,"client_secret":%q}`,
+			fakeAPIServerClientID, fakeAPIServerClientSecret),
+	})
+	tokenSource, err := newTokenSource(d, getEndpoints(d), "")
+	assert.NoError(t, err)
+	token, err := tokenSource.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, fakeAPIServerAccessToken, token.AccessToken)
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"api_server":    server.URL,
+		"issuer_url":    server.URL + "/",
+		"key_file_data": `{"client_id":`,
+	})
+	_, err = newTokenSource(d, getEndpoints(d), "")
+	assert.ErrorContains(t, err, "failed to parse the key file data")
+}
+
+func TestProviderSkipCredentialsValidation(t *testing.T) {
+	t.Setenv("KEY_FILE_PATH", "")
+	t.Setenv("KEY_FILE_DATA", "")
+	t.Setenv("STREAMNATIVE_ACCESS_TOKEN", "")
+	t.Setenv("GLOBAL_DEFAULT_CLIENT_ID", "")
+	t.Setenv("GLOBAL_DEFAULT_CLIENT_SECRET", "")
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{})
+	_, diags := providerConfigure(context.Background(), d, "")
+	assert.True(t, diags.HasError())
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"skip_credentials_validation": true,
+	})
+	meta, diags := providerConfigure(context.Background(), d, "")
+	assert.False(t, diags.HasError())
+	_, err := getClientSet(getFactoryFromMeta(meta))
+	assert.Error(t, err)
+}
+
 func testAccPreCheck(t *testing.T) {
+	if testAccCassettes != nil {
+		testAccCassettes.start(t)
+	}
 	keyFilePath := os.Getenv("KEY_FILE_PATH")
 	clientId := os.Getenv("GLOBAL_DEFAULT_CLIENT_ID")
 	clientSecret := os.Getenv("GLOBAL_DEFAULT_CLIENT_SECRET")
-	if keyFilePath == "" && clientId == "" && clientSecret == "" {
-		t.Fatal("KEY_FILE_PATH or GLOBAL_DEFAULT_CLIENT_ID," +
+	keyFileData := os.Getenv("KEY_FILE_DATA")
+	accessToken := os.Getenv("STREAMNATIVE_ACCESS_TOKEN")
+	if keyFilePath == "" && keyFileData == "" && accessToken == "" && clientId == "" && clientSecret == "" {
+		t.Fatal("KEY_FILE_PATH, KEY_FILE_DATA, STREAMNATIVE_ACCESS_TOKEN or GLOBAL_DEFAULT_CLIENT_ID," +
 			"GLOBAL_DEFAULT_CLIENT_SECRET must be set for acceptance tests")
 	}
 }
+
+type testGrantRefresher struct {
+	calls  int
+	expiry time.Time
+}
+
+func (r *testGrantRefresher) Refresh(grant *auth.AuthorizationGrant) (*auth.AuthorizationGrant, error) {
+	r.calls++
+	return &auth.AuthorizationGrant{
+		Type:              grant.Type,
+		ClientCredentials: grant.ClientCredentials,
+		Token: &oauth2.Token{
+			AccessToken: "refreshed-token",
+			Expiry:      r.expiry,
+		},
+	}, nil
+}
+
+func TestGrantTokenSourceRefresh(t *testing.T) {
+	grantStore, err := store.NewKeyringStore(keyring.NewArrayKeyring(nil))
+	assert.NoError(t, err)
+	now := time.Now()
+	assert.NoError(t, grantStore.SaveGrant(GlobalDefaultAudience, auth.AuthorizationGrant{
+		Type: auth.GrantTypeClientCredentials,
+		Token: &oauth2.Token{
+			AccessToken: "initial-token",
+			Expiry:      now.Add(time.Hour),
+		},
+	}))
+	refresher := &testGrantRefresher{expiry: now.Add(2 * time.Hour)}
+	tokenSource := &grantTokenSource{
+		audience:  GlobalDefaultAudience,
+		store:     grantStore,
+		refresher: refresher,
+		now:       func() time.Time { return now },
+	}
+
+	token, err := tokenSource.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "initial-token", token.AccessToken)
+	assert.Equal(t, 0, refresher.calls)
+
+	// The token is refreshed before it expires and the refreshed grant is saved
+	now = now.Add(time.Hour - time.Minute)
+	token, err = tokenSource.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "refreshed-token", token.AccessToken)
+	assert.Equal(t, 1, refresher.calls)
+	token, err = tokenSource.Token()
+	assert.NoError(t, err)
+	assert.Equal(t, "refreshed-token", token.AccessToken)
+	assert.Equal(t, 1, refresher.calls)
+}
+
+func TestCLIConfig(t *testing.T) {
+	configDir := t.TempDir()
+	_, err := loadCLIConfig(configDir)
+	assert.ErrorContains(t, err, "snctl auth login")
+
+	data, err := yaml.Marshal(&config.SnConfig{
+		Server:                   "https://api.example.com",
+		CertificateAuthorityData: base64.StdEncoding.EncodeToString([]byte("ca-data")),
+		Auth: config.Auth{
+			IssuerEndpoint: "https://auth.example.com/",
+			Audience:       "https://audience.example.com",
+			ClientID:       "snctl-client",
+		},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), data, 0600))
+	snConfig, err := loadCLIConfig(configDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "snctl-client", snConfig.Auth.ClientID)
+	caData, err := cliCertificateAuthorityData(snConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "ca-data", caData)
+
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"use_cli_credentials": true,
+		"cli_config_dir":      configDir,
+	})
+	dir, err := getCLIConfigDir(d)
+	assert.NoError(t, err)
+	assert.Equal(t, configDir, dir)
+	assert.Equal(t, environmentEndpoints{
+		APIServer: "https://api.example.com",
+		Issuer:    "https://auth.example.com/",
+		Audience:  "https://audience.example.com",
+	}, resolveEndpoints(d, cliEndpoints(snConfig)))
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"use_cli_credentials": true,
+		"cli_config_dir":      configDir,
+		"api_server":          "https://api.override.example.com",
+	})
+	assert.Equal(t, "https://api.override.example.com", resolveEndpoints(d, cliEndpoints(snConfig)).APIServer)
+}
@@ -17,7 +17,6 @@ package cloud
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"strings"
 	"testing"
 	"time"
@@ -28,15 +27,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var clusterGeneratedName = fmt.Sprintf("t-%d-%d", rand.Intn(1000), rand.Intn(100))
-
 func TestPulsarCluster(t *testing.T) {
+	var clusterGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckPulsarClusterDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckPulsarClusterDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourcePulsarCluster(
@@ -54,13 +53,14 @@ func TestPulsarCluster(t *testing.T) {
 }
 
 func TestPulsarClusterNoConfig(t *testing.T) {
-	var clusterGeneratedName = fmt.Sprintf("t-%d-%d", rand.Intn(1000), rand.Intn(100))
+	var clusterGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckPulsarClusterDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckPulsarClusterDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourcePulsarClusterWithoutConfig(
@@ -78,13 +78,14 @@ func TestPulsarClusterNoConfig(t *testing.T) {
 }
 
 func TestPulsarClusterWithMaintenanceWindow(t *testing.T) {
-	var clusterGeneratedName = fmt.Sprintf("t-%d-%d", rand.Intn(1000), rand.Intn(100))
+	var clusterGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckPulsarClusterDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckPulsarClusterDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourcePulsarClusterWithMaintenanceWindow(
@@ -102,13 +103,14 @@ func TestPulsarClusterWithMaintenanceWindow(t *testing.T) {
 }
 
 func TestPulsarClusterAddMaintenanceWindow(t *testing.T) {
-	var clusterGeneratedName = fmt.Sprintf("t-%d-%d", rand.Intn(1000), rand.Intn(100))
+	var clusterGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckPulsarClusterDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckPulsarClusterDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourcePulsarClusterWithoutConfig(
@@ -137,13 +139,14 @@ func TestPulsarClusterAddMaintenanceWindow(t *testing.T) {
 }
 
 func TestPulsarClusterRemoveMaintenanceWindow(t *testing.T) {
-	var clusterGeneratedName = fmt.Sprintf("t-%d-%d", rand.Intn(1000), rand.Intn(100))
+	var clusterGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckPulsarClusterDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckPulsarClusterDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourcePulsarClusterWithMaintenanceWindow(
@@ -172,13 +175,14 @@ func TestPulsarClusterRemoveMaintenanceWindow(t *testing.T) {
 }
 
 func TestPulsarClusterUpdateMaintenanceWindow(t *testing.T) {
-	var clusterGeneratedName = fmt.Sprintf("t-%d-%d", rand.Intn(1000), rand.Intn(100))
+	var clusterGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckPulsarClusterDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckPulsarClusterDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourcePulsarClusterWithMaintenanceWindow(
@@ -207,13 +211,14 @@ func TestPulsarClusterUpdateMaintenanceWindow(t *testing.T) {
 }
 
 func TestPulsarClusterMaintenanceWindowConfigDrift(t *testing.T) {
-	var clusterGeneratedName = fmt.Sprintf("t-%d-%d", rand.Intn(1000), rand.Intn(100))
+	var clusterGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckPulsarClusterDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckPulsarClusterDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourcePulsarClusterWithMaintenanceWindow(
@@ -241,13 +246,14 @@ func TestPulsarClusterMaintenanceWindowConfigDrift(t *testing.T) {
 }
 
 func TestPulsarClusterConfigDrift(t *testing.T) {
-	var clusterGeneratedName = fmt.Sprintf("t-%d-%d", rand.Intn(1000), rand.Intn(100))
+	var clusterGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckPulsarClusterDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckPulsarClusterDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourcePulsarCluster(
@@ -275,13 +281,14 @@ func TestPulsarClusterConfigDrift(t *testing.T) {
 }
 
 func TestPulsarClusterNoConfigConfigDrift(t *testing.T) {
-	var clusterGeneratedName = fmt.Sprintf("t-%d-%d", rand.Intn(1000), rand.Intn(100))
+	var clusterGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckPulsarClusterDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckPulsarClusterDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourcePulsarClusterWithoutConfig(
@@ -32,13 +32,13 @@ func TestPulsarInstance(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckPulsarInstanceDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckPulsarInstanceDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourcePulsarInstance(
 					"sndev",
-					"terraform-test-pulsar-instance-b",
+					"tf-acc-pulsar-instance-b",
 					"zonal",
 					"shared-gcp-prod",
 					"streamnative"),
@@ -19,12 +19,19 @@ import (
 	"encoding/base64"
 	"fmt"
 	"regexp"
-	"strings"
 	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/lestrrat-go/jwx/v2/jwa"
 	"github.com/lestrrat-go/jwx/v2/jwe"
 	"github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
@@ -34,141 +41,193 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func resourceApiKey() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceApiKeyCreate,
-		ReadContext:   resourceApiKeyRead,
-		UpdateContext: resourceApiKeyUpdate,
-		DeleteContext: resourceApiKeyDelete,
-		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, i interface{}) error {
-			oldOrg, _ := diff.GetChange("organization")
-			oldName, _ := diff.GetChange("name")
-			if oldOrg.(string) == "" && oldName.(string) == "" {
-				// This is create event, so we don't need to check the diff.
-				return nil
-			}
-			return nil
-		},
-		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				organizationApiKey := strings.Split(d.Id(), "/")
-				if err := d.Set("organization", organizationApiKey[0]); err != nil {
-					return nil, fmt.Errorf("ERROR_IMPORT_ORGANIZATION: %w", err)
-				}
-				if err := d.Set("name", organizationApiKey[1]); err != nil {
-					return nil, fmt.Errorf("ERROR_IMPORT_NAME: %w", err)
-				}
-				err := resourceApiKeyRead(ctx, d, meta)
-				if err.HasError() {
-					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
-				}
-				return []*schema.ResourceData{d}, nil
+type apiKeyResource struct {
+	factory *providerFactory
+}
+
+type apiKeyResourceModel struct {
+	ID                 types.String `tfsdk:"id"`
+	Organization       types.String `tfsdk:"organization"`
+	Name               types.String `tfsdk:"name"`
+	InstanceName       types.String `tfsdk:"instance_name"`
+	CustomizedMetadata types.Map    `tfsdk:"customized_metadata"`
+	Token              types.String `tfsdk:"token"`
+	ServiceAccountName types.String `tfsdk:"service_account_name"`
+	ExpirationTime     types.String `tfsdk:"expiration_time"`
+	Revoke             types.Bool   `tfsdk:"revoke"`
+	Description        types.String `tfsdk:"description"`
+	Ready              types.String `tfsdk:"ready"`
+	IssuedAt           types.String `tfsdk:"issued_at"`
+	ExpiresAt          types.String `tfsdk:"expires_at"`
+	PrivateKey         types.String `tfsdk:"private_key"`
+	KeyID              types.String `tfsdk:"key_id"`
+	RevokedAt          types.String `tfsdk:"revoked_at"`
+	PrincipalName      types.String `tfsdk:"principal_name"`
+}
+
+var (
+	_ resource.ResourceWithConfigure   = &apiKeyResource{}
+	_ resource.ResourceWithImportState = &apiKeyResource{}
+	_ resource.ResourceWithIdentity    = &apiKeyResource{}
+)
+
+// generateEncryptionKey generates the key which the token of a new api key is encrypted with, it's
+// replaced by the tests to replay the tokens encrypted with a fixed key
+var generateEncryptionKey = util.GenerateEncryptionKey
+
+func NewApiKeyResource() resource.Resource {
+	return &apiKeyResource{}
+}
+
+func (r *apiKeyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_apikey"
+}
+
+func (r *apiKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of this resource.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-		},
-		Schema: map[string]*schema.Schema{
-			"organization": {
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     true,
-				Description:  descriptions["organization"],
-				ValidateFunc: validateNotBlank,
+			"organization": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["organization"],
+				Validators:  []validator.String{notBlankValidator{}},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"name": {
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     true,
-				Description:  descriptions["apikey_name"],
-				ValidateFunc: validateNotBlank,
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["apikey_name"],
+				Validators:  []validator.String{notBlankValidator{}},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"instance_name": {
-				Type:        schema.TypeString,
+			"instance_name": schema.StringAttribute{
 				Required:    true,
-				ForceNew:    true,
 				Description: descriptions["instance_name"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"customized_metadata": {
-				Type:        schema.TypeMap,
+			"customized_metadata": schema.MapAttribute{
+				ElementType: types.StringType,
 				Optional:    true,
 				Description: descriptions["customized_metadata"],
 			},
-			"token": {
-				Type:        schema.TypeString,
+			"token": schema.StringAttribute{
 				Computed:    true,
 				Sensitive:   true,
 				Description: descriptions["token"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"service_account_name": {
-				Type:        schema.TypeString,
+			"service_account_name": schema.StringAttribute{
 				Required:    true,
-				ForceNew:    true,
 				Description: descriptions["service_account_name"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"expiration_time": {
-				Type:        schema.TypeString,
+			// The unset optional attributes default to the zero values saved by the SDKv2 resource,
+			// so the existing state doesn't plan a replacement
+			"expiration_time": schema.StringAttribute{
 				Optional:    true,
-				ForceNew:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
 				Description: descriptions["expiration_time"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"revoke": {
-				Type:        schema.TypeBool,
+			"revoke": schema.BoolAttribute{
 				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
 				Description: descriptions["revoke"],
 			},
-			"description": {
-				Type:        schema.TypeString,
-				Description: descriptions["description"],
+			"description": schema.StringAttribute{
 				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(""),
+				Description: descriptions["description"],
 			},
-			"ready": {
-				Type:        schema.TypeString,
+			"ready": schema.StringAttribute{
 				Computed:    true,
 				Description: descriptions["apikey_ready"],
 			},
-			"issued_at": {
-				Type:        schema.TypeString,
+			"issued_at": schema.StringAttribute{
 				Computed:    true,
 				Description: descriptions["issued_at"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"expires_at": {
-				Type:        schema.TypeString,
+			"expires_at": schema.StringAttribute{
 				Computed:    true,
 				Description: descriptions["expires_at"],
 			},
-			"private_key": {
-				Type:        schema.TypeString,
+			"private_key": schema.StringAttribute{
 				Computed:    true,
 				Sensitive:   true,
 				Description: descriptions["private_key"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"key_id": {
-				Type:        schema.TypeString,
+			"key_id": schema.StringAttribute{
 				Computed:    true,
 				Description: descriptions["key_id"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"revoked_at": {
-				Type:        schema.TypeString,
+			"revoked_at": schema.StringAttribute{
 				Computed:    true,
 				Description: descriptions["revoked_at"],
 			},
-			"principal_name": {
-				Type:        schema.TypeString,
+			"principal_name": schema.StringAttribute{
 				Computed:    true,
 				Description: descriptions["principal_name"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
 }
 
-func resourceApiKeyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	instanceName := d.Get("instance_name").(string)
-	serviceAccountName := d.Get("service_account_name").(string)
-	description := d.Get("description").(string)
-	expirationTime := d.Get("expiration_time").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(m))
+func (r *apiKeyResource) IdentitySchema(
+	_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = instanceResourceIdentitySchema()
+}
+
+func (r *apiKeyResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	r.factory = getFactoryFromMeta(req.ProviderData)
+}
+
+func (r *apiKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan apiKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	namespace := plan.Organization.ValueString()
+	name := plan.Name.ValueString()
+	clientSet, err := getClientSet(r.factory)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_CREATE_API_KEY: %w", err))
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_CREATE_API_KEY", err.Error())
+		return
 	}
 	ak := &v1alpha1.APIKey{
 		TypeMeta: metav1.TypeMeta{
@@ -180,225 +239,277 @@ func resourceApiKeyCreate(ctx context.Context, d *schema.ResourceData, m interfa
 			Namespace: namespace,
 		},
 		Spec: v1alpha1.APIKeySpec{
-			InstanceName:       instanceName,
-			ServiceAccountName: serviceAccountName,
+			InstanceName:       plan.InstanceName.ValueString(),
+			ServiceAccountName: plan.ServiceAccountName.ValueString(),
+			Description:        plan.Description.ValueString(),
+			Revoke:             plan.Revoke.ValueBool(),
 		},
 	}
-	r1 := regexp.MustCompile(`^(\d+.)(s|m|h|d)$`)
-	t := time.Now()
-	if expirationTime != "" {
-		if r1.MatchString(expirationTime) {
-			ago, err := str2duration.ParseDuration(expirationTime)
-			if err != nil {
-				return diag.FromErr(fmt.Errorf("ERROR_PARSE_EXPIRATION_TIME: %w", err))
-			}
-			t = t.Add(ago)
-		} else if expirationTime != "0" {
-			layout := "2006-01-02T15:04:05Z"
-			t, err = time.Parse(layout, expirationTime)
-			if err != nil {
-				return diag.FromErr(fmt.Errorf("ERROR_PARSE_EXPIRATION_TIME: %w", err))
-			}
-		}
-	} else {
-		defaultExpireTime, err := time.ParseDuration("720h")
-		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_PARSE_DEFAULT_EXPIRATION_TIME: %w", err))
-		}
-		t = t.Add(defaultExpireTime)
-	}
-	if expirationTime != "0" {
-		ak.Spec.ExpirationTime = &metav1.Time{Time: t}
-	}
-	if description != "" {
-		ak.Spec.Description = description
+	ak.Spec.ExpirationTime, err = parseApiKeyExpirationTime(plan.ExpirationTime.ValueString(), time.Now())
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_PARSE_EXPIRATION_TIME", err.Error())
+		return
 	}
-	privateKey, err := util.GenerateEncryptionKey()
+	privateKey, err := generateEncryptionKey()
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_GENERATE_RSA_PRIVATE_KEY: %w", err))
+		resp.Diagnostics.AddError("ERROR_GENERATE_RSA_PRIVATE_KEY", err.Error())
+		return
 	}
 	encryptionKey, err := util.ExportPublicKey(privateKey)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_EXPORT_PUBLIC_KEY: %w", err))
+		resp.Diagnostics.AddError("ERROR_EXPORT_PUBLIC_KEY", err.Error())
+		return
 	}
 	ak.Spec.EncryptionKey = &v1alpha1.EncryptionKey{
 		PEM: encryptionKey.PEM,
 	}
-	revoke := d.Get("revoke").(bool)
-	ak.Spec.Revoke = revoke
-	metadata := d.Get("customized_metadata").(map[string]interface{})
-	if metadata != nil && len(metadata) > 0 {
-		customizedMetadata := map[string]string{}
-		for k, v := range metadata {
-			customizedMetadata[k] = v.(string)
-		}
+	customizedMetadata, diags := stringMapFromValue(ctx, plan.CustomizedMetadata)
+	resp.Diagnostics.Append(diags...)
+	if len(customizedMetadata) > 0 {
 		ak.Spec.CustomizedMetadata = customizedMetadata
 	}
 	_, err = clientSet.CloudV1alpha1().APIKeys(namespace).Create(ctx, ak, metav1.CreateOptions{
 		FieldManager: "terraform-create",
 	})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_API_KEY: %w", err))
+		resp.Diagnostics.AddError("ERROR_CREATE_API_KEY", err.Error())
+		return
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s", namespace, name))
-	if err = d.Set(
-		"private_key", base64.StdEncoding.EncodeToString([]byte(util.ExportPrivateKey(privateKey)))); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_PRIVATE_KEY: %w", err))
-	}
+	plan.ID = types.StringValue(fmt.Sprintf("%s/%s", namespace, name))
+	plan.PrivateKey = types.StringValue(base64.StdEncoding.EncodeToString([]byte(util.ExportPrivateKey(privateKey))))
+	plan.Token = types.StringNull()
+	plan.IssuedAt = types.StringNull()
+	plan.ExpiresAt = types.StringNull()
+	plan.KeyID = types.StringNull()
+	plan.RevokedAt = types.StringNull()
 	err = retry.RetryContext(ctx, 10*time.Minute, func() *retry.RetryError {
-		dia := resourceApiKeyRead(ctx, d, m)
-		if dia.HasError() {
-			return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_CREATE_API_KEY: %s", dia[0].Summary))
+		apiKey, err := clientSet.CloudV1alpha1().APIKeys(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return retry.NonRetryableError(fmt.Errorf("ERROR_READ_API_KEY: %w", err))
+		}
+		if err = setApiKeyState(apiKey, &plan); err != nil {
+			return retry.NonRetryableError(err)
 		}
-		ready := d.Get("ready")
-		if ready == "False" {
+		if plan.Ready.ValueString() == "False" {
 			return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_CREATE_API_KEY"))
 		}
 		return nil
 	})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_CREATE_API_KEY: %w", err))
+		resp.Diagnostics.AddError("ERROR_RETRY_CREATE_API_KEY", err.Error())
+		return
 	}
-	return nil
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(setInstanceResourceIdentity(
+		ctx, resp.Identity, plan.Organization, plan.Name, plan.InstanceName)...)
 }
 
-func resourceApiKeyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	clientSet, err := getClientSet(getFactoryFromMeta(m))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_DELETE_API_KEY: %w", err))
+func (r *apiKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state apiKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	_, err = clientSet.CloudV1alpha1().APIKeys(namespace).Get(ctx, name, metav1.GetOptions{})
+	clientSet, err := getClientSet(r.factory)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_READ_API_KEY: %w", err))
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_READ_API_KEY", err.Error())
+		return
 	}
-	err = clientSet.CloudV1alpha1().APIKeys(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	apiKey, err := clientSet.CloudV1alpha1().APIKeys(state.Organization.ValueString()).Get(
+		ctx, state.Name.ValueString(), metav1.GetOptions{})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_DELETE_API_KEY: %w", err))
+		if apierrors.IsNotFound(err) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError("ERROR_READ_API_KEY", err.Error())
+		return
 	}
-	_ = d.Set("name", "")
-	d.SetId(fmt.Sprintf("%s/%s", namespace, name))
-	return nil
+	if err = setApiKeyState(apiKey, &state); err != nil {
+		resp.Diagnostics.AddError("ERROR_READ_API_KEY", err.Error())
+		return
+	}
+	if len(apiKey.Spec.CustomizedMetadata) > 0 {
+		var diags diag.Diagnostics
+		state.CustomizedMetadata, diags = types.MapValueFrom(ctx, types.StringType, apiKey.Spec.CustomizedMetadata)
+		resp.Diagnostics.Append(diags...)
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	resp.Diagnostics.Append(setInstanceResourceIdentity(
+		ctx, resp.Identity, state.Organization, state.Name, state.InstanceName)...)
 }
 
-func resourceApiKeyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(m))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_API_KEY: %w", err))
+func (r *apiKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state apiKeyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-	apiKey, err := clientSet.CloudV1alpha1().APIKeys(namespace).Get(ctx, name, metav1.GetOptions{})
+	namespace := plan.Organization.ValueString()
+	name := plan.Name.ValueString()
+	clientSet, err := getClientSet(r.factory)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_READ_API_KEY: %w", err))
-	}
-	revoke := d.Get("revoke").(bool)
-	apiKey.Spec.Revoke = revoke
-	description := d.Get("description").(string)
-	if description != "" {
-		apiKey.Spec.Description = description
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_READ_API_KEY", err.Error())
+		return
 	}
-	_, err = clientSet.CloudV1alpha1().APIKeys(namespace).Update(ctx, apiKey, metav1.UpdateOptions{})
+	revoke := plan.Revoke.ValueBool()
+	apiKeys := clientSet.CloudV1alpha1().APIKeys(namespace)
+	_, err = retryUpdateOnConflict(ctx, r.factory, name, apiKeys.Get, apiKeys.Update,
+		func(apiKey *v1alpha1.APIKey) error {
+			apiKey.Spec.Revoke = revoke
+			if description := plan.Description.ValueString(); description != "" {
+				apiKey.Spec.Description = description
+			}
+			return nil
+		}, metav1.UpdateOptions{})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_API_KEY: %w", err))
+		resp.Diagnostics.AddError("ERROR_UPDATE_API_KEY", err.Error())
+		return
 	}
+
+	// The token and private key are kept from the state, they can't be read from the API server
+	plan.Token = state.Token
+	plan.PrivateKey = state.PrivateKey
 	err = retry.RetryContext(ctx, 10*time.Minute, func() *retry.RetryError {
-		dia := resourceApiKeyRead(ctx, d, m)
-		if dia.HasError() {
-			return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_UPDATE_API_KEY: %s", dia[0].Summary))
+		apiKey, err := apiKeys.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return retry.NonRetryableError(fmt.Errorf("ERROR_READ_API_KEY: %w", err))
 		}
-		ready := d.Get("ready")
-		revokedAt := d.Get("revoked_at")
-		if revoke && revokedAt == nil {
+		if err = setApiKeyState(apiKey, &plan); err != nil {
+			return retry.NonRetryableError(err)
+		}
+		if revoke && plan.RevokedAt.ValueString() == "" {
 			return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_UPDATE_API_KEY"))
-		} else if ready == "False" {
+		} else if plan.Ready.ValueString() == "False" {
 			return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_UPDATE_API_KEY"))
 		}
-
 		return nil
 	})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_CREATE_API_KEY: %w", err))
+		resp.Diagnostics.AddError("ERROR_RETRY_UPDATE_API_KEY", err.Error())
+		return
 	}
-	d.SetId(fmt.Sprintf("%s/%s", apiKey.Namespace, apiKey.Name))
-	return nil
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(setInstanceResourceIdentity(
+		ctx, resp.Identity, plan.Organization, plan.Name, plan.InstanceName)...)
 }
 
-func resourceApiKeyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(m))
+func (r *apiKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state apiKeyResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	clientSet, err := getClientSet(r.factory)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_API_KEY: %w", err))
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_DELETE_API_KEY", err.Error())
+		return
 	}
-	apiKey, err := clientSet.CloudV1alpha1().APIKeys(namespace).Get(ctx, name, metav1.GetOptions{})
+	namespace := state.Organization.ValueString()
+	name := state.Name.ValueString()
+	_, err = clientSet.CloudV1alpha1().APIKeys(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			d.SetId("")
-			return nil
-		}
-		return diag.FromErr(fmt.Errorf("ERROR_READ_API_KEY: %w", err))
+		resp.Diagnostics.AddError("ERROR_READ_API_KEY", err.Error())
+		return
+	}
+	err = clientSet.CloudV1alpha1().APIKeys(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil {
+		resp.Diagnostics.AddError("ERROR_DELETE_API_KEY", err.Error())
 	}
-	if err = d.Set("organization", apiKey.Namespace); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_ORGANIZATION: %w", err))
+}
+
+func (r *apiKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importInstanceResourceState(ctx, req, resp, "ERROR_IMPORT_API_KEY")
+	if resp.Diagnostics.HasError() {
+		return
 	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("expiration_time"), "")...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("revoke"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("description"), "")...)
+}
 
-	if apiKey.Spec.CustomizedMetadata != nil && len(apiKey.Spec.CustomizedMetadata) > 0 {
-		if err = d.Set("customized_metadata", apiKey.Spec.CustomizedMetadata); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_CUSTOMIZED_METADATA: %w", err))
+// parseApiKeyExpirationTime parses the 'expiration_time' of the api key, it's either a duration like 1d,
+// a time like 2025-05-08T15:30:00Z or 0 for the api key never expires, the default is 30 days
+func parseApiKeyExpirationTime(expirationTime string, now time.Time) (*metav1.Time, error) {
+	switch {
+	case expirationTime == "0":
+		return nil, nil
+	case expirationTime == "":
+		return &metav1.Time{Time: now.Add(720 * time.Hour)}, nil
+	case regexp.MustCompile(`^(\d+.)(s|m|h|d)$`).MatchString(expirationTime):
+		duration, err := str2duration.ParseDuration(expirationTime)
+		if err != nil {
+			return nil, err
+		}
+		return &metav1.Time{Time: now.Add(duration)}, nil
+	default:
+		t, err := time.Parse("2006-01-02T15:04:05Z", expirationTime)
+		if err != nil {
+			return nil, err
 		}
+		return &metav1.Time{Time: t}, nil
 	}
+}
 
-	if err = d.Set("name", apiKey.Name); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_NAME: %w", err))
-	}
-	if err = d.Set("ready", "False"); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_READY: %w", err))
-	}
+// setApiKeyState sets the status of the api key to the model, the token is decrypted with the private key
+// of the model once the api key is issued
+func setApiKeyState(apiKey *v1alpha1.APIKey, model *apiKeyResourceModel) error {
+	model.ID = types.StringValue(fmt.Sprintf("%s/%s", apiKey.Namespace, apiKey.Name))
+	model.Organization = types.StringValue(apiKey.Namespace)
+	model.Name = types.StringValue(apiKey.Name)
+	model.InstanceName = types.StringValue(apiKey.Spec.InstanceName)
+	model.ServiceAccountName = types.StringValue(apiKey.Spec.ServiceAccountName)
+	model.Ready = types.StringValue("False")
 	if len(apiKey.Status.Conditions) == 3 {
 		for _, condition := range apiKey.Status.Conditions {
 			if condition.Type == "Issued" && condition.Status == "True" {
-				if err = d.Set("issued_at", apiKey.Status.IssuedAt.String()); err != nil {
-					return diag.FromErr(fmt.Errorf("ERROR_SET_ISSUED_AT: %w", err))
-				}
-				if err = d.Set("expires_at", apiKey.Status.ExpiresAt.String()); err != nil {
-					return diag.FromErr(fmt.Errorf("ERROR_SET_EXPIRES_AT: %w", err))
-				}
-				if err = d.Set("key_id", apiKey.Status.KeyId); err != nil {
-					return diag.FromErr(fmt.Errorf("ERROR_SET_KEY_ID: %w", err))
-				}
-				if err = d.Set("ready", "True"); err != nil {
-					return diag.FromErr(fmt.Errorf("ERROR_SET_READY: %w", err))
-				}
+				model.IssuedAt = types.StringValue(apiKey.Status.IssuedAt.String())
+				model.ExpiresAt = types.StringValue(apiKey.Status.ExpiresAt.String())
+				model.KeyID = types.StringValue(apiKey.Status.KeyId)
+				model.Ready = types.StringValue("True")
 
-				privateKey := d.Get("private_key")
-				if apiKey.Status.EncryptedToken.JWE != nil && privateKey != nil {
-					data, err := base64.StdEncoding.DecodeString(d.Get("private_key").(string))
+				if apiKey.Status.EncryptedToken.JWE != nil && model.PrivateKey.ValueString() != "" {
+					data, err := base64.StdEncoding.DecodeString(model.PrivateKey.ValueString())
 					if err != nil {
-						return diag.FromErr(fmt.Errorf("ERROR_DECODE_PRIVATE_KEY: %w", err))
+						return fmt.Errorf("ERROR_DECODE_PRIVATE_KEY: %w", err)
 					}
 					privateKey, err := util.ImportPrivateKey(string(data))
 					if err != nil {
-						return diag.FromErr(fmt.Errorf("ERROR_IMPORT_PRIVATE_KEY: %w", err))
+						return fmt.Errorf("ERROR_IMPORT_PRIVATE_KEY: %w", err)
 					}
 					token, err := jwe.Decrypt([]byte(*apiKey.Status.EncryptedToken.JWE), jwe.WithKey(jwa.RSA_OAEP, privateKey))
 					if err != nil {
-						return diag.FromErr(fmt.Errorf("ERROR_DECRYPT_API_KEY: %w", err))
-					}
-					if err = d.Set("token", string(token)); err != nil {
-						return diag.FromErr(fmt.Errorf("ERROR_SET_TOKEN: %w", err))
+						return fmt.Errorf("ERROR_DECRYPT_API_KEY: %w", err)
 					}
+					model.Token = types.StringValue(string(token))
 				}
 			}
 		}
 		if apiKey.Status.RevokedAt != nil {
-			if err = d.Set("revoked_at", apiKey.Status.RevokedAt.String()); err != nil {
-				return diag.FromErr(fmt.Errorf("ERROR_SET_REVOKED_AT: %w", err))
-			}
+			model.RevokedAt = types.StringValue(apiKey.Status.RevokedAt.String())
 		}
 	}
-	d.SetId(fmt.Sprintf("%s/%s", apiKey.Namespace, apiKey.Name))
-	return setPrincipalName(apiKey, d)
+	if model.IssuedAt.IsUnknown() {
+		model.IssuedAt = types.StringNull()
+	}
+	if model.ExpiresAt.IsUnknown() {
+		model.ExpiresAt = types.StringNull()
+	}
+	if model.KeyID.IsUnknown() {
+		model.KeyID = types.StringNull()
+	}
+	if model.Token.IsUnknown() {
+		model.Token = types.StringNull()
+	}
+	if model.RevokedAt.IsUnknown() {
+		model.RevokedAt = types.StringNull()
+	}
+	principalName, err := apiKeyPrincipalName(apiKey)
+	if err != nil {
+		return err
+	}
+	model.PrincipalName = types.StringValue(principalName)
+	return nil
 }
@@ -18,23 +18,23 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	cloudclient "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset"
 	pulsarv1alpha1 "github.com/streamnative/sn-operator/api/pulsar/v1alpha1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func resourceCatalog() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourceCatalogCreate,
-		ReadContext:   resourceCatalogRead,
-		UpdateContext: resourceCatalogUpdate,
-		DeleteContext: resourceCatalogDelete,
+		CreateContext: withResourceIdentity(catalogEngine.create, organizationNameIdentity),
+		ReadContext:   withResourceIdentity(catalogEngine.read, organizationNameIdentity),
+		UpdateContext: withResourceIdentity(catalogEngine.update, organizationNameIdentity),
+		DeleteContext: catalogEngine.delete,
+		Identity:      newResourceIdentity(organizationNameIdentity),
+		Importer:      resourceImporter(catalogEngine.read, resourceCatalog),
+		Timeouts:      resourceEngineTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"organization": {
 				Type:         schema.TypeString,
@@ -105,9 +105,29 @@ func resourceCatalog() *schema.Resource {
 	}
 }
 
-func resourceCatalogCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
+var catalogEngine = &resourceEngine[*v1alpha1.Catalog]{
+	kind: "CATALOG",
+	client: func(clientSet cloudclient.Interface, namespace string) resourceClient[*v1alpha1.Catalog] {
+		return clientSet.CloudV1alpha1().Catalogs(namespace)
+	},
+	newObject: func() *v1alpha1.Catalog {
+		return &v1alpha1.Catalog{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Catalog",
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			},
+		}
+	},
+	expand:  expandCatalog,
+	flatten: flattenCatalog,
+	ready: func(catalog *v1alpha1.Catalog) bool {
+		return catalogReadyStatus(catalog) == "True"
+	},
+}
+
+// expandCatalog sets the mode and the configuration of the catalog type from the configuration, the
+// configurations of the other catalog types are removed
+func expandCatalog(_ context.Context, d *schema.ResourceData, _ cloudclient.Interface, catalog *v1alpha1.Catalog) error {
 	mode := d.Get("mode").(string)
 
 	// Set default mode if not provided
@@ -117,32 +137,15 @@ func resourceCatalogCreate(ctx context.Context, d *schema.ResourceData, meta int
 
 	// Validate that the mode is supported
 	if err := validateCatalogMode(mode); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_VALIDATE_CATALOG_MODE: %w", err))
+		return fmt.Errorf("ERROR_VALIDATE_CATALOG_MODE: %w", err)
 	}
 
 	// Validate that only one catalog type is configured
 	if err := validateCatalogType(d); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_VALIDATE_CATALOG_TYPE: %w", err))
+		return fmt.Errorf("ERROR_VALIDATE_CATALOG_TYPE: %w", err)
 	}
 
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_CREATE_CATALOG: %w", err))
-	}
-
-	catalog := &v1alpha1.Catalog{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Catalog",
-			APIVersion: v1alpha1.SchemeGroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-		},
-		Spec: v1alpha1.CatalogSpec{
-			Mode: pulsarv1alpha1.TableMode(mode),
-		},
-	}
+	catalog.Spec.Mode = pulsarv1alpha1.TableMode(mode)
 
 	// Set Unity configuration
 	if d.Get("unity_uri").(string) != "" {
@@ -153,6 +156,8 @@ func resourceCatalogCreate(ctx context.Context, d *schema.ResourceData, meta int
 				Secret: d.Get("unity_secret").(string),
 			},
 		}
+	} else {
+		catalog.Spec.Unity = nil
 	}
 
 	// Set OpenCatalog configuration
@@ -164,6 +169,8 @@ func resourceCatalogCreate(ctx context.Context, d *schema.ResourceData, meta int
 				Secret: d.Get("open_catalog_secret").(string),
 			},
 		}
+	} else {
+		catalog.Spec.OpenCatalog = nil
 	}
 
 	// Set S3Table configuration
@@ -171,7 +178,7 @@ func resourceCatalogCreate(ctx context.Context, d *schema.ResourceData, meta int
 		// Generate URI from bucket name
 		uri, err := generateS3TableURI(s3TableBucket)
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_GENERATE_S3_TABLE_URI: %w", err))
+			return fmt.Errorf("ERROR_GENERATE_S3_TABLE_URI: %w", err)
 		}
 
 		catalog.Spec.S3Table = &v1alpha1.Iceberg{
@@ -180,258 +187,73 @@ func resourceCatalogCreate(ctx context.Context, d *schema.ResourceData, meta int
 				URI: uri,
 			},
 		}
+	} else {
+		catalog.Spec.S3Table = nil
 	}
-
-	createdCatalog, err := clientSet.CloudV1alpha1().Catalogs(namespace).Create(ctx, catalog, metav1.CreateOptions{
-		FieldManager: "terraform-create",
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_CATALOG: %w", err))
-	}
-
-	if createdCatalog.Status.Conditions != nil && len(createdCatalog.Status.Conditions) > 0 {
-		ready := false
-		for _, condition := range createdCatalog.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				ready = true
-			}
-		}
-		if ready {
-			_ = d.Set("organization", namespace)
-			_ = d.Set("name", name)
-			return resourceCatalogRead(ctx, d, meta)
-		}
-	}
-
-	d.SetId(fmt.Sprintf("%s/%s", namespace, name))
-	err = retry.RetryContext(ctx, 10*time.Minute, func() *retry.RetryError {
-		dia := resourceCatalogRead(ctx, d, meta)
-		if dia.HasError() {
-			return retry.RetryableError(fmt.Errorf("ERROR_READ_CATALOG: %s", dia[0].Summary))
-		}
-		ready := d.Get("ready").(string)
-		if ready == "False" {
-			return retry.RetryableError(fmt.Errorf("CONTINUE_WAITING_CATALOG_READY: catalog is not ready yet"))
-		}
-		return nil
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_WAIT_CATALOG_READY: %w", err))
-	}
-	return nil
-}
-
-func resourceCatalogDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_DELETE_CATALOG: %w", err))
-	}
-
-	err = clientSet.CloudV1alpha1().Catalogs(namespace).Delete(ctx, name, metav1.DeleteOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_DELETE_CATALOG: %w", err))
-	}
-
-	err = retry.RetryContext(ctx, 10*time.Minute, func() *retry.RetryError {
-		_, err := clientSet.CloudV1alpha1().Catalogs(namespace).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			if apierrors.IsNotFound(err) {
-				return nil
-			}
-			return retry.RetryableError(fmt.Errorf("ERROR_DELETE_CATALOG: %w", err))
-		}
-		return retry.RetryableError(fmt.Errorf("CONTINUE_WAITING_CATALOG_DELETE: %s", "catalog is not deleted yet"))
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_WAIT_CATALOG_DELETE: %w", err))
-	}
-
-	d.SetId("")
 	return nil
 }
 
-func resourceCatalogRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_CATALOG: %w", err))
-	}
-
-	_ = d.Set("ready", "False")
-	catalog, err := clientSet.CloudV1alpha1().Catalogs(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			d.SetId("")
-			return nil
-		}
-		return diag.FromErr(fmt.Errorf("ERROR_READ_CATALOG: %w", err))
-	}
-
-	if err = d.Set("organization", catalog.Namespace); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_ORGANIZATION: %w", err))
-	}
-	if err = d.Set("name", catalog.Name); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_NAME: %w", err))
-	}
-	if err = d.Set("mode", string(catalog.Spec.Mode)); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_MODE: %w", err))
+func flattenCatalog(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, catalog *v1alpha1.Catalog,
+) error {
+	if err := d.Set("mode", string(catalog.Spec.Mode)); err != nil {
+		return fmt.Errorf("ERROR_SET_MODE: %w", err)
 	}
 
 	// Set Unity configuration
 	if catalog.Spec.Unity != nil {
-		if err = d.Set("unity_catalog_name", catalog.Spec.Unity.CatalogName); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_UNITY_CATALOG_NAME: %w", err))
+		if err := d.Set("unity_catalog_name", catalog.Spec.Unity.CatalogName); err != nil {
+			return fmt.Errorf("ERROR_SET_UNITY_CATALOG_NAME: %w", err)
 		}
-		if err = d.Set("unity_uri", catalog.Spec.Unity.URI); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_UNITY_URI: %w", err))
+		if err := d.Set("unity_uri", catalog.Spec.Unity.URI); err != nil {
+			return fmt.Errorf("ERROR_SET_UNITY_URI: %w", err)
 		}
-		if err = d.Set("unity_secret", catalog.Spec.Unity.Secret); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_UNITY_SECRET: %w", err))
+		if err := d.Set("unity_secret", catalog.Spec.Unity.Secret); err != nil {
+			return fmt.Errorf("ERROR_SET_UNITY_SECRET: %w", err)
 		}
 	}
 
 	// Set OpenCatalog configuration
 	if catalog.Spec.OpenCatalog != nil {
-		if err = d.Set("open_catalog_warehouse", catalog.Spec.OpenCatalog.Warehouse); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_OPEN_CATALOG_WAREHOUSE: %w", err))
+		if err := d.Set("open_catalog_warehouse", catalog.Spec.OpenCatalog.Warehouse); err != nil {
+			return fmt.Errorf("ERROR_SET_OPEN_CATALOG_WAREHOUSE: %w", err)
 		}
-		if err = d.Set("open_catalog_uri", catalog.Spec.OpenCatalog.URI); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_OPEN_CATALOG_URI: %w", err))
+		if err := d.Set("open_catalog_uri", catalog.Spec.OpenCatalog.URI); err != nil {
+			return fmt.Errorf("ERROR_SET_OPEN_CATALOG_URI: %w", err)
 		}
-		if err = d.Set("open_catalog_secret", catalog.Spec.OpenCatalog.Secret); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_OPEN_CATALOG_SECRET: %w", err))
+		if err := d.Set("open_catalog_secret", catalog.Spec.OpenCatalog.Secret); err != nil {
+			return fmt.Errorf("ERROR_SET_OPEN_CATALOG_SECRET: %w", err)
 		}
 	}
 
 	// Set S3Table configuration
 	if catalog.Spec.S3Table != nil {
-		if err = d.Set("s3_table_bucket", catalog.Spec.S3Table.Warehouse); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_S3_TABLE_BUCKET: %w", err))
+		if err := d.Set("s3_table_bucket", catalog.Spec.S3Table.Warehouse); err != nil {
+			return fmt.Errorf("ERROR_SET_S3_TABLE_BUCKET: %w", err)
 		}
 
 		// Extract and set region from bucket
 		region, err := extractS3TableRegion(catalog.Spec.S3Table.Warehouse)
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_EXTRACT_S3_TABLE_REGION: %w", err))
+			return fmt.Errorf("ERROR_EXTRACT_S3_TABLE_REGION: %w", err)
 		}
 		if err = d.Set("s3_table_region", region); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_S3_TABLE_REGION: %w", err))
+			return fmt.Errorf("ERROR_SET_S3_TABLE_REGION: %w", err)
 		}
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s", catalog.Namespace, catalog.Name))
-	if catalog.Status.Conditions != nil && len(catalog.Status.Conditions) > 0 {
-		for _, condition := range catalog.Status.Conditions {
-			if condition.Type == "Ready" {
-				_ = d.Set("ready", condition.Status)
-			}
-		}
-	}
-	return nil
+	return d.Set("ready", catalogReadyStatus(catalog))
 }
 
-func resourceCatalogUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	mode := d.Get("mode").(string)
-
-	// Set default mode if not provided
-	if mode == "" {
-		mode = "EXTERNAL"
-	}
-
-	// Validate that the mode is supported
-	if err := validateCatalogMode(mode); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_VALIDATE_CATALOG_MODE: %w", err))
-	}
-
-	// Validate that only one catalog type is configured
-	if err := validateCatalogType(d); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_VALIDATE_CATALOG_TYPE: %w", err))
-	}
-
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_UPDATE_CATALOG: %w", err))
-	}
-
-	catalog, err := clientSet.CloudV1alpha1().Catalogs(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_GET_CATALOG_ON_UPDATE: %w", err))
-	}
-
-	catalog.Spec.Mode = pulsarv1alpha1.TableMode(mode)
-
-	// Update Unity configuration
-	if d.Get("unity_uri").(string) != "" {
-		catalog.Spec.Unity = &v1alpha1.Unity{
-			CatalogName: d.Get("unity_catalog_name").(string),
-			CatalogConnection: v1alpha1.CatalogConnection{
-				URI:    d.Get("unity_uri").(string),
-				Secret: d.Get("unity_secret").(string),
-			},
+// catalogReadyStatus returns the status of the Ready condition of the catalog, False when it has none
+func catalogReadyStatus(catalog *v1alpha1.Catalog) string {
+	status := "False"
+	for _, condition := range catalog.Status.Conditions {
+		if condition.Type == "Ready" {
+			status = string(condition.Status)
 		}
-	} else {
-		catalog.Spec.Unity = nil
 	}
-
-	// Update OpenCatalog configuration
-	if openCatalogWarehouse := d.Get("open_catalog_warehouse").(string); openCatalogWarehouse != "" || d.Get("open_catalog_uri").(string) != "" {
-		catalog.Spec.OpenCatalog = &v1alpha1.Iceberg{
-			Warehouse: d.Get("open_catalog_warehouse").(string),
-			CatalogConnection: v1alpha1.CatalogConnection{
-				URI:    d.Get("open_catalog_uri").(string),
-				Secret: d.Get("open_catalog_secret").(string),
-			},
-		}
-	} else {
-		catalog.Spec.OpenCatalog = nil
-	}
-
-	// Update S3Table configuration
-	if s3TableBucket := d.Get("s3_table_bucket").(string); s3TableBucket != "" {
-		// Generate URI from bucket name
-		uri, err := generateS3TableURI(s3TableBucket)
-		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_GENERATE_S3_TABLE_URI: %w", err))
-		}
-
-		catalog.Spec.S3Table = &v1alpha1.Iceberg{
-			Warehouse: s3TableBucket,
-			CatalogConnection: v1alpha1.CatalogConnection{
-				URI: uri,
-			},
-		}
-	} else {
-		catalog.Spec.S3Table = nil
-	}
-
-	_, err = clientSet.CloudV1alpha1().Catalogs(namespace).Update(ctx, catalog, metav1.UpdateOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_CATALOG: %w", err))
-	}
-
-	err = retry.RetryContext(ctx, 10*time.Minute, func() *retry.RetryError {
-		dia := resourceCatalogRead(ctx, d, meta)
-		if dia.HasError() {
-			return retry.RetryableError(fmt.Errorf("ERROR_READ_CATALOG"))
-		}
-		ready := d.Get("ready").(string)
-		if ready == "False" {
-			return retry.RetryableError(fmt.Errorf(
-				"CONTINUE_WAITING_CATALOG_READY: catalog is not ready yet"))
-		}
-		return nil
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_WAIT_CATALOG_READY: %w", err))
-	}
-
-	d.SetId(fmt.Sprintf("%s/%s", namespace, name))
-	return nil
+	return status
 }
 
 // Helper function to convert map[string]interface{} to map[string]string
@@ -24,19 +24,17 @@ import (
 	cloudclient "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
-	"k8s.io/apimachinery/pkg/api/errors"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func resourceCloudEnvironment() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourceCloudEnvironmentCreate,
-		ReadContext:   resourceCloudEnvironmentRead,
-		UpdateContext: resourceCloudEnvironmentUpdate,
-		DeleteContext: resourceCloudEnvironmentDelete,
+		CreateContext: withResourceIdentity(cloudEnvironmentEngine.create, organizationNameIdentity),
+		ReadContext:   withResourceIdentity(cloudEnvironmentEngine.read, organizationNameIdentity),
+		UpdateContext: withResourceIdentity(cloudEnvironmentEngine.update, organizationNameIdentity),
+		DeleteContext: cloudEnvironmentEngine.delete,
+		Identity:      newResourceIdentity(organizationNameIdentity),
 		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, i interface{}) error {
 			oldOrg, _ := diff.GetChange("organization")
 			if oldOrg.(string) == "" {
@@ -49,33 +47,21 @@ func resourceCloudEnvironment() *schema.Resource {
 			newGateway := convertGateway(new)
 
 			if oldGateway.Access != newGateway.Access {
-				return fmt.Errorf("ERROR_UPDATE_CLOUD_ENVIRONMENT: " +
-					"The cloud environment does not support updating the gateway access, please recreate it")
+				if err := forceNewIfAllowed(diff, fmt.Errorf("ERROR_UPDATE_CLOUD_ENVIRONMENT: "+
+					"The cloud environment does not support updating the gateway access, please recreate it"),
+					"default_gateway"); err != nil {
+					return err
+				}
 			}
 
-			if diff.HasChanges("organization") ||
-				diff.HasChanges("cloud_connection_name") ||
-				diff.HasChanges("region") ||
-				diff.HasChanges("network_id") ||
-				diff.HasChanges("network_cidr") ||
-				diff.HasChanges("network_subnet_cidr") {
-				return fmt.Errorf("ERROR_UPDATE_CLOUD_ENVIRONMENT: " +
-					"The cloud environment does not support updates on the attributes: organization, cloud_connection_name, region, network_id, network_cidr, network_subnet_cidr. Please recreate it")
-			}
-			return nil
-		},
-		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				organizationInstance := strings.Split(d.Id(), "/")
-				_ = d.Set("organization", organizationInstance[0])
-				err := resourceCloudEnvironmentRead(ctx, d, meta)
-				if err.HasError() {
-					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
-				}
-				return []*schema.ResourceData{d}, nil
-			},
+			return forceNewIfAllowed(diff, fmt.Errorf("ERROR_UPDATE_CLOUD_ENVIRONMENT: "+
+				"The cloud environment does not support updates on the attributes: "+
+				"organization, cloud_connection_name, region, network. Please recreate it"),
+				"organization", "cloud_connection_name", "region", "network")
 		},
+		Importer: resourceImporter(resourceCloudEnvironmentImportRead, resourceCloudEnvironment),
 		Schema: map[string]*schema.Schema{
+			"allow_replacement": allowReplacementSchema(),
 			"organization": {
 				Type:         schema.TypeString,
 				Required:     true,
@@ -199,12 +185,45 @@ func resourceCloudEnvironment() *schema.Resource {
 		},
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(120 * time.Minute),
+			Update: schema.DefaultTimeout(120 * time.Minute),
 			Delete: schema.DefaultTimeout(120 * time.Minute),
 		},
 	}
 }
 
-func resourceCloudEnvironmentCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+var cloudEnvironmentEngine = &resourceEngine[*cloudv1alpha1.CloudEnvironment]{
+	kind: "CLOUD_ENVIRONMENT",
+	client: func(clientSet cloudclient.Interface, namespace string) resourceClient[*cloudv1alpha1.CloudEnvironment] {
+		return clientSet.CloudV1alpha1().CloudEnvironments(namespace)
+	},
+	newObject: func() *cloudv1alpha1.CloudEnvironment {
+		return &cloudv1alpha1.CloudEnvironment{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "CloudEnvironment",
+				APIVersion: cloudv1alpha1.SchemeGroupVersion.String(),
+			},
+		}
+	},
+	expand:       expandCloudEnvironment,
+	expandUpdate: expandCloudEnvironmentUpdate,
+	flatten:      flattenCloudEnvironment,
+	ready:        cloudEnvironmentReady,
+	beforeDelete: func(cloudEnvironment *cloudv1alpha1.CloudEnvironment) error {
+		// The cloud environment is protected against the delete until the annotation is lifted
+		if cloudEnvironment.Annotations == nil {
+			cloudEnvironment.Annotations = make(map[string]string)
+		}
+		cloudEnvironment.Annotations["cloud.streamnative.io/destroy-protected"] = "false"
+		return nil
+	},
+	generatedName: true,
+}
+
+// expandCloudEnvironment sets the cloud environment from the configuration, the region and the network
+// are validated against the type of the cloud connection
+func expandCloudEnvironment(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, cloudEnvironment *cloudv1alpha1.CloudEnvironment,
+) error {
 	namespace := d.Get("organization").(string)
 	cloudEnvironmentType := d.Get("environment_type").(string)
 	region := d.Get("region").(string)
@@ -213,16 +232,10 @@ func resourceCloudEnvironmentCreate(ctx context.Context, d *schema.ResourceData,
 	network := d.Get("network").([]interface{})
 	dns := d.Get("dns").([]interface{})
 	rawAnnotations := d.Get("annotations").(map[string]interface{})
-	waitForCompletion := d.Get("wait_for_completion")
-
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_CLOUD_ENVIRONMENT: %w", err))
-	}
 
 	cc, err := clientSet.CloudV1alpha1().CloudConnections(namespace).Get(ctx, cloudConnectionName, metav1.GetOptions{})
 	if err != nil {
-		return diag.FromErr(err)
+		return fmt.Errorf("ERROR_GET_CLOUD_CONNECTION: %w", err)
 	}
 
 	annotations := make(map[string]string)
@@ -233,49 +246,35 @@ func resourceCloudEnvironmentCreate(ctx context.Context, d *schema.ResourceData,
 
 	if cc.Spec.ConnectionType != cloudv1alpha1.ConnectionTypeAzure {
 		if !contains(validRegions, region) {
-			return diag.FromErr(fmt.Errorf("invalid region: %s", region))
+			return fmt.Errorf("invalid region: %s", region)
 		}
 	}
 
-	cloudEnvironment := &cloudv1alpha1.CloudEnvironment{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "CloudEnvironment",
-			APIVersion: cloudv1alpha1.SchemeGroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace:   namespace,
-			Annotations: annotations,
-		},
-		Spec: cloudv1alpha1.CloudEnvironmentSpec{
-			CloudConnectionName: cloudConnectionName,
-			Region:              region,
-			Network:             &cloudv1alpha1.Network{},
-		},
+	cloudEnvironment.Annotations = annotations
+	cloudEnvironment.Spec = cloudv1alpha1.CloudEnvironmentSpec{
+		CloudConnectionName: cloudConnectionName,
+		Region:              region,
+		Network:             &cloudv1alpha1.Network{},
 	}
 	if zone != "" {
 		cloudEnvironment.Spec.Zone = &zone
 	}
 
-	if len(network) > 0 {
-		for _, networkItem := range network {
-			networkItemMap := networkItem.(map[string]interface{})
-			if networkItemMap["id"] != nil {
-				networkId := networkItemMap["id"].(string)
-				cloudEnvironment.Spec.Network.ID = networkId
-			}
-			if networkItemMap["cidr"] != nil {
-				networkCidr := networkItemMap["cidr"].(string)
-				cloudEnvironment.Spec.Network.CIDR = networkCidr
-			}
-			if networkItemMap["subnet_cidr"] != nil {
-				subnetCidr := networkItemMap["subnet_cidr"].(string)
-				cloudEnvironment.Spec.Network.SubnetCIDR = subnetCidr
-			}
+	for _, networkItem := range network {
+		networkItemMap := networkItem.(map[string]interface{})
+		if networkItemMap["id"] != nil {
+			cloudEnvironment.Spec.Network.ID = networkItemMap["id"].(string)
+		}
+		if networkItemMap["cidr"] != nil {
+			cloudEnvironment.Spec.Network.CIDR = networkItemMap["cidr"].(string)
+		}
+		if networkItemMap["subnet_cidr"] != nil {
+			cloudEnvironment.Spec.Network.SubnetCIDR = networkItemMap["subnet_cidr"].(string)
 		}
 	}
 
 	if cloudEnvironment.Spec.Network.ID == "" && cloudEnvironment.Spec.Network.CIDR == "" {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_CLOUD_ENVIRONMENT: " + "One of network.id or network.cidr must be set"))
+		return fmt.Errorf("One of network.id or network.cidr must be set")
 	}
 	if cc.Spec.ConnectionType == cloudv1alpha1.ConnectionTypeAzure {
 		if cloudEnvironment.Spec.Network.CIDR != "" {
@@ -283,274 +282,115 @@ func resourceCloudEnvironmentCreate(ctx context.Context, d *schema.ResourceData,
 				cloudEnvironment.Spec.Network.SubnetCIDR = cloudEnvironment.Spec.Network.CIDR
 			}
 			if validate, _ := validateSubnetCIDR(cloudEnvironment.Spec.Network.SubnetCIDR, cloudEnvironment.Spec.Network.CIDR); !validate {
-				return diag.FromErr(fmt.Errorf("ERROR_CREATE_CLOUD_ENVIRONMENT: " +
-					"Azure cloud environment requires network.subnet_cidr to be a subnet of network.cidr"))
+				return fmt.Errorf("Azure cloud environment requires network.subnet_cidr to be a subnet of network.cidr")
 			}
 		}
 	}
 
-	expandDns := func() error {
-		for _, l := range dns {
-			if l == nil {
-				continue
-			}
-			item := l.(map[string]interface{})
-
-			dnsId := item["id"].(string)
-			dnsName := item["name"].(string)
-
-			if (dnsId != "" && dnsName == "") || (dnsId == "" && dnsName != "") {
-				return fmt.Errorf("ERROR_CREATE_CLOUD_ENVIRONMENT: DNS ID and name must specify together")
-			}
-
-			cloudEnvironment.Spec.DNS = &cloudv1alpha1.DNS{
-				ID:   dnsId,
-				Name: dnsName,
-			}
+	for _, l := range dns {
+		if l == nil {
+			continue
 		}
-		return nil
-	}
-	if err := expandDns(); err != nil {
-		return diag.FromErr(err)
-	}
-
-	cloudEnvironment.Spec.DefaultGateway = convertGateway(d.Get("default_gateway"))
-
-	ce, err := clientSet.CloudV1alpha1().CloudEnvironments(namespace).Create(ctx, cloudEnvironment, metav1.CreateOptions{
-		FieldManager: "terraform-create",
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_CLOUD_ENVIRONMENT: %w", err))
-	}
-
-	ready := false
-	d.SetId(fmt.Sprintf("%s/%s", ce.ObjectMeta.Namespace, ce.ObjectMeta.Name))
-
-	if waitForCompletion == true {
-		err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), retryUntilCloudEnvironmentIsProvisioned(ctx, clientSet, namespace, ce.GetObjectMeta().GetName()))
-		if err != nil {
-			return diag.FromErr(err)
+		item := l.(map[string]interface{})
+		dnsId := item["id"].(string)
+		dnsName := item["name"].(string)
+		if (dnsId != "" && dnsName == "") || (dnsId == "" && dnsName != "") {
+			return fmt.Errorf("DNS ID and name must specify together")
 		}
-	} else {
-		for _, condition := range ce.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				ready = true
-			}
+		cloudEnvironment.Spec.DNS = &cloudv1alpha1.DNS{
+			ID:   dnsId,
+			Name: dnsName,
 		}
 	}
 
-	if ready {
-		_ = d.Set("organization", namespace)
-		return resourceCloudEnvironmentRead(ctx, d, meta)
-	}
+	cloudEnvironment.Spec.DefaultGateway = convertGateway(d.Get("default_gateway"))
+	return nil
+}
 
-	err = retry.RetryContext(ctx, 3*time.Minute, func() *retry.RetryError {
-		dia := resourceCloudEnvironmentRead(ctx, d, meta)
-		if dia.HasError() {
-			return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_READ_CLOUD_ENVIRONMENT: %s", dia[0].Summary))
-		}
-		return nil
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_READ_CLOUD_ENVIRONMENT: %w", err))
+// expandCloudEnvironmentUpdate sets the default gateway of the cloud environment, the gateway access and
+// the other attributes can't be updated
+func expandCloudEnvironmentUpdate(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, cloudEnvironment *cloudv1alpha1.CloudEnvironment,
+) error {
+	old, new := d.GetChange("default_gateway")
+	if convertGateway(old).Access != convertGateway(new).Access {
+		return fmt.Errorf("The cloud environment does not support updating the gateway access, please recreate it")
 	}
+	if d.HasChanges("organization", "cloud_connection_name", "region", "network") {
+		return fmt.Errorf("The cloud environment does not support updates on the attributes: " +
+			"organization, cloud_connection_name, region, network. Please recreate it")
+	}
+	cloudEnvironment.Spec.DefaultGateway = convertGateway(d.Get("default_gateway"))
 	return nil
 }
 
-func resourceCloudEnvironmentRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := strings.Split(d.Id(), "/")[1]
-
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_CLOUD_ENVIRONMENT: %w", err))
+func flattenCloudEnvironment(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, cloudEnvironment *cloudv1alpha1.CloudEnvironment,
+) error {
+	if err := d.Set("region", cloudEnvironment.Spec.Region); err != nil {
+		return fmt.Errorf("ERROR_SET_REGION: %w", err)
 	}
-	cloudEnvironment, err := clientSet.CloudV1alpha1().CloudEnvironments(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			d.SetId("")
-			return nil
-		}
-		return diag.FromErr(fmt.Errorf("ERROR_READ_CLOUD_ENVIRONMENT: %w", err))
+	if err := d.Set("cloud_connection_name", cloudEnvironment.Spec.CloudConnectionName); err != nil {
+		return fmt.Errorf("ERROR_SET_CLOUD_CONNECTION_NAME: %w", err)
 	}
-
-	_ = d.Set("region", cloudEnvironment.Spec.Region)
-	_ = d.Set("cloud_connection_name", cloudEnvironment.Spec.CloudConnectionName)
-
 	if cloudEnvironment.Spec.Network != nil {
-		err = d.Set("network", flattenCloudEnvironmentNetwork(cloudEnvironment.Spec.Network))
-		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_READ_CLOUD_ENVIRONMENT_CONFIG: %w", err))
+		if err := d.Set("network", flattenCloudEnvironmentNetwork(cloudEnvironment.Spec.Network)); err != nil {
+			return fmt.Errorf("ERROR_READ_CLOUD_ENVIRONMENT_CONFIG: %w", err)
 		}
 	}
-
 	if cloudEnvironment.Spec.DefaultGateway != nil {
-		_ = d.Set("default_gateway", flattenDefaultGateway(cloudEnvironment.Spec.DefaultGateway))
+		if err := d.Set("default_gateway", flattenDefaultGateway(cloudEnvironment.Spec.DefaultGateway)); err != nil {
+			return fmt.Errorf("ERROR_SET_DEFAULT_GATEWAY: %w", err)
+		}
 	}
-
-	d.SetId(fmt.Sprintf("%s/%s", cloudEnvironment.Namespace, cloudEnvironment.Name))
 	return nil
 }
 
-func resourceCloudEnvironmentUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	waitForCompletion := d.Get("wait_for_completion").(bool)
-	name := strings.Split(d.Id(), "/")[1]
-
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_UPDATE_CLOUD_ENVIRONMENT: %w", err))
-	}
-
-	old, new := d.GetChange("default_gateway")
-	oldGateway := convertGateway(old)
-	newGateway := convertGateway(new)
-
-	if oldGateway.Access != newGateway.Access {
-		return diag.Errorf("ERROR_UPDATE_CLOUD_ENVIRONMENT: " +
-			"The cloud environment does not support updating the gateway access, please recreate it")
-	}
-
-	if d.HasChanges("organization") ||
-		d.HasChanges("cloud_connection_name") ||
-		d.HasChanges("region") ||
-		d.HasChanges("network_id") ||
-		d.HasChanges("network_cidr") ||
-		d.HasChanges("network_subnet_cidr") {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_CLOUD_ENVIRONMENT: " +
-			"The cloud environment does not support updates on the attributes: organization, cloud_connection_name, region, network_id, network_cidr, network_subnet_cidr. Please recreate it"))
-	}
-
-	cloudEnvironment, err := clientSet.CloudV1alpha1().CloudEnvironments(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_READ_CLOUD_ENVIRONMENT: %w", err))
-	}
-
-	cloudEnvironment.Spec.DefaultGateway = convertGateway(d.Get("default_gateway"))
-
-	if _, err := clientSet.CloudV1alpha1().CloudEnvironments(namespace).Update(ctx, cloudEnvironment, metav1.UpdateOptions{
-		FieldManager: "terraform-update",
-	}); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_CLOUD_ENVIRONMENT: %w", err))
-	}
-
-	ready := false
-
-	if waitForCompletion {
-		err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), retryUntilCloudEnvironmentIsProvisioned(ctx, clientSet, namespace, cloudEnvironment.GetObjectMeta().GetName()))
-		if err != nil {
-			return diag.FromErr(err)
-		}
-	} else {
-		for _, condition := range cloudEnvironment.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				ready = true
-			}
+func cloudEnvironmentReady(cloudEnvironment *cloudv1alpha1.CloudEnvironment) bool {
+	for _, condition := range cloudEnvironment.Status.Conditions {
+		if condition.Type == "Ready" && condition.Status == "True" {
+			return true
 		}
 	}
+	return false
+}
 
-	if ready {
-		_ = d.Set("organization", namespace)
-		return resourceCloudEnvironmentRead(ctx, d, meta)
+// resourceCloudEnvironmentImportRead reads the attributes which are only saved in the annotations or
+// aren't refreshed by the read, the annotations managed by StreamNative Cloud are skipped
+func resourceCloudEnvironmentImportRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := cloudEnvironmentEngine.read(ctx, d, meta); diags.HasError() || d.Id() == "" {
+		return diags
 	}
-
-	err = retry.RetryContext(ctx, 3*time.Minute, func() *retry.RetryError {
-		dia := resourceCloudEnvironmentRead(ctx, d, meta)
-		if dia.HasError() {
-			return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_READ_CLOUD_ENVIRONMENT: %s", dia[0].Summary))
-		}
-		return nil
-	})
+	id, err := parseImportID(d.Id(), false)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_READ_CLOUD_ENVIRONMENT: %w", err))
+		return diag.FromErr(fmt.Errorf("ERROR_PARSE_IMPORT_ID: %w", err))
 	}
-
-	return nil
-}
-
-func resourceCloudEnvironmentDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	namespace := d.Get("organization").(string)
-	name := strings.Split(d.Id(), "/")[1]
-	waitForCompletion := d.Get("wait_for_completion")
-
-	// Get CloudEnvironment to update the annotation
-	cloudEnvironment, err := clientSet.CloudV1alpha1().CloudEnvironments(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		if errors.IsNotFound(err) {
-			//If we can't find the CE, just return, as the CE is already deleted
-			return nil
-		} else {
-			return diag.FromErr(fmt.Errorf("ERROR_READ_CLOUD_ENVIRONMENT: %w", err))
-		}
-	}
-
-	cloudEnvironment.Annotations["cloud.streamnative.io/destroy-protected"] = "false"
-
-	if _, err := clientSet.CloudV1alpha1().CloudEnvironments(namespace).Update(ctx, cloudEnvironment, metav1.UpdateOptions{
-		FieldManager: "terraform-update",
-	}); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_CLOUD_ENVIRONMENT: %w", err))
+		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_IMPORT_CLOUD_ENVIRONMENT: %w", err))
 	}
-
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_DELETE_CLOUD_ENVIRONMENT: %w", err))
-	}
-
-	err = clientSet.CloudV1alpha1().CloudEnvironments(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	cloudEnvironment, err := clientSet.CloudV1alpha1().CloudEnvironments(id.Organization).Get(
+		ctx, id.Name, metav1.GetOptions{})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("DELETE_CLOUD_ENVIRONMENT: %w", err))
+		return diag.FromErr(fmt.Errorf("ERROR_READ_CLOUD_ENVIRONMENT: %w", err))
 	}
-
-	if waitForCompletion == true {
-		err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), retryUntilCloudEnvironmentIsDeleted(ctx, clientSet, namespace, name))
-		if err != nil {
-			return diag.FromErr(err)
+	annotations := map[string]interface{}{}
+	for k, v := range cloudEnvironment.Annotations {
+		if strings.Contains(k, "streamnative.io/") {
+			continue
 		}
+		annotations[k] = v
 	}
-
-	return nil
-}
-
-// retryUntilCloudEnvironmentIsProvisioned checks if a given CloudEnvironment has finished provisioning
-func retryUntilCloudEnvironmentIsProvisioned(ctx context.Context, clientSet *cloudclient.Clientset, ns string, name string) retry.RetryFunc {
-	return func() *retry.RetryError {
-		ce, err := clientSet.CloudV1alpha1().CloudEnvironments(ns).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			if statusErr, ok := err.(*apierrors.StatusError); ok && apierrors.IsNotFound(statusErr) {
-				return nil
-			}
-			return retry.NonRetryableError(err)
-		}
-
-		for _, condition := range ce.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				return nil
-			}
-		}
-
-		//Sleep 10 seconds between checks so we don't overload the API
-		time.Sleep(time.Second * 10)
-
-		return retry.RetryableError(fmt.Errorf("cloudenvironment: %s/%s is not in complete state", ns, name))
+	_ = d.Set("annotations", annotations)
+	_ = d.Set("environment_type", cloudEnvironment.Annotations["cloud.streamnative.io/environment-type"])
+	if cloudEnvironment.Spec.Zone != nil {
+		_ = d.Set("zone", *cloudEnvironment.Spec.Zone)
 	}
-}
-
-// retryUntilCloudEnvironmentIsDeleted checks if a given CloudEnvironment has finished deleting
-func retryUntilCloudEnvironmentIsDeleted(ctx context.Context, clientSet *cloudclient.Clientset, ns string, name string) retry.RetryFunc {
-	return func() *retry.RetryError {
-		//Sleep 10 seconds between checks so we don't overload the API
-		time.Sleep(time.Second * 10)
-
-		_, err := clientSet.CloudV1alpha1().CloudEnvironments(ns).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			if strings.Contains(err.Error(), "not found") {
-				return nil
-			} else {
-				return retry.RetryableError(fmt.Errorf("cloudenvironment: %s/%s is not in complete state", ns, name))
-			}
-		}
-
-		return retry.RetryableError(fmt.Errorf("cloudenvironment: %s/%s is not in complete state", ns, name))
+	if cloudEnvironment.Spec.DNS != nil {
+		_ = d.Set("dns", []interface{}{map[string]interface{}{
+			"id":   cloudEnvironment.Spec.DNS.ID,
+			"name": cloudEnvironment.Spec.DNS.Name,
+		}})
 	}
+	return nil
 }
@@ -0,0 +1,302 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	cloudclient "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultResourceTimeout is the timeout of the create, update and delete of the resources which are
+// implemented by a resourceEngine and don't declare their own timeouts
+const defaultResourceTimeout = 10 * time.Minute
+
+// resourceClient is the typed client of a cloud v1alpha1 kind in a namespace, the typed clients of the
+// clientset implement it for the pointer to their kind, e.g. VolumeInterface for *v1alpha1.Volume
+type resourceClient[T metav1.Object] interface {
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (T, error)
+	Create(ctx context.Context, obj T, opts metav1.CreateOptions) (T, error)
+	Update(ctx context.Context, obj T, opts metav1.UpdateOptions) (T, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+}
+
+// resourceEngine implements the create, read, update and delete of the SDKv2 resources which manage
+// an object of a cloud v1alpha1 kind identified by <organization>/<name>. It parses the ID, maps the
+// NotFound errors to a removed resource, waits for the object to be ready or deleted within the
+// timeouts of the resource and reports the errors as ERROR_<ACTION>_<KIND> diagnostics
+type resourceEngine[T metav1.Object] struct {
+	// kind is the kind in the diagnostics, e.g. VOLUME for ERROR_CREATE_VOLUME
+	kind string
+	// client returns the typed client of the kind in the namespace
+	client func(clientSet cloudclient.Interface, namespace string) resourceClient[T]
+	// newObject returns an empty object, its namespace and name are set from the configuration before
+	// it's expanded
+	newObject func() T
+	// expand sets the object from the configuration, it's called with the new object on create and
+	// with the latest object on update
+	expand func(ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, obj T) error
+	// expandUpdate sets the latest object from the configuration on update, expand is used when it's nil
+	expandUpdate func(ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, obj T) error
+	// flatten sets the attributes of the state other than the organization and the name from the object,
+	// the clientSet reads the objects which the attributes are derived from
+	flatten func(ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, obj T) error
+	// ready reports whether the object is ready, the engine doesn't wait for the object when it's nil
+	ready func(obj T) bool
+	// afterCreate creates the objects which depend on the created object, it's optional
+	afterCreate func(ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, obj T) error
+	// beforeDelete updates the object before it's deleted, e.g. to lift its protection against the delete,
+	// it's optional
+	beforeDelete func(obj T) error
+	// generatedName is set for the kinds whose name is generated by the API server, their resources have
+	// no name attribute and are only identified by the ID
+	generatedName bool
+	// immutable rejects the updates, the attributes of the immutable resources are replaced or rejected
+	// by their CustomizeDiff
+	immutable bool
+	// deletePropagation is the propagation policy of the delete, the default of the API server is
+	// used when it's empty
+	deletePropagation metav1.DeletionPropagation
+}
+
+// resourceEngineTimeouts returns the timeouts of the resources implemented by a resourceEngine
+func resourceEngineTimeouts() *schema.ResourceTimeout {
+	return &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(defaultResourceTimeout),
+		Update: schema.DefaultTimeout(defaultResourceTimeout),
+		Delete: schema.DefaultTimeout(defaultResourceTimeout),
+	}
+}
+
+// errorf returns the diagnostics of the error of the action on the kind
+func (e *resourceEngine[T]) errorf(action string, err error) diag.Diagnostics {
+	return diag.FromErr(fmt.Errorf("ERROR_%s_%s: %w", action, e.kind, err))
+}
+
+// id returns the organization and the name of the object, they're parsed from the ID when it's set so
+// the object is found before the configuration is read into the state
+func (e *resourceEngine[T]) id(d *schema.ResourceData) (importID, error) {
+	if d.Id() == "" {
+		id := importID{Organization: d.Get("organization").(string)}
+		if !e.generatedName {
+			id.Name = d.Get("name").(string)
+		}
+		return id, nil
+	}
+	return parseImportID(d.Id(), false)
+}
+
+// waitForCompletion reports whether the engine waits for the object to be ready or deleted, it's
+// disabled by the wait_for_completion attribute of the resources which have it
+func (e *resourceEngine[T]) waitForCompletion(d *schema.ResourceData) bool {
+	wait, ok := d.Get("wait_for_completion").(bool)
+	return !ok || wait
+}
+
+// setState sets the state and the ID of the resource from the object
+func (e *resourceEngine[T]) setState(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, obj T,
+) diag.Diagnostics {
+	if err := d.Set("organization", obj.GetNamespace()); err != nil {
+		return diag.FromErr(fmt.Errorf("ERROR_SET_ORGANIZATION: %w", err))
+	}
+	if !e.generatedName {
+		if err := d.Set("name", obj.GetName()); err != nil {
+			return diag.FromErr(fmt.Errorf("ERROR_SET_NAME: %w", err))
+		}
+	}
+	if err := e.flatten(ctx, d, clientSet, obj); err != nil {
+		return e.errorf("FLATTEN", err)
+	}
+	d.SetId(importID{Organization: obj.GetNamespace(), Name: obj.GetName()}.String())
+	return nil
+}
+
+func (e *resourceEngine[T]) create(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	clientSet, err := getClientSet(getFactoryFromMeta(meta))
+	if err != nil {
+		return e.errorf("INIT_CLIENT_ON_CREATE", err)
+	}
+	namespace := d.Get("organization").(string)
+	obj := e.newObject()
+	obj.SetNamespace(namespace)
+	if !e.generatedName {
+		obj.SetName(d.Get("name").(string))
+	}
+	if err = e.expand(ctx, d, clientSet, obj); err != nil {
+		return e.errorf("CREATE", err)
+	}
+	created, err := e.client(clientSet, namespace).Create(ctx, obj, metav1.CreateOptions{
+		FieldManager: "terraform-create",
+	})
+	if err != nil {
+		return e.errorf("CREATE", err)
+	}
+	d.SetId(importID{Organization: created.GetNamespace(), Name: created.GetName()}.String())
+	if e.afterCreate != nil {
+		if err = e.afterCreate(ctx, d, clientSet, created); err != nil {
+			return e.errorf("CREATE", err)
+		}
+	}
+	return e.waitReady(ctx, d, clientSet, d.Timeout(schema.TimeoutCreate))
+}
+
+func (e *resourceEngine[T]) read(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id, err := e.id(d)
+	if err != nil {
+		return e.errorf("PARSE_ID", err)
+	}
+	clientSet, err := getClientSet(getFactoryFromMeta(meta))
+	if err != nil {
+		return e.errorf("INIT_CLIENT_ON_READ", err)
+	}
+	obj, err := e.client(clientSet, id.Organization).Get(ctx, id.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return e.errorf("READ", err)
+	}
+	return e.setState(ctx, d, clientSet, obj)
+}
+
+func (e *resourceEngine[T]) update(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	// allow_replacement and wait_for_completion only change how the plan is made and applied, they're
+	// saved in the state without updating the object
+	if d.HasChanges("allow_replacement", "wait_for_completion") &&
+		!d.HasChangesExcept("allow_replacement", "wait_for_completion") {
+		return nil
+	}
+	if e.immutable {
+		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_%s: The %s does not support updates, please recreate it",
+			e.kind, strings.ToLower(strings.ReplaceAll(e.kind, "_", " "))))
+	}
+	id, err := e.id(d)
+	if err != nil {
+		return e.errorf("PARSE_ID", err)
+	}
+	factory := getFactoryFromMeta(meta)
+	clientSet, err := getClientSet(factory)
+	if err != nil {
+		return e.errorf("INIT_CLIENT_ON_UPDATE", err)
+	}
+	client := e.client(clientSet, id.Organization)
+	expand := e.expand
+	if e.expandUpdate != nil {
+		expand = e.expandUpdate
+	}
+	_, err = retryUpdateOnConflict(ctx, factory, id.Name, client.Get, client.Update, func(obj T) error {
+		return expand(ctx, d, clientSet, obj)
+	}, metav1.UpdateOptions{
+		FieldManager: "terraform-update",
+	})
+	if err != nil {
+		return e.errorf("UPDATE", err)
+	}
+	return e.waitReady(ctx, d, clientSet, d.Timeout(schema.TimeoutUpdate))
+}
+
+func (e *resourceEngine[T]) delete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	id, err := e.id(d)
+	if err != nil {
+		return e.errorf("PARSE_ID", err)
+	}
+	factory := getFactoryFromMeta(meta)
+	clientSet, err := getClientSet(factory)
+	if err != nil {
+		return e.errorf("INIT_CLIENT_ON_DELETE", err)
+	}
+	client := e.client(clientSet, id.Organization)
+	if e.beforeDelete != nil {
+		_, err = retryUpdateOnConflict(ctx, factory, id.Name, client.Get, client.Update, e.beforeDelete,
+			metav1.UpdateOptions{
+				FieldManager: "terraform-update",
+			})
+		if apierrors.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		if err != nil {
+			return e.errorf("UPDATE_BEFORE_DELETE", err)
+		}
+	}
+	opts := metav1.DeleteOptions{}
+	if e.deletePropagation != "" {
+		propagation := e.deletePropagation
+		opts.PropagationPolicy = &propagation
+	}
+	if err = client.Delete(ctx, id.Name, opts); err != nil && !apierrors.IsNotFound(err) {
+		return e.errorf("DELETE", err)
+	}
+	if !e.waitForCompletion(d) {
+		d.SetId("")
+		return nil
+	}
+	err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *retry.RetryError {
+		_, err := client.Get(ctx, id.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return retry.NonRetryableError(err)
+		}
+		return retry.RetryableError(fmt.Errorf("CONTINUE_WAITING_%s_DELETED: %s is not deleted yet", e.kind, id))
+	})
+	if err != nil {
+		return e.errorf("WAIT_DELETED", err)
+	}
+	d.SetId("")
+	return nil
+}
+
+// waitReady waits until the object is ready and sets the state from the ready object, the object is
+// read once when the kind has no readiness or the resource doesn't wait for completion
+func (e *resourceEngine[T]) waitReady(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, timeout time.Duration,
+) diag.Diagnostics {
+	id, err := e.id(d)
+	if err != nil {
+		return e.errorf("PARSE_ID", err)
+	}
+	client := e.client(clientSet, id.Organization)
+	var obj T
+	err = retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		var err error
+		obj, err = client.Get(ctx, id.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return retry.RetryableError(err)
+			}
+			return retry.NonRetryableError(err)
+		}
+		if e.ready != nil && e.waitForCompletion(d) && !e.ready(obj) {
+			return retry.RetryableError(fmt.Errorf("CONTINUE_WAITING_%s_READY: %s is not ready yet", e.kind, id))
+		}
+		return nil
+	})
+	if err != nil {
+		return e.errorf("WAIT_READY", err)
+	}
+	return e.setState(ctx, d, clientSet, obj)
+}
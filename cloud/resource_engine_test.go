@@ -0,0 +1,220 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestResourceEngineLifecycle(t *testing.T) {
+	ctx := context.Background()
+	meta, clientSet := newFakeProviderMeta()
+	clientSet.PrependReactor("create", "volumes", markCreatedReady)
+	d := schema.TestResourceDataRaw(t, resourceVolume().Schema, map[string]interface{}{
+		"organization": "sndev",
+		"name":         "volume",
+		"bucket":       "bucket",
+		"path":         "path",
+		"region":       "us-east-1",
+		"role_arn":     "arn:aws:iam::123456789012:role/volume",
+	})
+
+	diags := volumeEngine.create(ctx, d, meta)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, "sndev/volume", d.Id())
+	assert.Equal(t, "True", d.Get("ready"))
+	volume, err := clientSet.CloudV1alpha1().Volumes("sndev").Get(ctx, "volume", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "bucket", volume.Spec.Bucket)
+	assert.Equal(t, "us-east-1", volume.Spec.AWS.Region)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/volume", volume.Spec.AWS.RoleArn)
+
+	// the object is read by the ID when the configuration isn't in the state
+	imported := schema.TestResourceDataRaw(t, resourceVolume().Schema, map[string]interface{}{})
+	imported.SetId("sndev/volume")
+	diags = volumeEngine.read(ctx, imported, meta)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, "volume", imported.Get("name"))
+	assert.Equal(t, "path", imported.Get("path"))
+
+	assert.NoError(t, d.Set("bucket", "updated"))
+	diags = volumeEngine.update(ctx, d, meta)
+	assert.False(t, diags.HasError(), diags)
+	volume, err = clientSet.CloudV1alpha1().Volumes("sndev").Get(ctx, "volume", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "updated", volume.Spec.Bucket)
+
+	diags = volumeEngine.delete(ctx, d, meta)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, "", d.Id())
+	_, err = clientSet.CloudV1alpha1().Volumes("sndev").Get(ctx, "volume", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	// the resource is removed from the state when the object is deleted out of band
+	diags = volumeEngine.read(ctx, imported, meta)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, "", imported.Id())
+}
+
+func TestResourceEngineErrors(t *testing.T) {
+	ctx := context.Background()
+	meta, clientSet := newFakeProviderMeta()
+
+	d := schema.TestResourceDataRaw(t, resourceCloudConnection().Schema, map[string]interface{}{
+		"organization": "sndev",
+		"name":         "connection",
+		"type":         "aws",
+	})
+	diags := cloudConnectionEngine.create(ctx, d, meta)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "ERROR_CREATE_CLOUD_CONNECTION: one of aws.account_id")
+	assert.Empty(t, clientSet.Actions())
+
+	diags = cloudConnectionEngine.update(ctx, d, meta)
+	assert.True(t, diags.HasError())
+	assert.Equal(t, "ERROR_UPDATE_CLOUD_CONNECTION: The cloud connection does not support updates, "+
+		"please recreate it", diags[0].Summary)
+
+	// the changes of allow_replacement alone are saved in the state only
+	state := &terraform.InstanceState{
+		ID: "sndev/connection",
+		Attributes: map[string]string{
+			"id":                "sndev/connection",
+			"organization":      "sndev",
+			"name":              "connection",
+			"type":              "aws",
+			"allow_replacement": "false",
+		},
+	}
+	changed, err := schema.InternalMap(resourceCloudConnection().Schema).Data(state, &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"allow_replacement": {Old: "false", New: "true"},
+		},
+	})
+	assert.NoError(t, err)
+	diags = cloudConnectionEngine.update(ctx, changed, meta)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, true, changed.Get("allow_replacement"))
+	assert.Empty(t, clientSet.Actions())
+	changed, err = schema.InternalMap(resourceCloudConnection().Schema).Data(state, &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"allow_replacement": {Old: "false", New: "true"},
+			"type":              {Old: "aws", New: "gcp"},
+		},
+	})
+	assert.NoError(t, err)
+	diags = cloudConnectionEngine.update(ctx, changed, meta)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "ERROR_UPDATE_CLOUD_CONNECTION")
+
+	d.SetId("sndev")
+	diags = cloudConnectionEngine.read(ctx, d, meta)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "ERROR_PARSE_ID_CLOUD_CONNECTION")
+
+	// the volume never becomes ready without the controller
+	volume := schema.TestResourceDataRaw(t, resourceVolume().Schema, map[string]interface{}{
+		"organization": "sndev",
+		"name":         "volume",
+		"bucket":       "bucket",
+		"path":         "path",
+		"region":       "us-east-1",
+		"role_arn":     "arn:aws:iam::123456789012:role/volume",
+	})
+	volumeClient := clientSet.CloudV1alpha1().Volumes("sndev")
+	_, err = volumeClient.Create(ctx, &cloudv1alpha1.Volume{
+		ObjectMeta: metav1.ObjectMeta{Name: "volume", Namespace: "sndev"},
+	}, metav1.CreateOptions{})
+	assert.NoError(t, err)
+	diags = volumeEngine.waitReady(ctx, volume, clientSet, time.Second)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "ERROR_WAIT_READY_VOLUME")
+
+	diags = volumeEngine.create(ctx, volume, meta)
+	assert.True(t, diags.HasError())
+	assert.Contains(t, diags[0].Summary, "ERROR_CREATE_VOLUME")
+}
+
+func TestResourceEngineWithoutWaitForCompletion(t *testing.T) {
+	ctx := context.Background()
+	meta, clientSet := newFakeProviderMeta(&cloudv1alpha1.PoolMember{
+		ObjectMeta: metav1.ObjectMeta{Name: "aws-use1", Namespace: "sndev"},
+	})
+	d := schema.TestResourceDataRaw(t, resourcePulsarGateway().Schema, map[string]interface{}{
+		"organization":        "sndev",
+		"name":                "gateway",
+		"access":              "public",
+		"pool_member_name":    "aws-use1",
+		"wait_for_completion": false,
+	})
+
+	// the gateway is saved in the state without waiting for it to be ready
+	diags := pulsarGatewayEngine.create(ctx, d, meta)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, "sndev/gateway", d.Id())
+	assert.Equal(t, "aws-use1", d.Get("pool_member_name"))
+
+	diags = pulsarGatewayEngine.delete(ctx, d, meta)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, "", d.Id())
+	_, err := clientSet.CloudV1alpha1().PulsarGateways("sndev").Get(ctx, "gateway", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestResourceEngineBeforeDelete(t *testing.T) {
+	ctx := context.Background()
+	meta, clientSet := newFakeProviderMeta(&cloudv1alpha1.CloudEnvironment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "env-abc",
+			Namespace:   "sndev",
+			Annotations: map[string]string{"cloud.streamnative.io/destroy-protected": "true"},
+		},
+	})
+	var protection []string
+	clientSet.PrependReactor("delete", "cloudenvironments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj, err := clientSet.Tracker().Get(action.GetResource(), "sndev", "env-abc")
+		if err != nil {
+			return true, nil, err
+		}
+		protection = append(protection, obj.(metav1.Object).GetAnnotations()["cloud.streamnative.io/destroy-protected"])
+		return false, nil, nil
+	})
+	d := schema.TestResourceDataRaw(t, resourceCloudEnvironment().Schema, map[string]interface{}{})
+	d.SetId("sndev/env-abc")
+
+	// the protection of the cloud environment is lifted before it's deleted
+	diags := cloudEnvironmentEngine.delete(ctx, d, meta)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, []string{"false"}, protection)
+	assert.Equal(t, "", d.Id())
+
+	// the cloud environment which is already deleted is removed from the state
+	d.SetId("sndev/env-abc")
+	diags = cloudEnvironmentEngine.delete(ctx, d, meta)
+	assert.False(t, diags.HasError(), diags)
+	assert.Equal(t, []string{"false"}, protection)
+	assert.Equal(t, "", d.Id())
+}
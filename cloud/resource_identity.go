@@ -0,0 +1,137 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The identity attributes of the resources, the instance name is added to the identity of the
+// resources which belong to a pulsar instance
+var (
+	organizationNameIdentity = []string{"organization", "name"}
+	instanceResourceIdentity = []string{"organization", "name", "instance_name"}
+)
+
+// newResourceIdentity returns the identity schema of the attributes, the organization and the name
+// are required to import the resource by the identity
+func newResourceIdentity(attributes []string) *schema.ResourceIdentity {
+	return &schema.ResourceIdentity{
+		Version: 1,
+		SchemaFunc: func() map[string]*schema.Schema {
+			identitySchema := map[string]*schema.Schema{}
+			for _, attribute := range attributes {
+				identitySchema[attribute] = &schema.Schema{
+					Type:              schema.TypeString,
+					RequiredForImport: attribute == "organization" || attribute == "name",
+					OptionalForImport: attribute != "organization" && attribute != "name",
+					Description:       descriptions["identity_"+attribute],
+				}
+			}
+			return identitySchema
+		},
+	}
+}
+
+// withResourceIdentity sets the identity of the resource after the create, read or update succeeds,
+// the organization and the name are taken from the ID in the format <organization>/<name>. The stored
+// identity must match the object which is read, the identity of a resource never changes
+func withResourceIdentity[F ~func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics](
+	f F, attributes []string) F {
+	return func(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+		diags := f(ctx, d, meta)
+		if diags.HasError() || d.Id() == "" {
+			return diags
+		}
+		if err := setResourceIdentity(d, attributes); err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		return diags
+	}
+}
+
+func setResourceIdentity(d *schema.ResourceData, attributes []string) error {
+	id, err := parseImportID(d.Id(), false)
+	if err != nil {
+		return err
+	}
+	identity, err := d.Identity()
+	if err != nil {
+		return fmt.Errorf("ERROR_SET_RESOURCE_IDENTITY: %w", err)
+	}
+	stored := map[string]string{}
+	read := map[string]string{}
+	for _, attribute := range attributes {
+		stored[attribute], _ = identity.Get(attribute).(string)
+		switch attribute {
+		case "organization":
+			read[attribute] = id.Organization
+		case "name":
+			read[attribute] = id.Name
+		default:
+			read[attribute], _ = d.Get(attribute).(string)
+		}
+	}
+	if err = checkResourceIdentity(stored, read); err != nil {
+		return fmt.Errorf("ERROR_RESOURCE_IDENTITY_CHANGED: %w", err)
+	}
+	for _, attribute := range attributes {
+		if err = identity.Set(attribute, read[attribute]); err != nil {
+			return fmt.Errorf("ERROR_SET_RESOURCE_IDENTITY: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkResourceIdentity returns an error when the stored identity doesn't match the identity of the
+// object which is read, the attributes which aren't stored yet are skipped
+func checkResourceIdentity(stored, read map[string]string) error {
+	for _, attribute := range []string{"organization", "name", "instance_name"} {
+		if stored[attribute] != "" && stored[attribute] != read[attribute] {
+			return fmt.Errorf("the %s of the resource identity is %q, but the %s of the object is %q",
+				attribute, stored[attribute], attribute, read[attribute])
+		}
+	}
+	return nil
+}
+
+// setIDFromIdentity sets the import ID of the resource imported by the identity instead of the ID,
+// the instance name is only in the import ID when it's set in the identity
+func setIDFromIdentity(d *schema.ResourceData, instanceScoped bool) error {
+	if d.Id() != "" {
+		return nil
+	}
+	identity, err := d.Identity()
+	if err != nil {
+		return err
+	}
+	organization, _ := identity.Get("organization").(string)
+	name, _ := identity.Get("name").(string)
+	if organization == "" || name == "" {
+		return fmt.Errorf("the identity must contain the organization and the name")
+	}
+	if instanceScoped {
+		if instanceName, _ := identity.Get("instance_name").(string); instanceName != "" {
+			d.SetId(fmt.Sprintf("%s/%s/%s", organization, instanceName, name))
+			return nil
+		}
+	}
+	d.SetId(fmt.Sprintf("%s/%s", organization, name))
+	return nil
+}
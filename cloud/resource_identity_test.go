@@ -0,0 +1,108 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceIdentitySchemas(t *testing.T) {
+	server, err := NewProviderServer(context.Background(), Provider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server().GetResourceIdentitySchemas(
+		context.Background(), &tfprotov5.GetResourceIdentitySchemasRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, resp.Diagnostics)
+	for name := range Provider().ResourcesMap {
+		assert.Contains(t, resp.IdentitySchemas, name)
+	}
+	for _, name := range []string{"streamnative_apikey", "streamnative_secret", "streamnative_pulsar_cluster"} {
+		if assert.Contains(t, resp.IdentitySchemas, name) {
+			attributes := map[string]bool{}
+			for _, attribute := range resp.IdentitySchemas[name].IdentityAttributes {
+				attributes[attribute.Name] = attribute.RequiredForImport
+			}
+			assert.Equal(t, map[string]bool{"organization": true, "name": true, "instance_name": false}, attributes)
+		}
+	}
+}
+
+func TestSetResourceIdentity(t *testing.T) {
+	r := resourcePulsarCluster()
+	d := schema.TestResourceDataWithIdentityRaw(t, r.Schema, r.Identity.SchemaFunc(), map[string]string{
+		"organization": "sndev",
+		"name":         "test-cluster",
+	})
+	assert.NoError(t, setIDFromIdentity(d, true))
+	assert.Equal(t, "sndev/test-cluster", d.Id())
+
+	assert.NoError(t, d.Set("instance_name", "test-instance"))
+	assert.NoError(t, setResourceIdentity(d, instanceResourceIdentity))
+	identity, err := d.Identity()
+	assert.NoError(t, err)
+	assert.Equal(t, "sndev", identity.Get("organization"))
+	assert.Equal(t, "test-cluster", identity.Get("name"))
+	assert.Equal(t, "test-instance", identity.Get("instance_name"))
+
+	// The object read by the refresh must match the stored identity
+	assert.NoError(t, setResourceIdentity(d, instanceResourceIdentity))
+	assert.NoError(t, d.Set("instance_name", "other-instance"))
+	err = setResourceIdentity(d, instanceResourceIdentity)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "ERROR_RESOURCE_IDENTITY_CHANGED")
+	}
+	assert.NoError(t, d.Set("instance_name", "test-instance"))
+	d.SetId("sndev/other-cluster")
+	err = setResourceIdentity(d, instanceResourceIdentity)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "ERROR_RESOURCE_IDENTITY_CHANGED")
+	}
+
+	d.SetId("test-cluster")
+	assert.Error(t, setResourceIdentity(d, instanceResourceIdentity))
+}
+
+func TestSetInstanceResourceIdentity(t *testing.T) {
+	ctx := context.Background()
+	identitySchema := instanceResourceIdentitySchema()
+	identity := &tfsdk.ResourceIdentity{
+		Schema: identitySchema,
+		Raw:    tftypes.NewValue(identitySchema.Type().TerraformType(ctx), nil),
+	}
+	diags := setInstanceResourceIdentity(ctx, identity,
+		types.StringValue("sndev"), types.StringValue("apikey"), types.StringValue("instance"))
+	assert.False(t, diags.HasError(), diags)
+	diags = setInstanceResourceIdentity(ctx, identity,
+		types.StringValue("sndev"), types.StringValue("apikey"), types.StringValue("instance"))
+	assert.False(t, diags.HasError(), diags)
+
+	diags = setInstanceResourceIdentity(ctx, identity,
+		types.StringValue("sndev"), types.StringValue("other"), types.StringValue("instance"))
+	if assert.True(t, diags.HasError()) {
+		assert.Equal(t, "ERROR_RESOURCE_IDENTITY_CHANGED", diags[0].Summary())
+	}
+}
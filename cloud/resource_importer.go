@@ -0,0 +1,112 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// importID is the ID of an imported resource, the instance name is only set when the resource is
+// imported by <organization>/<instance_name>/<name>
+type importID struct {
+	Organization string
+	InstanceName string
+	Name         string
+}
+
+// String returns the ID of the resource in the state, which never contains the instance name
+func (id importID) String() string {
+	return fmt.Sprintf("%s/%s", id.Organization, id.Name)
+}
+
+// parseImportID parses the import ID in the format <organization>/<name>, the resources which belong
+// to a pulsar instance can be imported by <organization>/<instance_name>/<name> as well
+func parseImportID(id string, instanceScoped bool) (importID, error) {
+	expected := "<organization>/<name>"
+	if instanceScoped {
+		expected += " or <organization>/<instance_name>/<name>"
+	}
+	parts := strings.Split(id, "/")
+	for _, part := range parts {
+		if strings.TrimSpace(part) == "" {
+			return importID{}, fmt.Errorf("invalid import ID %q, expected %s without empty parts", id, expected)
+		}
+	}
+	switch {
+	case len(parts) == 2:
+		return importID{Organization: parts[0], Name: parts[1]}, nil
+	case len(parts) == 3 && instanceScoped:
+		return importID{Organization: parts[0], InstanceName: parts[1], Name: parts[2]}, nil
+	}
+	return importID{}, fmt.Errorf("invalid import ID %q, expected %s", id, expected)
+}
+
+// resourceImporter returns the importer of the SDKv2 resources, the resource is imported either by the
+// import ID or by the identity and read by read. The attributes which aren't read and have a default
+// value are set to the default so the configuration generated by `terraform plan -generate-config-out`
+// applies without changes
+func resourceImporter(read schema.ReadContextFunc, resource func() *schema.Resource) *schema.ResourceImporter {
+	return &schema.ResourceImporter{
+		StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+			r := resource()
+			_, instanceScoped := r.Identity.SchemaMap()["instance_name"]
+			if err := setIDFromIdentity(d, instanceScoped); err != nil {
+				return nil, fmt.Errorf("ERROR_IMPORT_IDENTITY: %w", err)
+			}
+			id, err := parseImportID(d.Id(), instanceScoped)
+			if err != nil {
+				return nil, fmt.Errorf("ERROR_PARSE_IMPORT_ID: %w", err)
+			}
+			d.SetId(id.String())
+			_ = d.Set("organization", id.Organization)
+			_ = d.Set("name", id.Name)
+			if id.InstanceName != "" {
+				_ = d.Set("instance_name", id.InstanceName)
+			}
+			diags := read(ctx, d, meta)
+			if diags.HasError() {
+				return nil, fmt.Errorf("import %q: %s", id, diags[0].Summary)
+			}
+			if d.Id() == "" {
+				return nil, fmt.Errorf("import %q: the resource doesn't exist", id)
+			}
+			setImportDefaults(d, r.Schema)
+			return []*schema.ResourceData{d}, nil
+		},
+	}
+}
+
+// setImportDefaults sets the top level attributes which weren't read to their default value, without
+// the default value in the state the first plan after the import would update the resource. The
+// attributes set by the read are kept even when they are set to the zero value
+func setImportDefaults(d *schema.ResourceData, resourceSchema map[string]*schema.Schema) {
+	attributes := map[string]string{}
+	if state := d.State(); state != nil {
+		attributes = state.Attributes
+	}
+	for name, s := range resourceSchema {
+		if s.Default == nil || s.Computed {
+			continue
+		}
+		if _, ok := attributes[name]; ok {
+			continue
+		}
+		_ = d.Set(name, s.Default)
+	}
+}
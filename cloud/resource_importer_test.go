@@ -0,0 +1,57 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImportID(t *testing.T) {
+	id, err := parseImportID("sndev/test-cluster", false)
+	assert.NoError(t, err)
+	assert.Equal(t, importID{Organization: "sndev", Name: "test-cluster"}, id)
+	assert.Equal(t, "sndev/test-cluster", id.String())
+
+	id, err = parseImportID("sndev/test-instance/test-secret", true)
+	assert.NoError(t, err)
+	assert.Equal(t, importID{Organization: "sndev", InstanceName: "test-instance", Name: "test-secret"}, id)
+	assert.Equal(t, "sndev/test-secret", id.String())
+
+	for _, invalid := range []string{"", "sndev", "sndev/", "/test-cluster", "sndev//test-cluster", "a/b/c/d"} {
+		_, err = parseImportID(invalid, true)
+		assert.Error(t, err, invalid)
+	}
+	_, err = parseImportID("sndev/test-instance/test-secret", false)
+	assert.ErrorContains(t, err, "expected <organization>/<name>")
+}
+
+func TestSetImportDefaults(t *testing.T) {
+	r := resourcePulsarGateway()
+	d := r.TestResourceData()
+	d.SetId("sndev/test-gateway")
+	assert.Equal(t, false, d.Get("wait_for_completion"))
+	setImportDefaults(d, r.Schema)
+	assert.Equal(t, true, d.Get("wait_for_completion"))
+	assert.Equal(t, "", d.Get("access"))
+
+	// The attributes set by the read keep their value even when it's the zero value
+	d = r.TestResourceData()
+	d.SetId("sndev/test-gateway")
+	assert.NoError(t, d.Set("wait_for_completion", false))
+	setImportDefaults(d, r.Schema)
+	assert.Equal(t, false, d.Get("wait_for_completion"))
+}
@@ -24,23 +24,20 @@ import (
 	"k8s.io/utils/pointer"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
 	cloudclient "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset"
-	"k8s.io/apimachinery/pkg/api/errors"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func resourcePulsarCluster() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourcePulsarClusterCreate,
-		ReadContext:   resourcePulsarClusterRead,
-		UpdateContext: resourcePulsarClusterUpdate,
-		DeleteContext: resourcePulsarClusterDelete,
+		CreateContext: withResourceIdentity(pulsarClusterEngine.create, instanceResourceIdentity),
+		ReadContext:   withResourceIdentity(pulsarClusterEngine.read, instanceResourceIdentity),
+		UpdateContext: withResourceIdentity(pulsarClusterEngine.update, instanceResourceIdentity),
+		DeleteContext: pulsarClusterEngine.delete,
+		Identity:      newResourceIdentity(instanceResourceIdentity),
 		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, i interface{}) error {
 			oldOrg, _ := diff.GetChange("organization")
 			oldName, newName := diff.GetChange("name")
@@ -53,28 +50,23 @@ func resourcePulsarCluster() *schema.Resource {
 				// Auto generate the name, so we don't need to check the diff.
 				return nil
 			}
-			if diff.HasChanges([]string{"organization", "name", "instance_name", "location", "pool_member_name", "release_channel"}...) {
-				return fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: " +
-					"The pulsar cluster organization, name, instance_name, location, pool_member_name does not support updates, please recreate it")
+			if err := forceNewIfAllowed(diff, fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: "+
+				"The pulsar cluster organization, name, instance_name, location, pool_member_name, release_channel, volume "+
+				"does not support updates, please recreate it"),
+				"organization", "name", "instance_name", "location", "pool_member_name", "release_channel", "volume"); err != nil {
+				return err
 			}
 			// For serverless clusters, make lakehouse_storage_enabled computed
 			makeLakehouseStorageComputedForServerless(ctx, diff, i)
 			return nil
 		},
-		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				err := resourcePulsarClusterRead(ctx, d, meta)
-				if err.HasError() {
-					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
-				}
-				return []*schema.ResourceData{d}, nil
-			},
-		},
+		Importer: resourceImporter(pulsarClusterEngine.read, resourcePulsarCluster),
 		Timeouts: &schema.ResourceTimeout{
 			// Pulsar clusters can take time to tear down; allow 30m to avoid spurious test failures.
 			Delete: schema.DefaultTimeout(30 * time.Minute),
 		},
 		Schema: map[string]*schema.Schema{
+			"allow_replacement": allowReplacementSchema(),
 			"organization": {
 				Type:         schema.TypeString,
 				Required:     true,
@@ -89,6 +81,7 @@ func resourcePulsarCluster() *schema.Resource {
 			"display_name": {
 				Type:        schema.TypeString,
 				Optional:    true,
+				Computed:    true,
 				Description: descriptions["cluster_display_name"],
 			},
 			"instance_name": {
@@ -100,12 +93,14 @@ func resourcePulsarCluster() *schema.Resource {
 			"location": {
 				Type:         schema.TypeString,
 				Optional:     true,
+				Computed:     true,
 				Description:  descriptions["location"],
 				ValidateFunc: validateNotBlank,
 			},
 			"pool_member_name": {
 				Type:         schema.TypeString,
 				Optional:     true,
+				Computed:     true,
 				Description:  descriptions["pool_member_name"],
 				ValidateFunc: validateNotBlank,
 			},
@@ -264,6 +259,7 @@ func resourcePulsarCluster() *schema.Resource {
 			"endpoint_access": {
 				Type:     schema.TypeList,
 				Optional: true,
+				Computed: true,
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"gateway": {
@@ -424,26 +420,44 @@ func resourcePulsarCluster() *schema.Resource {
 	}
 }
 
-func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
+var pulsarClusterEngine = &resourceEngine[*cloudv1alpha1.PulsarCluster]{
+	kind: "PULSAR_CLUSTER",
+	client: func(clientSet cloudclient.Interface, namespace string) resourceClient[*cloudv1alpha1.PulsarCluster] {
+		return clientSet.CloudV1alpha1().PulsarClusters(namespace)
+	},
+	newObject: func() *cloudv1alpha1.PulsarCluster {
+		return &cloudv1alpha1.PulsarCluster{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "PulsarCluster",
+				APIVersion: cloudv1alpha1.SchemeGroupVersion.String(),
+			},
+		}
+	},
+	expand:       expandPulsarCluster,
+	expandUpdate: expandPulsarClusterUpdate,
+	flatten:      flattenPulsarCluster,
+	ready:        pulsarClusterReady,
+	afterCreate:  logPulsarClusterIAMPolicy,
+}
+
+// expandPulsarCluster sets the new pulsar cluster from the configuration, the configuration is validated
+// against the instance, the pool member, the volume, the gateways and the catalog of the cluster
+func expandPulsarCluster(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, pulsarCluster *cloudv1alpha1.PulsarCluster,
+) error {
+	namespace := pulsarCluster.Namespace
 	displayName := d.Get("display_name").(string)
 	instanceName := d.Get("instance_name").(string)
 	pool_member_name := d.Get("pool_member_name").(string)
 	location := d.Get("location").(string)
 	if pool_member_name == "" && location == "" {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_PULSAR_CLUSTER: " +
-			"either pool_member_name or location must be provided"))
+		return fmt.Errorf("either pool_member_name or location must be provided")
 	}
 	releaseChannel := d.Get("release_channel").(string)
 	bookieReplicas := int32(d.Get("bookie_replicas").(int))
 	brokerReplicas := int32(d.Get("broker_replicas").(int))
 	computeUnit := getComputeUnit(d)
 	storageUnit := getStorageUnit(d)
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_CREATE_PULSAR_CLUSTER: %w", err))
-	}
 	pulsarInstance, err := clientSet.CloudV1alpha1().
 		PulsarInstances(namespace).
 		Get(ctx, instanceName, metav1.GetOptions{
@@ -453,14 +467,12 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 			},
 		})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_GET_PULSAR_INSTANCE_ON_CREATE_PULSAR_CLUSTER: %w", err))
+		return fmt.Errorf("ERROR_GET_PULSAR_INSTANCE_ON_CREATE_PULSAR_CLUSTER: %w", err)
 	}
 	ursaEngine, ok := pulsarInstance.Annotations[UrsaEngineAnnotation]
 	ursaEnabled := ok && ursaEngine == UrsaEngineValue
-	bookieCPU := resource.NewMilliQuantity(int64(storageUnit*2*1000), resource.DecimalSI)
-	brokerCPU := resource.NewMilliQuantity(int64(computeUnit*2*1000), resource.DecimalSI)
-	brokerMem := resource.NewQuantity(int64(computeUnit*8*1024*1024*1024), resource.DecimalSI)
-	bookieMem := resource.NewQuantity(int64(storageUnit*8*1024*1024*1024), resource.DecimalSI)
+	bookieCPU, bookieMem := convertUnitToCpuAndMemory(storageUnit)
+	brokerCPU, brokerMem := convertUnitToCpuAndMemory(computeUnit)
 
 	if pool_member_name != "" {
 		// only allow BYOC user to select specific pool member
@@ -468,32 +480,22 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 			PoolMembers(namespace).
 			Get(ctx, pool_member_name, metav1.GetOptions{})
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_GET_POOL_MEMBER_ON_CREATE_PULSAR_CLUSTER: %w", err))
+			return fmt.Errorf("ERROR_GET_POOL_MEMBER_ON_CREATE_PULSAR_CLUSTER: %w", err)
 		}
 		if poolMember.Spec.PoolName != pulsarInstance.Spec.PoolRef.Name {
-			return diag.FromErr(fmt.Errorf("ERROR_CREATE_PULSAR_CLUSTER: " +
-				"the pool member does not belong to the pool which pulsar instance is attached"))
+			return fmt.Errorf("the pool member does not belong to the pool which pulsar instance is attached")
 		}
 	}
 
-	pulsarCluster := &cloudv1alpha1.PulsarCluster{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "PulsarCluster",
-			APIVersion: cloudv1alpha1.SchemeGroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: namespace,
-		},
-		Spec: cloudv1alpha1.PulsarClusterSpec{
-			InstanceName:   instanceName,
-			Location:       location,
-			ReleaseChannel: releaseChannel,
-			Broker: cloudv1alpha1.Broker{
-				Replicas: &brokerReplicas,
-				Resources: &cloudv1alpha1.DefaultNodeResource{
-					Cpu:    brokerCPU,
-					Memory: brokerMem,
-				},
+	pulsarCluster.Spec = cloudv1alpha1.PulsarClusterSpec{
+		InstanceName:   instanceName,
+		Location:       location,
+		ReleaseChannel: releaseChannel,
+		Broker: cloudv1alpha1.Broker{
+			Replicas: &brokerReplicas,
+			Resources: &cloudv1alpha1.DefaultNodeResource{
+				Cpu:    brokerCPU,
+				Memory: brokerMem,
 			},
 		},
 	}
@@ -506,20 +508,15 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 			},
 		},
 	}
-	if name != "" {
-		pulsarCluster.ObjectMeta.Name = name
-	}
 	if displayName != "" {
 		pulsarCluster.Spec.DisplayName = displayName
 	}
 	if pulsarInstance.IsServerless() {
 		if computeUnit != 0.5 {
-			return diag.FromErr(fmt.Errorf("ERROR_CREATE_PULSAR_CLUSTER: " +
-				"compute_unit must be 0.5 for serverless instance"))
+			return fmt.Errorf("compute_unit must be 0.5 for serverless instance")
 		}
 		if brokerReplicas != 2 {
-			return diag.FromErr(fmt.Errorf("ERROR_CREATE_PULSAR_CLUSTER: " +
-				"broker_replicas must be 2 for serverless instance"))
+			return fmt.Errorf("broker_replicas must be 2 for serverless instance")
 		}
 		pulsarCluster.Annotations = map[string]string{
 			"cloud.streamnative.io/type": "serverless",
@@ -537,7 +534,7 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 		if volumeName != "" {
 			_, err := clientSet.CloudV1alpha1().Volumes(namespace).Get(ctx, volumeName, metav1.GetOptions{})
 			if err != nil {
-				return diag.FromErr(fmt.Errorf("ERROR_GET_VOLUME_ON_CREATE_PULSAR_CLUSTER: %w", err))
+				return fmt.Errorf("ERROR_GET_VOLUME_ON_CREATE_PULSAR_CLUSTER: %w", err)
 			}
 			pulsarCluster.Spec.Volume = &cloudv1alpha1.VolumeReference{
 				Name: volumeName,
@@ -546,8 +543,7 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 	}
 	if ursaEnabled || pulsarInstance.IsServerless() {
 		if pulsarCluster.Spec.ReleaseChannel != "" && pulsarCluster.Spec.ReleaseChannel != "rapid" {
-			return diag.FromErr(fmt.Errorf("ERROR_CREATE_PULSAR_CLUSTER: " +
-				"release_channel must be rapid for ursa engine or serverless instance"))
+			return fmt.Errorf("release_channel must be rapid for ursa engine or serverless instance")
 		}
 	}
 	if !ursaEnabled && !pulsarInstance.IsServerless() {
@@ -566,7 +562,7 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 		if endpoint.Gateway != "default" {
 			_, err := clientSet.CloudV1alpha1().PulsarGateways(namespace).Get(ctx, endpoint.Gateway, metav1.GetOptions{})
 			if err != nil {
-				return diag.FromErr(fmt.Errorf("ERROR_GET_PULSAR_GATEWAY_ON_CREATE_PULSAR_CLUSTER: %w", err))
+				return fmt.Errorf("ERROR_GET_PULSAR_GATEWAY_ON_CREATE_PULSAR_CLUSTER: %w", err)
 			}
 		}
 	}
@@ -587,8 +583,7 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 		// For non-serverless clusters, check user input
 		if d.Get("lakehouse_storage_enabled").(bool) {
 			if ursaEnabled {
-				return diag.FromErr(fmt.Errorf("ERROR_CREATE_PULSAR_CLUSTER: " +
-					"you don't set this option for ursa engine cluster"))
+				return fmt.Errorf("you don't set this option for ursa engine cluster")
 			}
 			if pulsarCluster.Spec.Config == nil {
 				pulsarCluster.Spec.Config = &cloudv1alpha1.Config{}
@@ -606,14 +601,14 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 		// Get catalog information
 		catalog, err = clientSet.CloudV1alpha1().Catalogs(namespace).Get(ctx, catalogName, metav1.GetOptions{})
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_GET_CATALOG: %w", err))
+			return fmt.Errorf("ERROR_GET_CATALOG: %w", err)
 		}
 
 		// Check if it's an S3Table catalog
 		if catalog.Spec.S3Table != nil {
 			// Validate region match
 			if err := validateCatalogRegionMatch(ctx, clientSet, namespace, catalogName, location); err != nil {
-				return diag.FromErr(err)
+				return err
 			}
 		}
 
@@ -632,7 +627,7 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 		if (lakehouseStorageEnabled || ursaEnabled) && catalog != nil {
 			tableFormat, err := determineTableFormat(ctx, clientSet, namespace, catalogName)
 			if err != nil {
-				return diag.FromErr(fmt.Errorf("ERROR_DETERMINE_TABLE_FORMAT: %w", err))
+				return fmt.Errorf("ERROR_DETERMINE_TABLE_FORMAT: %w", err)
 			}
 			pulsarCluster.Spec.TableFormat = tableFormat
 		}
@@ -641,8 +636,7 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 	// Handle SDT annotation based on apply_lakehouse_to_all_topics
 	if shouldApplyLakehouseToAllTopics(d) {
 		if ursaEnabled {
-			return diag.FromErr(fmt.Errorf("ERROR_CREATE_PULSAR_CLUSTER: " +
-				"you don't set this apply_lakehouse_to_all_topics option for ursa engine cluster"))
+			return fmt.Errorf("you don't set this apply_lakehouse_to_all_topics option for ursa engine cluster")
 		}
 		if pulsarCluster.Annotations == nil {
 			pulsarCluster.Annotations = make(map[string]string)
@@ -650,114 +644,45 @@ func resourcePulsarClusterCreate(ctx context.Context, d *schema.ResourceData, me
 		pulsarCluster.Annotations["cloud.streamnative.io/sdt-enabled"] = "true"
 	}
 
-	pc, err := clientSet.CloudV1alpha1().PulsarClusters(namespace).Create(ctx, pulsarCluster, metav1.CreateOptions{
-		FieldManager: "terraform-create",
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_PULSAR_CLUSTER: %w", err))
-	}
-	d.SetId(fmt.Sprintf("%s/%s", pc.Namespace, pc.Name))
-
-	// Generate and set IAM policy if catalog is configured
-	if catalog != nil && catalog.Spec.S3Table != nil {
-		// Try to get account ID from pool options using instance pool information
-		var accountID string
-		if pool_member_name != "" || location != "" {
-			accountIDFromPool, err := getAccountIDFromPoolOptions(
-				ctx, clientSet,
-				pulsarCluster.Namespace,
-				fmt.Sprintf("%s-%s", pulsarInstance.Spec.PoolRef.Namespace, pulsarInstance.Spec.PoolRef.Name),
-				location,
-				pool_member_name)
-			if err != nil {
-				tflog.Warn(ctx, fmt.Sprintf("Failed to get account ID from pool options: %v", err))
-				tflog.Warn(ctx, "Using placeholder account ID in IAM policy")
-			} else {
-				accountID = accountIDFromPool
-				tflog.Info(ctx, fmt.Sprintf("Retrieved account ID from pool options: %s", accountID))
-			}
-		}
-
-		// Get S3Table warehouse
-		s3TableWarehouse := ""
-		if catalog.Spec.S3Table != nil {
-			s3TableWarehouse = catalog.Spec.S3Table.Warehouse
-		}
-
-		iamPolicy := generateIAMPolicy(namespace, name, catalogName, accountID, s3TableWarehouse)
-		_ = d.Set("iam_policy", iamPolicy)
-
-		// Log IAM policy information for user reference
-		tflog.Info(ctx, "🎉 Pulsar cluster created successfully with S3Table catalog!")
-		tflog.Info(ctx, fmt.Sprintf("Cluster: %s", name))
-		tflog.Info(ctx, fmt.Sprintf("Organization: %s", namespace))
-		tflog.Info(ctx, fmt.Sprintf("Catalog: %s", catalogName))
-		if accountID != "" {
-			tflog.Info(ctx, fmt.Sprintf("Account ID: %s", accountID))
-		}
-		tflog.Info(ctx, "IAM Policy has been generated and is available in the 'iam_policy' output.")
-		tflog.Info(ctx, "Please apply this IAM policy to your AWS IAM role to enable S3Table access.")
-	}
-	if pc.Status.Conditions != nil {
-		ready := false
-		for _, condition := range pc.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				ready = true
-			}
-		}
-		if ready {
-			return resourcePulsarClusterRead(ctx, d, meta)
-		}
-	}
-	err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), func() *retry.RetryError {
-		dia := resourcePulsarClusterRead(ctx, d, meta)
-		if dia.HasError() {
-			return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_READ_PULSAR_CLUSTER: %s", dia[0].Summary))
-		}
-		ready := d.Get("ready")
-		if ready == "False" {
-			return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_READ_PULSAR_CLUSTER"))
-		}
-		return nil
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_READ_PULSAR_CLUSTER: %w", err))
-	}
 	return nil
 }
 
-func resourcePulsarClusterRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_PULSAR_CLUSTER: %w", err))
-	}
-	var pulsarCluster *cloudv1alpha1.PulsarCluster
-	if name == "" {
-		organizationCluster := strings.Split(d.Id(), "/")
-		name = organizationCluster[1]
-		namespace = organizationCluster[0]
+// logPulsarClusterIAMPolicy tells the user to apply the IAM policy of the S3Table catalog of the created
+// cluster, the policy is set in the state by the read once the cluster is ready
+func logPulsarClusterIAMPolicy(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, pulsarCluster *cloudv1alpha1.PulsarCluster,
+) error {
+	catalogName := d.Get("catalog").(string)
+	if catalogName == "" {
+		return nil
 	}
-	pulsarCluster, err = clientSet.CloudV1alpha1().PulsarClusters(namespace).Get(ctx, name, metav1.GetOptions{})
+	catalog, err := clientSet.CloudV1alpha1().Catalogs(pulsarCluster.Namespace).Get(ctx, catalogName, metav1.GetOptions{})
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			d.SetId("")
-			return nil
-		}
-		return diag.FromErr(fmt.Errorf("ERROR_READ_PULSAR_CLUSTER: %w", err))
+		return fmt.Errorf("ERROR_GET_CATALOG: %w", err)
 	}
-	_ = d.Set("ready", "False")
-	if pulsarCluster.Status.Conditions != nil {
-		for _, condition := range pulsarCluster.Status.Conditions {
-			if condition.Type == "Ready" {
-				_ = d.Set("ready", condition.Status)
-			}
-		}
+	if catalog.Spec.S3Table == nil {
+		return nil
 	}
+	tflog.Info(ctx, "🎉 Pulsar cluster created successfully with S3Table catalog!")
+	tflog.Info(ctx, fmt.Sprintf("Cluster: %s", pulsarCluster.Name))
+	tflog.Info(ctx, fmt.Sprintf("Organization: %s", pulsarCluster.Namespace))
+	tflog.Info(ctx, fmt.Sprintf("Catalog: %s", catalogName))
+	tflog.Info(ctx, "IAM Policy has been generated and is available in the 'iam_policy' output.")
+	tflog.Info(ctx, "Please apply this IAM policy to your AWS IAM role to enable S3Table access.")
+	return nil
+}
+
+// flattenPulsarCluster sets the state from the pulsar cluster, the service URLs, the type and the IAM
+// policy are derived from the instance and the catalog of the cluster
+func flattenPulsarCluster(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, pulsarCluster *cloudv1alpha1.PulsarCluster,
+) error {
+	namespace := pulsarCluster.Namespace
+	_ = d.Set("ready", pulsarClusterReadyStatus(pulsarCluster))
+	setPulsarClusterSpec(d, pulsarCluster)
 	pulsarInstance, err := clientSet.CloudV1alpha1().PulsarInstances(namespace).Get(ctx, pulsarCluster.Spec.InstanceName, metav1.GetOptions{})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_READ_PULSAR_INSTANCE: %w", err))
+		return fmt.Errorf("ERROR_READ_PULSAR_INSTANCE: %w", err)
 	}
 	istioEnabledVal, ok := pulsarInstance.Annotations[IstioEnabledAnnotation]
 	istioEnabled := ok && istioEnabledVal == "true"
@@ -769,22 +694,23 @@ func resourcePulsarClusterRead(ctx context.Context, d *schema.ResourceData, meta
 	var mqttServiceUrls []string
 	for _, endpoint := range pulsarCluster.Spec.ServiceEndpoints {
 		if endpoint.Type == "service" {
-			httpTlsServiceUrls = append(httpTlsServiceUrls, fmt.Sprintf("https://%s", endpoint.DnsName))
-			pulsarTlsServiceUrls = append(pulsarTlsServiceUrls, fmt.Sprintf("pulsar+ssl://%s:6651", endpoint.DnsName))
+			httpTlsServiceUrls = append(httpTlsServiceUrls,
+				formatServiceURL(endpoint.DnsName, ServiceProtocolHTTP, istioEnabled))
+			pulsarTlsServiceUrls = append(pulsarTlsServiceUrls,
+				formatServiceURL(endpoint.DnsName, ServiceProtocolPulsar, istioEnabled))
 			if pulsarCluster.Spec.Config != nil {
 				if pulsarCluster.Spec.Config.WebsocketEnabled != nil && *pulsarCluster.Spec.Config.WebsocketEnabled {
-					if istioEnabled {
-						websocketServiceUrls = append(websocketServiceUrls, fmt.Sprintf("wss://%s", endpoint.DnsName))
-					} else {
-						websocketServiceUrls = append(websocketServiceUrls, fmt.Sprintf("ws://%s:9443", endpoint.DnsName))
-					}
+					websocketServiceUrls = append(websocketServiceUrls,
+						formatServiceURL(endpoint.DnsName, ServiceProtocolWebsocket, istioEnabled))
 				}
 				if pulsarCluster.Spec.Config.Protocols != nil {
 					if pulsarCluster.Spec.Config.Protocols.Kafka != nil && istioEnabled {
-						kafkaServiceUrls = append(kafkaServiceUrls, fmt.Sprintf("%s:9093", endpoint.DnsName))
+						kafkaServiceUrls = append(kafkaServiceUrls,
+							formatServiceURL(endpoint.DnsName, ServiceProtocolKafka, istioEnabled))
 					}
 					if pulsarCluster.Spec.Config.Protocols.Mqtt != nil {
-						mqttServiceUrls = append(mqttServiceUrls, fmt.Sprintf("mqtts://%s:8883", endpoint.DnsName))
+						mqttServiceUrls = append(mqttServiceUrls,
+							formatServiceURL(endpoint.DnsName, ServiceProtocolMQTT, istioEnabled))
 					}
 				}
 			}
@@ -818,7 +744,7 @@ func resourcePulsarClusterRead(ctx context.Context, d *schema.ResourceData, meta
 		})
 		err = d.Set("config", flattenPulsarClusterConfig(pulsarCluster.Spec.Config))
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_READ_PULSAR_CLUSTER_CONFIG: %w", err))
+			return fmt.Errorf("ERROR_READ_PULSAR_CLUSTER_CONFIG: %w", err)
 		}
 	}
 
@@ -826,7 +752,7 @@ func resourcePulsarClusterRead(ctx context.Context, d *schema.ResourceData, meta
 	if pulsarCluster.Spec.MaintenanceWindow != nil {
 		err = d.Set("maintenance_window", flattenMaintenanceWindow(pulsarCluster.Spec.MaintenanceWindow))
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_READ_PULSAR_CLUSTER_MAINTENANCE_WINDOW: %w", err))
+			return fmt.Errorf("ERROR_READ_PULSAR_CLUSTER_MAINTENANCE_WINDOW: %w", err)
 		}
 	} else {
 		_ = d.Set("maintenance_window", []interface{}{})
@@ -872,7 +798,7 @@ func resourcePulsarClusterRead(ctx context.Context, d *schema.ResourceData, meta
 		// Get catalog information
 		catalog, err := clientSet.CloudV1alpha1().Catalogs(namespace).Get(ctx, catalogName, metav1.GetOptions{})
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_GET_CATALOG: %w", err))
+			return fmt.Errorf("ERROR_GET_CATALOG: %w", err)
 		}
 
 		var accountID string
@@ -881,7 +807,7 @@ func resourcePulsarClusterRead(ctx context.Context, d *schema.ResourceData, meta
 			// Validate region match
 			if err := validateCatalogRegionMatch(
 				ctx, clientSet, namespace, catalogName, pulsarCluster.Spec.Location); err != nil {
-				return diag.FromErr(err)
+				return err
 			}
 			// Try to get account ID from pool options using instance pool information
 			if pulsarCluster.Spec.PoolMemberRef.Name != "" || pulsarCluster.Spec.Location != "" {
@@ -913,70 +839,30 @@ func resourcePulsarClusterRead(ctx context.Context, d *schema.ResourceData, meta
 		_ = d.Set("iam_policy", "")
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s", pulsarCluster.Namespace, pulsarCluster.Name))
 	return nil
 }
 
-func resourcePulsarClusterUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+// expandPulsarClusterUpdate applies the changes of the pulsar cluster to the object read from the API
+// server, the attributes which replace the cluster can't be updated
+func expandPulsarClusterUpdate(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, pulsarCluster *cloudv1alpha1.PulsarCluster,
+) error {
 	serverless := d.Get("type")
-	displayNameChanged := d.HasChange("display_name")
-	lakehouseStorageChanged := d.HasChange("lakehouse_storage_enabled")
-
+	namespace := pulsarCluster.Namespace
 	// For serverless clusters, lakehouse_storage_enabled is computed and cannot be changed
-	if serverless == string(cloudv1alpha1.PulsarInstanceTypeServerless) {
-		if lakehouseStorageChanged {
-			return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: " +
-				"lakehouse_storage_enabled cannot be set for serverless pulsar cluster, it is automatically computed"))
-		}
-		// Always set to true for serverless clusters
-		_ = d.Set("lakehouse_storage_enabled", true)
-	}
-	if d.HasChange("organization") {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: " +
-			"The pulsar cluster organization does not support updates"))
-	}
-	if d.HasChange("name") {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: " +
-			"The pulsar cluster name does not support updates"))
-	}
-	if d.HasChange("instance_name") {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: " +
-			"The pulsar cluster instance_name does not support updates"))
-	}
-	if d.HasChange("location") {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: " +
-			"The pulsar cluster location does not support updates"))
-	}
-	if d.HasChange("release_channel") {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: " +
-			"The pulsar cluster release channel does not support updates"))
-	}
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	if d.Get("type") == cloudv1alpha1.PulsarInstanceTypeServerless {
-		organizationCluster := strings.Split(d.Id(), "/")
-		namespace = organizationCluster[0]
-		name = organizationCluster[1]
-	}
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_PULSAR_CLUSTER: %w", err))
-	}
-	if name == "" {
-		organizationCluster := strings.Split(d.Id(), "/")
-		name = organizationCluster[1]
-		namespace = organizationCluster[0]
+	if serverless == string(cloudv1alpha1.PulsarInstanceTypeServerless) && d.HasChange("lakehouse_storage_enabled") {
+		return fmt.Errorf("lakehouse_storage_enabled cannot be set for serverless pulsar cluster, it is automatically computed")
 	}
-	pulsarCluster, err := clientSet.CloudV1alpha1().PulsarClusters(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_READ_PULSAR_CLUSTER: %w", err))
+	for _, key := range []string{"organization", "name", "instance_name", "location", "volume", "release_channel"} {
+		if d.HasChange(key) {
+			return fmt.Errorf("The pulsar cluster %s does not support updates", key)
+		}
 	}
-
 	// Validate lakehouse_storage_enabled update: once enabled, cannot be disabled
 	// For serverless clusters, skip validation as it's computed
 	if serverless != string(cloudv1alpha1.PulsarInstanceTypeServerless) {
-		if diagErr := validateLakehouseStorageUpdate(d, pulsarCluster); diagErr != nil {
-			return diagErr
+		if err := validateLakehouseStorageUpdate(d, pulsarCluster); err != nil {
+			return err
 		}
 	} else {
 		// For serverless clusters, ensure lakehouse storage is enabled
@@ -996,24 +882,21 @@ func resourcePulsarClusterUpdate(ctx context.Context, d *schema.ResourceData, me
 		pulsarCluster.Spec.Broker.Replicas = &brokerReplicas
 	}
 	if d.HasChange("compute_unit") || d.HasChange("compute_unit_per_broker") {
-		computeUnit := getComputeUnit(d)
-		pulsarCluster.Spec.Broker.Resources.Cpu = resource.NewMilliQuantity(
-			int64(computeUnit*2*1000), resource.DecimalSI)
-		pulsarCluster.Spec.Broker.Resources.Memory = resource.NewQuantity(
-			int64(computeUnit*8*1024*1024*1024), resource.DecimalSI)
+		pulsarCluster.Spec.Broker.Resources.Cpu, pulsarCluster.Spec.Broker.Resources.Memory =
+			convertUnitToCpuAndMemory(getComputeUnit(d))
 	}
 	if d.HasChange("storage_unit") || d.HasChange("storage_unit_per_bookie") {
-		storageUnit := getStorageUnit(d)
-		pulsarCluster.Spec.BookKeeper.Resources.Cpu = resource.NewMilliQuantity(
-			int64(storageUnit*2*1000), resource.DecimalSI)
-		pulsarCluster.Spec.BookKeeper.Resources.Memory = resource.NewQuantity(
-			int64(storageUnit*8*1024*1024*1024), resource.DecimalSI)
-	}
-	changed := getPulsarClusterChanged(ctx, pulsarCluster, d)
-	if displayNameChanged {
+		pulsarCluster.Spec.BookKeeper.Resources.Cpu, pulsarCluster.Spec.BookKeeper.Resources.Memory =
+			convertUnitToCpuAndMemory(getStorageUnit(d))
+	}
+	getPulsarClusterChanged(ctx, pulsarCluster, d)
+	if d.HasChange("display_name") {
 		displayName := d.Get("display_name").(string)
 		pulsarCluster.Spec.DisplayName = displayName
 	}
+	if d.HasChange("endpoint_access") {
+		pulsarCluster.Spec.EndpointAccess = convertEndpointAccess(d.Get("endpoint_access"))
+	}
 
 	// Handle catalog configuration changes
 	if d.HasChange("catalog") {
@@ -1021,7 +904,7 @@ func resourcePulsarClusterUpdate(ctx context.Context, d *schema.ResourceData, me
 		if catalogName != "" {
 			// Validate catalog configuration
 			if err := validateCatalogConfiguration(ctx, clientSet, namespace, catalogName, pulsarCluster.Spec.Location); err != nil {
-				return diag.FromErr(err)
+				return err
 			}
 			// Add catalog to the cluster
 			pulsarCluster.Spec.Catalogs = []string{catalogName}
@@ -1029,7 +912,6 @@ func resourcePulsarClusterUpdate(ctx context.Context, d *schema.ResourceData, me
 			// Remove catalog
 			pulsarCluster.Spec.Catalogs = nil
 		}
-		changed = true
 	}
 
 	// Handle table format determination when catalog or lakehouse storage changes
@@ -1040,15 +922,13 @@ func resourcePulsarClusterUpdate(ctx context.Context, d *schema.ResourceData, me
 		// Determine table format based on catalog (lakehouse storage is always enabled for serverless)
 		tableFormat, err := determineTableFormat(ctx, clientSet, namespace, catalogName)
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_DETERMINE_TABLE_FORMAT: %w", err))
+			return fmt.Errorf("ERROR_DETERMINE_TABLE_FORMAT: %w", err)
 		}
 		pulsarCluster.Spec.TableFormat = tableFormat
-		changed = true
 	}
 
 	if d.Get("apply_lakehouse_to_all_topics").(bool) && pulsarCluster.IsUsingUrsaEngine() {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: " +
-			"you don't set this apply_lakehouse_to_all_topics option for ursa engine cluster"))
+		return fmt.Errorf("you don't set this apply_lakehouse_to_all_topics option for ursa engine cluster")
 	}
 	// Handle SDT annotation based on apply_lakehouse_to_all_topics
 	if d.HasChange("apply_lakehouse_to_all_topics") || d.HasChange("catalog") || d.HasChange("lakehouse_storage_enabled") {
@@ -1063,112 +943,33 @@ func resourcePulsarClusterUpdate(ctx context.Context, d *schema.ResourceData, me
 				delete(pulsarCluster.Annotations, "cloud.streamnative.io/sdt-enabled")
 			}
 		}
-		changed = true
 	}
 
-	// Update IAM policy if catalog changes
-	if d.HasChange("catalog") {
-		catalogName := d.Get("catalog").(string)
-		if catalogName != "" {
-			// Try to get account ID from pool options using instance pool information
-			var accountID string
-			// Get pulsar instance to access pool information
-			pulsarInstance, err := clientSet.CloudV1alpha1().PulsarInstances(namespace).Get(ctx, pulsarCluster.Spec.InstanceName, metav1.GetOptions{})
-			if err != nil {
-				tflog.Warn(ctx, fmt.Sprintf("Failed to get pulsar instance: %v", err))
-			} else if pulsarCluster.Spec.PoolMemberRef.Name != "" || pulsarCluster.Spec.Location != "" {
-				accountIDFromPool, err := getAccountIDFromPoolOptions(ctx,
-					clientSet,
-					pulsarCluster.Namespace,
-					fmt.Sprintf("%s-%s", pulsarInstance.Spec.PoolRef.Namespace, pulsarInstance.Spec.PoolRef.Name),
-					pulsarCluster.Spec.Location,
-					pulsarCluster.Spec.PoolMemberRef.Name)
-				if err != nil {
-					tflog.Warn(ctx, fmt.Sprintf("Failed to get account ID from pool options: %v", err))
-				} else {
-					accountID = accountIDFromPool
-				}
-			}
-
-			// Get S3Table warehouse
-			s3TableWarehouse, err := getS3TableWarehouse(ctx, clientSet, namespace, catalogName)
-			if err != nil {
-				tflog.Warn(ctx, fmt.Sprintf("Failed to get S3Table warehouse: %v", err))
-			}
-
-			iamPolicy := generateIAMPolicy(namespace, name, catalogName, accountID, s3TableWarehouse)
-			_ = d.Set("iam_policy", iamPolicy)
-		} else {
-			_ = d.Set("iam_policy", "")
-		}
-	}
+	return nil
+}
 
-	if d.HasChange("bookie_replicas") ||
-		d.HasChange("broker_replicas") ||
-		d.HasChange("compute_unit") ||
-		d.HasChange("storage_unit") ||
-		d.HasChange("compute_unit_per_broker") ||
-		d.HasChange("storage_unit_per_bookie") || changed || displayNameChanged {
-		_, err = clientSet.CloudV1alpha1().PulsarClusters(namespace).Update(ctx, pulsarCluster, metav1.UpdateOptions{
-			FieldManager: "terraform-update",
-		})
-		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: %w", err))
-		}
-		// Delay 10 seconds to wait for api server start reconcile.
-		time.Sleep(10 * time.Second)
-		err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), func() *retry.RetryError {
-			dia := resourcePulsarClusterRead(ctx, d, meta)
-			if dia.HasError() {
-				return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_READ_PULSAR_CLUSTER: %s", dia[0].Summary))
-			}
-			ready := d.Get("ready")
-			if ready == "False" {
-				return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_READ_PULSAR_CLUSTER"))
-			}
-			return nil
-		})
-		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_RETRY_READ_PULSAR_CLUSTER: %w", err))
+// pulsarClusterReady reports whether the cluster is ready at its current generation, the Ready condition
+// of the previous generation is reported until the update is reconciled. The condition without an
+// observed generation falls back to the Ready status
+func pulsarClusterReady(pulsarCluster *cloudv1alpha1.PulsarCluster) bool {
+	for _, condition := range pulsarCluster.Status.Conditions {
+		if condition.Type == "Ready" && condition.Status == "True" &&
+			(condition.ObservedGeneration == 0 || condition.ObservedGeneration >= pulsarCluster.Generation) {
+			return true
 		}
 	}
-	return nil
+	return false
 }
 
-func resourcePulsarClusterDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_DELETE_PULSAR_CLUSTER: %w", err))
-	}
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	if name == "" {
-		organizationCluster := strings.Split(d.Id(), "/")
-		name = organizationCluster[1]
-		namespace = organizationCluster[0]
-	}
-	err = clientSet.CloudV1alpha1().PulsarClusters(namespace).Delete(ctx, name, metav1.DeleteOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_DELETE_PULSAR_CLUSTER: %w", err))
-	}
-	err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), func() *retry.RetryError {
-		_, err = clientSet.CloudV1alpha1().PulsarClusters(namespace).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			if statusErr, ok := err.(*errors.StatusError); ok && errors.IsNotFound(statusErr) {
-				return nil
-			}
-			return retry.NonRetryableError(err)
+// pulsarClusterReadyStatus returns the status of the Ready condition, it's 'False' until the
+// condition is reported by the controller
+func pulsarClusterReadyStatus(pulsarCluster *cloudv1alpha1.PulsarCluster) string {
+	for _, condition := range pulsarCluster.Status.Conditions {
+		if condition.Type == "Ready" {
+			return string(condition.Status)
 		}
-
-		e := fmt.Errorf("pulsarcluster (%s) still exists", d.Id())
-		return retry.RetryableError(e)
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_READ_PULSAR_CLUSTER: %w", err))
 	}
-
-	d.SetId("")
-	return nil
+	return "False"
 }
 
 func getPulsarClusterChanged(ctx context.Context, pulsarCluster *cloudv1alpha1.PulsarCluster, d *schema.ResourceData) bool {
@@ -1376,24 +1177,62 @@ func getStorageUnit(d *schema.ResourceData) float64 {
 	return storageUnit
 }
 
+// convertUnitToCpuAndMemory converts the compute unit or the storage unit to the resources,
+// one unit is 2 CPU and 8 GiB memory, the resources are rounded instead of truncated so 1.005
+// unit isn't converted to 2009m CPU
+func convertUnitToCpuAndMemory(unit float64) (*resource.Quantity, *resource.Quantity) {
+	return resource.NewMilliQuantity(int64(math.Round(unit*2*1000)), resource.DecimalSI),
+		resource.NewQuantity(int64(math.Round(unit*8*1024*1024*1024)), resource.DecimalSI)
+}
+
 func convertCpuAndMemoryToComputeUnit(pc *cloudv1alpha1.PulsarCluster) float64 {
 	if pc != nil && pc.Spec.Broker.Resources != nil {
-		cpu := pc.Spec.Broker.Resources.Cpu.MilliValue()
-		memory := pc.Spec.Broker.Resources.Memory.Value()
-		return math.Max(float64(cpu)/2/1000, float64(memory)/(8*1024*1024*1024))
+		return convertResourcesToUnit(pc.Spec.Broker.Resources)
 	}
 	return 0.5 // default value
 }
 
 func convertCpuAndMemoryToStorageUnit(pc *cloudv1alpha1.PulsarCluster) float64 {
 	if pc != nil && pc.Spec.BookKeeper != nil && pc.Spec.BookKeeper.Resources != nil {
-		cpu := pc.Spec.BookKeeper.Resources.Cpu.MilliValue()
-		memory := pc.Spec.BookKeeper.Resources.Memory.Value()
-		return math.Max(float64(cpu)/2/1000, float64(memory)/(8*1024*1024*1024))
+		return convertResourcesToUnit(&pc.Spec.BookKeeper.Resources.DefaultNodeResource)
 	}
 	return 0.5 // default value
 }
 
+// convertResourcesToUnit converts the resources back to the unit, the unit is rounded to the
+// thousandth so the resources converted from a unit always convert back to the same unit
+func convertResourcesToUnit(resources *cloudv1alpha1.DefaultNodeResource) float64 {
+	var cpuUnit, memoryUnit float64
+	if resources.Cpu != nil {
+		cpuUnit = float64(resources.Cpu.MilliValue()) / (2 * 1000)
+	}
+	if resources.Memory != nil {
+		memoryUnit = float64(resources.Memory.Value()) / (8 * 1024 * 1024 * 1024)
+	}
+	return math.Round(math.Max(cpuUnit, memoryUnit)*1000) / 1000
+}
+
+// setPulsarClusterSpec sets the attributes which are read back from the spec of the pulsar cluster,
+// the name isn't set as it's generated by the API server when it's not configured
+func setPulsarClusterSpec(d *schema.ResourceData, pulsarCluster *cloudv1alpha1.PulsarCluster) {
+	_ = d.Set("instance_name", pulsarCluster.Spec.InstanceName)
+	_ = d.Set("display_name", pulsarCluster.Spec.DisplayName)
+	_ = d.Set("location", pulsarCluster.Spec.Location)
+	_ = d.Set("pool_member_name", pulsarCluster.Spec.PoolMemberRef.Name)
+	if pulsarCluster.Spec.Broker.Replicas != nil {
+		_ = d.Set("broker_replicas", int(*pulsarCluster.Spec.Broker.Replicas))
+	}
+	if pulsarCluster.Spec.BookKeeper != nil && pulsarCluster.Spec.BookKeeper.Replicas != nil {
+		_ = d.Set("bookie_replicas", int(*pulsarCluster.Spec.BookKeeper.Replicas))
+	}
+	if pulsarCluster.Spec.Volume != nil {
+		_ = d.Set("volume", pulsarCluster.Spec.Volume.Name)
+	} else {
+		_ = d.Set("volume", "")
+	}
+	_ = d.Set("endpoint_access", flattenEndpointAccess(pulsarCluster.Spec.EndpointAccess))
+}
+
 // makeLakehouseStorageComputedForServerless makes lakehouse_storage_enabled computed for serverless clusters
 func makeLakehouseStorageComputedForServerless(ctx context.Context, diff *schema.ResourceDiff, meta interface{}) {
 	// Get instance information to check type
@@ -1433,7 +1272,7 @@ func makeLakehouseStorageComputedForServerless(ctx context.Context, diff *schema
 }
 
 // determineTableFormat determines the table format based on catalog type and configuration
-func determineTableFormat(ctx context.Context, cloudClientSet *cloudclient.Clientset, namespace, catalogName string) (string, error) {
+func determineTableFormat(ctx context.Context, cloudClientSet cloudclient.Interface, namespace, catalogName string) (string, error) {
 
 	// If no catalog is specified, return "none"
 	if catalogName == "" {
@@ -1483,7 +1322,7 @@ func shouldApplyLakehouseToAllTopics(d *schema.ResourceData) bool {
 }
 
 // validateCatalogConfiguration validates catalog configuration for the cluster
-func validateCatalogConfiguration(ctx context.Context, cloudClientSet *cloudclient.Clientset, namespace, catalogName, clusterLocation string) error {
+func validateCatalogConfiguration(ctx context.Context, cloudClientSet cloudclient.Interface, namespace, catalogName, clusterLocation string) error {
 	// Get catalog information
 	catalog, err := cloudClientSet.CloudV1alpha1().Catalogs(namespace).Get(ctx, catalogName, metav1.GetOptions{})
 	if err != nil {
@@ -1502,7 +1341,7 @@ func validateCatalogConfiguration(ctx context.Context, cloudClientSet *cloudclie
 }
 
 // validateCatalogRegionMatch validates that S3Table catalog region matches cluster location
-func validateCatalogRegionMatch(ctx context.Context, cloudClientSet *cloudclient.Clientset, namespace, catalogName, clusterLocation string) error {
+func validateCatalogRegionMatch(ctx context.Context, cloudClientSet cloudclient.Interface, namespace, catalogName, clusterLocation string) error {
 	// Get catalog information
 	catalog, err := cloudClientSet.CloudV1alpha1().Catalogs(namespace).Get(ctx, catalogName, metav1.GetOptions{})
 	if err != nil {
@@ -1530,7 +1369,7 @@ func validateCatalogRegionMatch(ctx context.Context, cloudClientSet *cloudclient
 
 // getAccountIDFromPoolOptions retrieves the account ID from PoolOptions API
 func getAccountIDFromPoolOptions(ctx context.Context,
-	cloudClientSet *cloudclient.Clientset,
+	cloudClientSet cloudclient.Interface,
 	namespace, poolName,
 	location, poolmemberName string) (string, error) {
 
@@ -1613,7 +1452,7 @@ func generateIAMPolicy(organization, clusterName, catalogName string, accountID
 }
 
 // getS3TableWarehouse retrieves the warehouse field from S3Table catalog
-func getS3TableWarehouse(ctx context.Context, cloudClientSet *cloudclient.Clientset, namespace, catalogName string) (string, error) {
+func getS3TableWarehouse(ctx context.Context, cloudClientSet cloudclient.Interface, namespace, catalogName string) (string, error) {
 	if catalogName == "" {
 		return "", nil
 	}
@@ -1633,7 +1472,7 @@ func getS3TableWarehouse(ctx context.Context, cloudClientSet *cloudclient.Client
 }
 
 // validateLakehouseStorageUpdate validates that lakehouse_storage_enabled cannot be disabled once enabled
-func validateLakehouseStorageUpdate(d *schema.ResourceData, pulsarCluster *cloudv1alpha1.PulsarCluster) diag.Diagnostics {
+func validateLakehouseStorageUpdate(d *schema.ResourceData, pulsarCluster *cloudv1alpha1.PulsarCluster) error {
 	if d.HasChange("lakehouse_storage_enabled") {
 		newEnabled := d.Get("lakehouse_storage_enabled").(bool)
 		// Check if lakehouse storage was previously enabled
@@ -1643,8 +1482,8 @@ func validateLakehouseStorageUpdate(d *schema.ResourceData, pulsarCluster *cloud
 			*pulsarCluster.Spec.Config.LakehouseStorage.Enabled {
 			// If it was enabled and trying to set to false, reject the update
 			if !newEnabled {
-				return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: " +
-					"lakehouse_storage_enabled cannot be disabled once it has been enabled"))
+				return fmt.Errorf("ERROR_UPDATE_PULSAR_CLUSTER: " +
+					"lakehouse_storage_enabled cannot be disabled once it has been enabled")
 			}
 		}
 	}
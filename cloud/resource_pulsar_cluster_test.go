@@ -0,0 +1,311 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
+)
+
+func TestConvertUnitToCpuAndMemory(t *testing.T) {
+	cpu, memory := convertUnitToCpuAndMemory(1.005)
+	assert.Equal(t, "2010m", cpu.String())
+	assert.Equal(t, int64(8632884265), memory.Value())
+
+	cpu, memory = convertUnitToCpuAndMemory(2)
+	assert.Equal(t, "4", cpu.String())
+	assert.Equal(t, int64(8*1024*1024*1024), memory.Value())
+}
+
+func TestConvertUnitRoundTrip(t *testing.T) {
+	for i := 200; i <= 8000; i += 5 {
+		unit := float64(i) / 1000
+		brokerCPU, brokerMemory := convertUnitToCpuAndMemory(unit)
+		bookieCPU, bookieMemory := convertUnitToCpuAndMemory(unit)
+		pc := &cloudv1alpha1.PulsarCluster{
+			Spec: cloudv1alpha1.PulsarClusterSpec{
+				Broker: cloudv1alpha1.Broker{
+					Resources: &cloudv1alpha1.DefaultNodeResource{Cpu: brokerCPU, Memory: brokerMemory},
+				},
+				BookKeeper: &cloudv1alpha1.BookKeeper{
+					Resources: &cloudv1alpha1.BookkeeperNodeResource{
+						DefaultNodeResource: cloudv1alpha1.DefaultNodeResource{Cpu: bookieCPU, Memory: bookieMemory},
+					},
+				},
+			},
+		}
+		assert.Equal(t, unit, convertCpuAndMemoryToComputeUnit(pc), "compute unit %v", unit)
+		assert.Equal(t, unit, convertCpuAndMemoryToStorageUnit(pc), "storage unit %v", unit)
+	}
+	assert.Equal(t, 0.5, convertCpuAndMemoryToComputeUnit(nil))
+	assert.Equal(t, 0.5, convertCpuAndMemoryToStorageUnit(&cloudv1alpha1.PulsarCluster{}))
+}
+
+func TestSetPulsarClusterSpec(t *testing.T) {
+	pc := &cloudv1alpha1.PulsarCluster{
+		Spec: cloudv1alpha1.PulsarClusterSpec{
+			InstanceName:  "test-instance",
+			DisplayName:   "Test Cluster",
+			Location:      "us-central1",
+			PoolMemberRef: cloudv1alpha1.PoolMemberReference{Name: "gcp-shared-usce1", Namespace: "streamnative"},
+			Broker:        cloudv1alpha1.Broker{Replicas: pointer.Int32(3)},
+			BookKeeper:    &cloudv1alpha1.BookKeeper{Replicas: pointer.Int32(5)},
+			Volume:        &cloudv1alpha1.VolumeReference{Name: "test-volume"},
+			EndpointAccess: []cloudv1alpha1.EndpointAccess{
+				{Gateway: "default"},
+				{Gateway: "test-gateway"},
+			},
+		},
+	}
+	d := resourcePulsarCluster().TestResourceData()
+	setPulsarClusterSpec(d, pc)
+	assert.Equal(t, "test-instance", d.Get("instance_name"))
+	assert.Equal(t, "Test Cluster", d.Get("display_name"))
+	assert.Equal(t, "us-central1", d.Get("location"))
+	assert.Equal(t, "gcp-shared-usce1", d.Get("pool_member_name"))
+	assert.Equal(t, 3, d.Get("broker_replicas"))
+	assert.Equal(t, 5, d.Get("bookie_replicas"))
+	assert.Equal(t, "test-volume", d.Get("volume"))
+	assert.Equal(t, pc.Spec.EndpointAccess, convertEndpointAccess(d.Get("endpoint_access")))
+
+	pc.Spec.Volume = nil
+	pc.Spec.EndpointAccess = nil
+	setPulsarClusterSpec(d, pc)
+	assert.Equal(t, "", d.Get("volume"))
+	assert.Empty(t, convertEndpointAccess(d.Get("endpoint_access")))
+}
+
+func TestPulsarClusterCreateValidation(t *testing.T) {
+	serverless := &cloudv1alpha1.PulsarInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "serverless", Namespace: "sndev"},
+		Spec: cloudv1alpha1.PulsarInstanceSpec{
+			Type:    cloudv1alpha1.PulsarInstanceTypeServerless,
+			PoolRef: &cloudv1alpha1.PoolRef{Name: "shared-aws", Namespace: "streamnative"},
+		},
+	}
+	ursa := &cloudv1alpha1.PulsarInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ursa",
+			Namespace:   "sndev",
+			Annotations: map[string]string{UrsaEngineAnnotation: UrsaEngineValue},
+		},
+		Spec: cloudv1alpha1.PulsarInstanceSpec{
+			PoolRef: &cloudv1alpha1.PoolRef{Name: "shared-aws", Namespace: "streamnative"},
+		},
+	}
+	dedicated := &cloudv1alpha1.PulsarInstance{
+		ObjectMeta: metav1.ObjectMeta{Name: "dedicated", Namespace: "sndev"},
+		Spec: cloudv1alpha1.PulsarInstanceSpec{
+			PoolRef: &cloudv1alpha1.PoolRef{Name: "shared-aws", Namespace: "streamnative"},
+		},
+	}
+	poolMember := &cloudv1alpha1.PoolMember{
+		ObjectMeta: metav1.ObjectMeta{Name: "byoc-aws", Namespace: "sndev"},
+		Spec:       cloudv1alpha1.PoolMemberSpec{PoolName: "byoc"},
+	}
+	s3TableCatalog := &cloudv1alpha1.Catalog{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3table", Namespace: "sndev"},
+		Spec: cloudv1alpha1.CatalogSpec{
+			S3Table: &cloudv1alpha1.Iceberg{
+				Warehouse: "arn:aws:s3tables:ap-northeast-1:577003581484:bucket/s3-table-test",
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		config map[string]interface{}
+		err    string
+	}{
+		{
+			name:   "missing instance",
+			config: map[string]interface{}{"instance_name": "missing", "location": "us-east-1"},
+			err:    "ERROR_GET_PULSAR_INSTANCE_ON_CREATE_PULSAR_CLUSTER",
+		},
+		{
+			name:   "missing location",
+			config: map[string]interface{}{"instance_name": "dedicated"},
+			err:    "either pool_member_name or location must be provided",
+		},
+		{
+			name: "serverless compute unit",
+			config: map[string]interface{}{
+				"instance_name": "serverless", "location": "us-east-1", "compute_unit_per_broker": 1.0,
+			},
+			err: "compute_unit must be 0.5 for serverless instance",
+		},
+		{
+			name:   "serverless broker replicas",
+			config: map[string]interface{}{"instance_name": "serverless", "location": "us-east-1", "broker_replicas": 3},
+			err:    "broker_replicas must be 2 for serverless instance",
+		},
+		{
+			name:   "ursa release channel",
+			config: map[string]interface{}{"instance_name": "ursa", "location": "us-east-1", "release_channel": "lts"},
+			err:    "release_channel must be rapid for ursa engine or serverless instance",
+		},
+		{
+			name: "ursa lakehouse storage",
+			config: map[string]interface{}{
+				"instance_name": "ursa", "location": "us-east-1", "lakehouse_storage_enabled": true,
+			},
+			err: "you don't set this option for ursa engine cluster",
+		},
+		{
+			name:   "pool member of another pool",
+			config: map[string]interface{}{"instance_name": "dedicated", "pool_member_name": "byoc-aws"},
+			err:    "the pool member does not belong to the pool which pulsar instance is attached",
+		},
+		{
+			name: "s3table catalog in another region",
+			config: map[string]interface{}{
+				"instance_name": "dedicated", "location": "us-east-1", "catalog": "s3table",
+			},
+			err: "You can only select a catalog in the same region (us-east-1) as this cluster",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			meta, clientSet := newFakeProviderMeta(serverless, ursa, dedicated, poolMember, s3TableCatalog)
+			tc.config["organization"] = "sndev"
+			d := schema.TestResourceDataRaw(t, resourcePulsarCluster().Schema, tc.config)
+			diags := pulsarClusterEngine.create(context.Background(), d, meta)
+			assert.True(t, diags.HasError())
+			assert.Contains(t, diags[0].Summary, tc.err)
+			for _, action := range clientSet.Actions() {
+				assert.NotEqual(t, "create", action.GetVerb(), action.GetResource().Resource)
+			}
+		})
+	}
+}
+
+func TestPulsarClusterCatalog(t *testing.T) {
+	catalogs := []runtime.Object{
+		&cloudv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "s3table", Namespace: "sndev"},
+			Spec: cloudv1alpha1.CatalogSpec{
+				S3Table: &cloudv1alpha1.Iceberg{
+					Warehouse: "arn:aws:s3tables:ap-northeast-1:577003581484:bucket/s3-table-test",
+				},
+			},
+		},
+		&cloudv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "unity-delta", Namespace: "sndev"},
+			Spec: cloudv1alpha1.CatalogSpec{
+				Unity: &cloudv1alpha1.Unity{
+					CatalogConnection: cloudv1alpha1.CatalogConnection{URI: "https://dbc.cloud.databricks.com"},
+				},
+			},
+		},
+		&cloudv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "unity-iceberg", Namespace: "sndev"},
+			Spec: cloudv1alpha1.CatalogSpec{
+				Unity: &cloudv1alpha1.Unity{
+					CatalogConnection: cloudv1alpha1.CatalogConnection{
+						URI: "https://dbc.cloud.databricks.com/api/2.1/unity-catalog/iceberg-rest",
+					},
+				},
+			},
+		},
+		&cloudv1alpha1.Catalog{
+			ObjectMeta: metav1.ObjectMeta{Name: "open-catalog", Namespace: "sndev"},
+			Spec: cloudv1alpha1.CatalogSpec{
+				OpenCatalog: &cloudv1alpha1.Iceberg{Warehouse: "warehouse"},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		catalog     string
+		tableFormat string
+		warehouse   string
+		// regionErr is whether the catalog can't be used by a cluster in ap-southeast-1
+		regionErr bool
+	}{
+		{catalog: "", tableFormat: "none"},
+		{
+			catalog:     "s3table",
+			tableFormat: "iceberg",
+			warehouse:   "arn:aws:s3tables:ap-northeast-1:577003581484:bucket/s3-table-test",
+			regionErr:   true,
+		},
+		{catalog: "unity-delta", tableFormat: "delta"},
+		{catalog: "unity-iceberg", tableFormat: "iceberg"},
+		{catalog: "open-catalog", tableFormat: "iceberg"},
+	} {
+		t.Run(tc.catalog, func(t *testing.T) {
+			meta, _ := newFakeProviderMeta(catalogs...)
+			clientSet, err := getClientSet(meta)
+			assert.NoError(t, err)
+			tableFormat, err := determineTableFormat(ctx, clientSet, "sndev", tc.catalog)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.tableFormat, tableFormat)
+			warehouse, err := getS3TableWarehouse(ctx, clientSet, "sndev", tc.catalog)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.warehouse, warehouse)
+			if tc.catalog == "" {
+				return
+			}
+			assert.NoError(t, validateCatalogConfiguration(ctx, clientSet, "sndev", tc.catalog, "ap-northeast-1"))
+			err = validateCatalogConfiguration(ctx, clientSet, "sndev", tc.catalog, "ap-southeast-1")
+			assert.Equal(t, tc.regionErr, err != nil, err)
+		})
+	}
+
+	// The errors of the API server are returned
+	meta, fakeClientSet := newFakeProviderMeta(catalogs...)
+	fakeClientSet.PrependReactor("get", "catalogs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("the server is unavailable")
+	})
+	clientSet, err := getClientSet(meta)
+	assert.NoError(t, err)
+	_, err = determineTableFormat(ctx, clientSet, "sndev", "s3table")
+	assert.ErrorContains(t, err, "ERROR_GET_CATALOG")
+	err = validateCatalogRegionMatch(ctx, clientSet, "sndev", "s3table", "ap-northeast-1")
+	assert.ErrorContains(t, err, "ERROR_GET_CATALOG")
+}
+
+func TestPulsarClusterReady(t *testing.T) {
+	for _, test := range []struct {
+		status             string
+		observedGeneration int64
+		ready              bool
+	}{
+		{"True", 2, true},
+		{"True", 1, false},
+		{"False", 2, false},
+		// The API server may not report the observed generation
+		{"True", 0, true},
+		{"False", 0, false},
+	} {
+		pulsarCluster := &cloudv1alpha1.PulsarCluster{ObjectMeta: metav1.ObjectMeta{Generation: test.observedGeneration}}
+		assert.NoError(t, setTestReadyCondition(pulsarCluster, test.status))
+		pulsarCluster.Generation = 2
+		assert.Equal(t, test.ready, pulsarClusterReady(pulsarCluster), test)
+		pulsarGateway := &cloudv1alpha1.PulsarGateway{ObjectMeta: metav1.ObjectMeta{Generation: test.observedGeneration}}
+		assert.NoError(t, setTestReadyCondition(pulsarGateway, test.status))
+		pulsarGateway.Generation = 2
+		assert.Equal(t, test.ready, pulsarGatewayReady(pulsarGateway), test)
+	}
+}
@@ -17,14 +17,10 @@ package cloud
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/streamnative/cloud-api-server/pkg/apis/cloud"
@@ -34,10 +30,11 @@ import (
 
 func resourcePulsarGateway() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourcePulsarGatewayCreate,
-		ReadContext:   resourcePulsarGatewayRead,
-		UpdateContext: resourcePulsarGatewayUpdate,
-		DeleteContext: resourcePulsarGatewayDelete,
+		CreateContext: withResourceIdentity(pulsarGatewayEngine.create, organizationNameIdentity),
+		ReadContext:   withResourceIdentity(pulsarGatewayEngine.read, organizationNameIdentity),
+		UpdateContext: withResourceIdentity(pulsarGatewayEngine.update, organizationNameIdentity),
+		DeleteContext: pulsarGatewayEngine.delete,
+		Identity:      newResourceIdentity(organizationNameIdentity),
 		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, i interface{}) error {
 			oldOrg, _ := diff.GetChange("organization")
 			oldName, _ := diff.GetChange("name")
@@ -45,25 +42,13 @@ func resourcePulsarGateway() *schema.Resource {
 				// This is create event, so we don't need to check the diff.
 				return nil
 			}
-			if diff.HasChanges("name", "access") {
-				return fmt.Errorf("ERROR_UPDATE_PULSAR_GATEWAY: " +
-					"The pulsar gateway does not support updates name and access, please recreate it")
-			}
-			return nil
-		},
-		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				organizationName := strings.Split(d.Id(), "/")
-				_ = d.Set("organization", organizationName[0])
-				_ = d.Set("name", organizationName[1])
-				err := dataSourcePulsarGatewayRead(ctx, d, meta)
-				if err.HasError() {
-					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
-				}
-				return []*schema.ResourceData{d}, nil
-			},
+			return forceNewIfAllowed(diff, fmt.Errorf("ERROR_UPDATE_PULSAR_GATEWAY: "+
+				"The pulsar gateway does not support updates name and access, please recreate it"),
+				"organization", "name", "access")
 		},
+		Importer: resourceImporter(pulsarGatewayEngine.read, resourcePulsarGateway),
 		Schema: map[string]*schema.Schema{
+			"allow_replacement": allowReplacementSchema(),
 			"organization": {
 				Type:         schema.TypeString,
 				Required:     true,
@@ -119,225 +104,87 @@ func resourcePulsarGateway() *schema.Resource {
 	}
 }
 
-func resourcePulsarGatewayCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+var pulsarGatewayEngine = &resourceEngine[*cloudv1alpha1.PulsarGateway]{
+	kind: "PULSAR_GATEWAY",
+	client: func(clientSet cloudclient.Interface, namespace string) resourceClient[*cloudv1alpha1.PulsarGateway] {
+		return clientSet.CloudV1alpha1().PulsarGateways(namespace)
+	},
+	newObject: func() *cloudv1alpha1.PulsarGateway {
+		return &cloudv1alpha1.PulsarGateway{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "PulsarGateway",
+				APIVersion: cloudv1alpha1.SchemeGroupVersion.String(),
+			},
+		}
+	},
+	expand:       expandPulsarGateway,
+	expandUpdate: expandPulsarGatewayUpdate,
+	flatten:      flattenPulsarGateway,
+	ready:        pulsarGatewayReady,
+}
+
+// expandPulsarGateway sets the gateway from the configuration, the pool member must exist in the organization
+func expandPulsarGateway(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, pulsarGateway *cloudv1alpha1.PulsarGateway,
+) error {
 	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
 	access := d.Get("access").(string)
 	poolMemberName := d.Get("pool_member_name").(string)
-	waitForCompletion := d.Get("wait_for_completion").(bool)
-
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
+	_, err := clientSet.CloudV1alpha1().PoolMembers(namespace).Get(ctx, poolMemberName, metav1.GetOptions{})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_PULSAR_GATEWAY: %w", err))
+		return fmt.Errorf("ERROR_GET_POOL_MEMBER_ON_CREATE_PULSAR_GATEWAY: %w", err)
 	}
-	_, err = clientSet.CloudV1alpha1().PoolMembers(namespace).Get(ctx, poolMemberName, metav1.GetOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_GET_POOL_MEMBER_ON_CREATE_PULSAR_GATEWAY: %w", err))
-	}
-	pulsarGateway := &cloudv1alpha1.PulsarGateway{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "PulsarGateway",
-			APIVersion: cloudv1alpha1.SchemeGroupVersion.String(),
+	pulsarGateway.Spec = cloudv1alpha1.PulsarGatewaySpec{
+		Gateway: cloudv1alpha1.Gateway{
+			Access: cloudv1alpha1.AccessType(access),
 		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
+		PoolMemberRef: cloudv1alpha1.PoolMemberReference{
 			Namespace: namespace,
-		},
-		Spec: cloudv1alpha1.PulsarGatewaySpec{
-			Gateway: cloudv1alpha1.Gateway{
-				Access: cloudv1alpha1.AccessType(access),
-			},
-			PoolMemberRef: cloudv1alpha1.PoolMemberReference{
-				Namespace: namespace,
-				Name:      poolMemberName,
-			},
+			Name:      poolMemberName,
 		},
 	}
 	if access == string(cloud.PrivateAccess) {
 		pulsarGateway.Spec.PrivateService = convertPrivateService(d.Get("private_service"))
 	}
-
-	pg, err := clientSet.CloudV1alpha1().PulsarGateways(namespace).Create(ctx, pulsarGateway, metav1.CreateOptions{
-		FieldManager: "terraform-create",
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_PULSAR_GATEWAY: %w", err))
-	}
-	ready := false
-	d.SetId(fmt.Sprintf("%s/%s", pg.ObjectMeta.Namespace, pg.ObjectMeta.Name))
-	if waitForCompletion {
-		err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutCreate), retryUntilPulsarGatewayIsReady(ctx, clientSet, namespace, pg.GetObjectMeta().GetName()))
-		if err != nil {
-			return diag.FromErr(err)
-		}
-	} else {
-		for _, condition := range pg.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				ready = true
-			}
-		}
-	}
-
-	if ready {
-		d.SetId(fmt.Sprintf("%s/%s", pg.ObjectMeta.Namespace, pg.ObjectMeta.Name))
-		return resourcePulsarGatewayRead(ctx, d, meta)
-	}
-	err = retry.RetryContext(ctx, 3*time.Minute, func() *retry.RetryError {
-		dia := resourcePulsarGatewayRead(ctx, d, meta)
-		if dia.HasError() {
-			return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_READ_PULSAR_GATEWAY: %s", dia[0].Summary))
-		}
-		return nil
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_READ_PULSAR_GATEWAY: %w", err))
-	}
 	return nil
 }
 
-func resourcePulsarGatewayRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := strings.Split(d.Id(), "/")[1]
-
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_PULSAR_GATEWAY: %w", err))
-	}
-
-	pg, err := clientSet.CloudV1alpha1().PulsarGateways(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			d.SetId("")
-			return nil
-		}
-		return diag.FromErr(fmt.Errorf("ERROR_READ_PULSAR_GATEWAY: %w", err))
-	}
-	d.SetId(fmt.Sprintf("%s/%s", pg.Namespace, pg.Name))
-	return nil
-}
-
-func resourcePulsarGatewayUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := strings.Split(d.Id(), "/")[1]
-	access := d.Get("access").(string)
-	waitForCompletion := d.Get("wait_for_completion").(bool)
-
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_UPDATE_PULSAR_GATEWAY: %w", err))
-	}
-	if access != string(cloud.PrivateAccess) || !d.HasChange("private_service") {
-		return nil
-	}
-	pg, err := clientSet.CloudV1alpha1().PulsarGateways(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_READ_PULSAR_GATEWAY: %w", err))
-	}
-
-	pg.Spec.PrivateService = convertPrivateService(d.Get("private_service"))
-
-	if _, err := clientSet.CloudV1alpha1().PulsarGateways(namespace).Update(ctx, pg, metav1.UpdateOptions{
-		FieldManager: "terraform-update",
-	}); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_GATEWAY: %w", err))
-	}
-
-	if waitForCompletion {
-		if err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), retryUntilPulsarGatewayIsUpdated(ctx, clientSet, namespace, name)); err != nil {
-			return diag.FromErr(err)
-		}
-		if err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutUpdate), retryUntilPulsarGatewayIsReady(ctx, clientSet, namespace, name)); err != nil {
-			return diag.FromErr(err)
-		}
+// expandPulsarGatewayUpdate sets the private service of the gateway, the other attributes replace the gateway
+func expandPulsarGatewayUpdate(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, pulsarGateway *cloudv1alpha1.PulsarGateway,
+) error {
+	if d.Get("access").(string) == string(cloud.PrivateAccess) && d.HasChange("private_service") {
+		pulsarGateway.Spec.PrivateService = convertPrivateService(d.Get("private_service"))
 	}
 	return nil
 }
 
-func resourcePulsarGatewayDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_DELETE_PULSAR_GATEWAY: %w", err))
+func flattenPulsarGateway(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, pulsarGateway *cloudv1alpha1.PulsarGateway,
+) error {
+	if err := d.Set("access", string(pulsarGateway.Spec.Access)); err != nil {
+		return fmt.Errorf("ERROR_SET_ACCESS: %w", err)
 	}
-	namespace := d.Get("organization").(string)
-	name := strings.Split(d.Id(), "/")[1]
-	waitForCompletion := d.Get("wait_for_completion").(bool)
-
-	err = clientSet.CloudV1alpha1().PulsarGateways(namespace).Delete(ctx, name, metav1.DeleteOptions{})
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return nil
-		}
-		return diag.FromErr(fmt.Errorf("DELETE_PULSAR_GATEWAY: %w", err))
+	if err := d.Set("pool_member_name", pulsarGateway.Spec.PoolMemberRef.Name); err != nil {
+		return fmt.Errorf("ERROR_SET_POOL_MEMBER_NAME: %w", err)
 	}
-
-	if waitForCompletion {
-		err = retry.RetryContext(ctx, d.Timeout(schema.TimeoutDelete), retryUntilPulsarGatewayIsDeleted(ctx, clientSet, namespace, name))
-		if err != nil {
-			return diag.FromErr(err)
+	if pulsarGateway.Spec.Access == cloudv1alpha1.AccessType(cloud.PrivateAccess) && pulsarGateway.Spec.PrivateService != nil {
+		if err := d.Set("private_service", flattenPrivateService(pulsarGateway.Spec.PrivateService)); err != nil {
+			return fmt.Errorf("ERROR_SET_PRIVATE_SERVICE: %w", err)
 		}
 	}
-
 	return nil
 }
 
-func retryUntilPulsarGatewayIsReady(ctx context.Context, clientSet *cloudclient.Clientset, ns string, name string) retry.RetryFunc {
-	return func() *retry.RetryError {
-		pg, err := clientSet.CloudV1alpha1().PulsarGateways(ns).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			if statusErr, ok := err.(*apierrors.StatusError); ok && apierrors.IsNotFound(statusErr) {
-				return nil
-			}
-			return retry.NonRetryableError(err)
-		}
-
-		for _, condition := range pg.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				return nil
-			}
-		}
-
-		//Sleep 10 seconds between checks so we don't overload the API
-		time.Sleep(time.Second * 10)
-
-		return retry.RetryableError(fmt.Errorf("pulsargateway: %s/%s is not in complete state", ns, name))
-	}
-}
-
-func retryUntilPulsarGatewayIsUpdated(ctx context.Context, clientSet *cloudclient.Clientset, ns string, name string) retry.RetryFunc {
-	return func() *retry.RetryError {
-		pg, err := clientSet.CloudV1alpha1().PulsarGateways(ns).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			if statusErr, ok := err.(*apierrors.StatusError); ok && apierrors.IsNotFound(statusErr) {
-				return nil
-			}
-			return retry.NonRetryableError(err)
-		}
-		for _, condition := range pg.Status.Conditions {
-			if condition.ObservedGeneration == pg.Generation {
-				return nil
-			}
+// pulsarGatewayReady reports whether the gateway is ready at its current generation, the Ready condition
+// of the previous generation is reported until the update is reconciled. The condition without an
+// observed generation falls back to the Ready status
+func pulsarGatewayReady(pulsarGateway *cloudv1alpha1.PulsarGateway) bool {
+	for _, condition := range pulsarGateway.Status.Conditions {
+		if condition.Type == "Ready" && condition.Status == "True" &&
+			(condition.ObservedGeneration == 0 || condition.ObservedGeneration >= pulsarGateway.Generation) {
+			return true
 		}
-
-		//Sleep 10 seconds between checks so we don't overload the API
-		time.Sleep(time.Second * 10)
-
-		return retry.RetryableError(fmt.Errorf("pulsargateway: %s/%s is not in complete state", ns, name))
-	}
-}
-
-func retryUntilPulsarGatewayIsDeleted(ctx context.Context, clientSet *cloudclient.Clientset, ns string, name string) retry.RetryFunc {
-	return func() *retry.RetryError {
-		_, err := clientSet.CloudV1alpha1().PulsarGateways(ns).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			if apierrors.IsNotFound(err) {
-				return nil
-			}
-			return retry.RetryableError(fmt.Errorf("pulsargateway: %s/%s is not deleted", ns, name))
-		}
-
-		//Sleep 10 seconds between checks so we don't overload the API
-		time.Sleep(time.Second * 10)
-
-		return retry.RetryableError(fmt.Errorf("pulsargateway: %s/%s is not deleted", ns, name))
 	}
+	return false
 }
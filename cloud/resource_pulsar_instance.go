@@ -17,24 +17,21 @@ package cloud
 import (
 	"context"
 	"fmt"
-	"strings"
-	"time"
-
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	cloudclient "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func resourcePulsarInstance() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourcePulsarInstanceCreate,
-		ReadContext:   resourcePulsarInstanceRead,
-		UpdateContext: resourcePulsarInstanceUpdate,
-		DeleteContext: resourcePulsarInstanceDelete,
+		CreateContext: withResourceIdentity(pulsarInstanceEngine.create, organizationNameIdentity),
+		ReadContext:   withResourceIdentity(pulsarInstanceEngine.read, organizationNameIdentity),
+		UpdateContext: withResourceIdentity(pulsarInstanceEngine.update, organizationNameIdentity),
+		DeleteContext: pulsarInstanceEngine.delete,
+		Identity:      newResourceIdentity(organizationNameIdentity),
 		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, i interface{}) error {
 			oldOrg, _ := diff.GetChange("organization")
 			oldName, _ := diff.GetChange("name")
@@ -52,18 +49,8 @@ func resourcePulsarInstance() *schema.Resource {
 			}
 			return nil
 		},
-		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				organizationInstance := strings.Split(d.Id(), "/")
-				_ = d.Set("organization", organizationInstance[0])
-				_ = d.Set("name", organizationInstance[1])
-				err := resourcePulsarInstanceRead(ctx, d, meta)
-				if err.HasError() {
-					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
-				}
-				return []*schema.ResourceData{d}, nil
-			},
-		},
+		Importer: resourceImporter(resourcePulsarInstanceImportRead, resourcePulsarInstance),
+		Timeouts: resourceEngineTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"organization": {
 				Type:         schema.TypeString,
@@ -124,27 +111,38 @@ func resourcePulsarInstance() *schema.Resource {
 	}
 }
 
-func resourcePulsarInstanceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	availabilityMode := d.Get("availability_mode").(string)
+var pulsarInstanceEngine = &resourceEngine[*cloudv1alpha1.PulsarInstance]{
+	kind: "PULSAR_INSTANCE",
+	client: func(clientSet cloudclient.Interface, namespace string) resourceClient[*cloudv1alpha1.PulsarInstance] {
+		return clientSet.CloudV1alpha1().PulsarInstances(namespace)
+	},
+	newObject: func() *cloudv1alpha1.PulsarInstance {
+		return &cloudv1alpha1.PulsarInstance{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "PulsarInstance",
+				APIVersion: cloudv1alpha1.SchemeGroupVersion.String(),
+			},
+		}
+	},
+	expand:    expandPulsarInstance,
+	flatten:   flattenPulsarInstance,
+	ready:     pulsarInstanceReady,
+	immutable: true,
+}
+
+// expandPulsarInstance sets the instance from the configuration, the type of the instance defaults to
+// the one of the deployment type of the pool option
+func expandPulsarInstance(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, pulsarInstance *cloudv1alpha1.PulsarInstance,
+) error {
 	poolName := d.Get("pool_name").(string)
 	poolNamespace := d.Get("pool_namespace").(string)
 	instanceType := d.Get("type").(string)
-	instanceEngine := d.Get("engine").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_PULSAR_INSTANCE: %w", err))
-	}
-	poolRef := &cloudv1alpha1.PoolRef{
-		Namespace: poolNamespace,
-		Name:      poolName,
-	}
 	poolOption, err := clientSet.CloudV1alpha1().
-		PoolOptions(namespace).
+		PoolOptions(pulsarInstance.Namespace).
 		Get(ctx, fmt.Sprintf("%s-%s", poolNamespace, poolName), metav1.GetOptions{})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_GET_POOL_OPTION: %w", err))
+		return fmt.Errorf("ERROR_GET_POOL_OPTION: %w", err)
 	}
 	if instanceType == "" {
 		if poolOption.Spec.DeploymentType == cloudv1alpha1.PoolDeploymentTypeHosted {
@@ -157,105 +155,66 @@ func resourcePulsarInstanceCreate(ctx context.Context, d *schema.ResourceData, m
 			instanceType = "byoc-pro"
 		}
 	}
-	pulsarInstance := &cloudv1alpha1.PulsarInstance{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "PulsarInstance",
-			APIVersion: cloudv1alpha1.SchemeGroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-		},
-		Spec: cloudv1alpha1.PulsarInstanceSpec{
-			AvailabilityMode: cloudv1alpha1.InstanceAvailabilityMode(availabilityMode),
-			Type:             cloudv1alpha1.PulsarInstanceType(instanceType),
-			PoolRef:          poolRef,
+	pulsarInstance.Spec = cloudv1alpha1.PulsarInstanceSpec{
+		AvailabilityMode: cloudv1alpha1.InstanceAvailabilityMode(d.Get("availability_mode").(string)),
+		Type:             cloudv1alpha1.PulsarInstanceType(instanceType),
+		PoolRef: &cloudv1alpha1.PoolRef{
+			Namespace: poolNamespace,
+			Name:      poolName,
 		},
 	}
-	if instanceEngine == UrsaEngineValue {
+	if d.Get("engine").(string) == UrsaEngineValue {
 		pulsarInstance.Annotations = map[string]string{
 			UrsaEngineAnnotation: UrsaEngineValue,
 		}
 	}
-	pi, err := clientSet.CloudV1alpha1().PulsarInstances(namespace).Create(ctx, pulsarInstance, metav1.CreateOptions{
-		FieldManager: "terraform-create",
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_PULSAR_INSTANCE: %w", err))
-	}
-	if pi.Status.Conditions != nil {
-		ready := false
-		for _, condition := range pi.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				ready = true
-			}
-		}
-		if ready {
-			_ = d.Set("organization", namespace)
-			_ = d.Set("name", name)
-			return resourcePulsarInstanceRead(ctx, d, meta)
-		}
+	return nil
+}
+
+// flattenPulsarInstance only sets the readiness, the type and engine are defaulted by the API server and
+// reading them on refresh would replace the instance
+func flattenPulsarInstance(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, pulsarInstance *cloudv1alpha1.PulsarInstance,
+) error {
+	ready := "False"
+	if pulsarInstanceReady(pulsarInstance) {
+		ready = "True"
 	}
-	err = retry.RetryContext(ctx, 3*time.Minute, func() *retry.RetryError {
-		dia := resourcePulsarInstanceRead(ctx, d, meta)
-		if dia.HasError() {
-			return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_READ_PULSAR_INSTANCE: %s", dia[0].Summary))
-		}
-		ready := d.Get("ready")
-		if ready == "False" {
-			return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_READ_PULSAR_INSTANCE"))
+	return d.Set("ready", ready)
+}
+
+func pulsarInstanceReady(pulsarInstance *cloudv1alpha1.PulsarInstance) bool {
+	for _, condition := range pulsarInstance.Status.Conditions {
+		if condition.Type == "Ready" && condition.Status == "True" {
+			return true
 		}
-		return nil
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_READ_PULSAR_INSTANCE: %w", err))
 	}
-	return nil
+	return false
 }
 
-func resourcePulsarInstanceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+// resourcePulsarInstanceImportRead reads the attributes which aren't refreshed by the read as well
+func resourcePulsarInstanceImportRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	if diags := pulsarInstanceEngine.read(ctx, d, meta); diags.HasError() || d.Id() == "" {
+		return diags
+	}
 	namespace := d.Get("organization").(string)
 	name := d.Get("name").(string)
 	clientSet, err := getClientSet(getFactoryFromMeta(meta))
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_SERVICE_ACCOUNT: %w", err))
+		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_IMPORT_PULSAR_INSTANCE: %w", err))
 	}
 	pulsarInstance, err := clientSet.CloudV1alpha1().PulsarInstances(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			d.SetId("")
-			return nil
-		}
 		return diag.FromErr(fmt.Errorf("ERROR_READ_PULSAR_INSTANCE: %w", err))
 	}
-	_ = d.Set("ready", "False")
-	if pulsarInstance.Status.Conditions != nil {
-		for _, condition := range pulsarInstance.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				_ = d.Set("ready", "True")
-			}
-		}
-	}
-	d.SetId(fmt.Sprintf("%s/%s", pulsarInstance.Namespace, pulsarInstance.Name))
-	return nil
-}
-
-func resourcePulsarInstanceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	return diag.FromErr(fmt.Errorf("ERROR_UPDATE_PULSAR_INSTANCE: " +
-		"The pulsar instance does not support updates, please recreate it"))
-}
-
-func resourcePulsarInstanceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_DELETE_PULSAR_INSTANCE: %w", err))
+	_ = d.Set("availability_mode", string(pulsarInstance.Spec.AvailabilityMode))
+	if pulsarInstance.Spec.PoolRef != nil {
+		_ = d.Set("pool_name", pulsarInstance.Spec.PoolRef.Name)
+		_ = d.Set("pool_namespace", pulsarInstance.Spec.PoolRef.Namespace)
 	}
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	err = clientSet.CloudV1alpha1().PulsarInstances(namespace).Delete(ctx, name, metav1.DeleteOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("DELETE_PULSAR_INSTANCE: %w", err))
+	_ = d.Set("type", string(pulsarInstance.Spec.Type))
+	if pulsarInstance.Annotations[UrsaEngineAnnotation] == UrsaEngineValue {
+		_ = d.Set("engine", UrsaEngineValue)
 	}
-	_ = d.Set("name", "")
 	return nil
 }
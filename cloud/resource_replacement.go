@@ -0,0 +1,56 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// allowReplacementSchema is the allow_replacement attribute of the resources which have attributes
+// that can't be updated in place
+func allowReplacementSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: descriptions["allow_replacement"],
+	}
+}
+
+// forceNewIfAllowed plans the replacement of the resource when one of the attributes changes and
+// allow_replacement is enabled, otherwise the change is rejected by notSupported so the resource
+// is never destroyed by surprise
+func forceNewIfAllowed(diff *schema.ResourceDiff, notSupported error, attributes ...string) error {
+	var changed []string
+	for _, attribute := range attributes {
+		if diff.HasChange(attribute) {
+			changed = append(changed, attribute)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	if !diff.Get("allow_replacement").(bool) {
+		return fmt.Errorf("%w, or set allow_replacement to true to replace it", notSupported)
+	}
+	for _, attribute := range changed {
+		if err := diff.ForceNew(attribute); err != nil {
+			return err
+		}
+	}
+	return nil
+}
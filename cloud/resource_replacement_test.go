@@ -0,0 +1,60 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForceNewIfAllowed(t *testing.T) {
+	r := resourcePulsarGateway()
+	state := &terraform.InstanceState{
+		ID: "sndev/test-gateway",
+		Attributes: map[string]string{
+			"id":                  "sndev/test-gateway",
+			"organization":        "sndev",
+			"name":                "test-gateway",
+			"access":              "public",
+			"pool_member_name":    "test-pool-member",
+			"wait_for_completion": "true",
+			"allow_replacement":   "false",
+		},
+	}
+	config := map[string]interface{}{
+		"organization":     "sndev",
+		"name":             "test-gateway",
+		"access":           "private",
+		"pool_member_name": "test-pool-member",
+	}
+	_, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), nil)
+	assert.ErrorContains(t, err, "allow_replacement")
+
+	config["allow_replacement"] = true
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err)
+	if assert.NotNil(t, diff) {
+		assert.True(t, diff.RequiresNew())
+		assert.True(t, diff.Attributes["access"].RequiresNew)
+	}
+
+	config["access"] = "public"
+	diff, err = r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(config), nil)
+	assert.NoError(t, err)
+	assert.False(t, diff != nil && diff.RequiresNew())
+}
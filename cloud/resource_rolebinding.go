@@ -3,24 +3,22 @@ package cloud
 import (
 	"context"
 	"fmt"
-	"strings"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	cloudclient "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset"
 	"github.com/streamnative/terraform-provider-streamnative/cloud/rbac"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func resourceRoleBinding() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourceRoleBindingCreate,
-		ReadContext:   resourceRoleBindingRead,
-		UpdateContext: resourceRoleBindingUpdate,
-		DeleteContext: resourceRoleBindingDelete,
+		CreateContext: withResourceIdentity(roleBindingEngine.create, organizationNameIdentity),
+		ReadContext:   withResourceIdentity(roleBindingEngine.read, organizationNameIdentity),
+		UpdateContext: withResourceIdentity(roleBindingEngine.update, organizationNameIdentity),
+		DeleteContext: roleBindingEngine.delete,
+		Identity:      newResourceIdentity(organizationNameIdentity),
 		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, i interface{}) error {
 			oldOrg, _ := diff.GetChange("organization")
 			oldName, _ := diff.GetChange("name")
@@ -37,25 +35,8 @@ func resourceRoleBinding() *schema.Resource {
 			}
 			return nil
 		},
-		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				organizationRoleBinding := strings.Split(d.Id(), "/")
-				if len(organizationRoleBinding) != 2 {
-					return nil, fmt.Errorf("invalid rolebinding import ID format: %q. Expected format: 'organization/name'", d.Id())
-				}
-				if err := d.Set("organization", organizationRoleBinding[0]); err != nil {
-					return nil, fmt.Errorf("ERROR_IMPORT_ORGANIZATION: %w", err)
-				}
-				if err := d.Set("name", organizationRoleBinding[1]); err != nil {
-					return nil, fmt.Errorf("ERROR_IMPORT_NAME: %w", err)
-				}
-				err := resourceRoleBindingRead(ctx, d, meta)
-				if err.HasError() {
-					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
-				}
-				return []*schema.ResourceData{d}, nil
-			},
-		},
+		Importer: resourceImporter(resourceRoleBindingImportRead, resourceRoleBinding),
+		Timeouts: resourceEngineTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"organization": {
 				Type:         schema.TypeString,
@@ -174,205 +155,95 @@ func resourceRoleBinding() *schema.Resource {
 	}
 }
 
-func resourceRoleBindingCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-
-	predefinedRoleName := d.Get("cluster_role_name").(string)
-	serviceAccountNames := d.Get("service_account_names").([]interface{})
-	userNames := d.Get("user_names").([]interface{})
-	resourceNameRestriction := d.Get("resource_name_restriction").([]interface{})
-
-	clientSet, err := getClientSet(getFactoryFromMeta(m))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_CREATE_ROLEBINDING: %w", err))
-	}
-	rb := &v1alpha1.RoleBinding{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "RoleBinding",
-			APIVersion: v1alpha1.SchemeGroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-		},
-		Spec: v1alpha1.RoleBindingSpec{
-			Subjects: []v1alpha1.Subject{},
-		},
-	}
+var roleBindingEngine = &resourceEngine[*v1alpha1.RoleBinding]{
+	kind: "ROLEBINDING",
+	client: func(clientSet cloudclient.Interface, namespace string) resourceClient[*v1alpha1.RoleBinding] {
+		return clientSet.CloudV1alpha1().RoleBindings(namespace)
+	},
+	newObject: func() *v1alpha1.RoleBinding {
+		return &v1alpha1.RoleBinding{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "RoleBinding",
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			},
+		}
+	},
+	expand:  expandRoleBinding,
+	flatten: flattenRoleBinding,
+	ready:   roleBindingReady,
+}
 
-	if predefinedRoleName != "" {
+// expandRoleBinding sets the role, the subjects and the conditions of the role binding from the configuration
+func expandRoleBinding(_ context.Context, d *schema.ResourceData, _ cloudclient.Interface, rb *v1alpha1.RoleBinding) error {
+	if predefinedRoleName := d.Get("cluster_role_name").(string); predefinedRoleName != "" {
 		rb.Spec.RoleRef = v1alpha1.RoleRef{
 			APIGroup: "cloud.streamnative.io",
 			Kind:     "ClusterRole",
 			Name:     predefinedRoleName,
 		}
 	}
-	if serviceAccountNames != nil {
-		for _, serviceAccountName := range serviceAccountNames {
-			rb.Spec.Subjects = append(rb.Spec.Subjects, v1alpha1.Subject{
-				APIGroup: "cloud.streamnative.io",
-				Name:     serviceAccountName.(string),
-				Kind:     "ServiceAccount",
-			})
-		}
+	rb.Spec.Subjects = []v1alpha1.Subject{}
+	for _, serviceAccountName := range d.Get("service_account_names").([]interface{}) {
+		rb.Spec.Subjects = append(rb.Spec.Subjects, v1alpha1.Subject{
+			APIGroup: "cloud.streamnative.io",
+			Name:     serviceAccountName.(string),
+			Kind:     "ServiceAccount",
+		})
 	}
-
-	if userNames != nil {
-		for _, userName := range userNames {
-			rb.Spec.Subjects = append(rb.Spec.Subjects, v1alpha1.Subject{
-				APIGroup: "cloud.streamnative.io",
-				Name:     userName.(string),
-				Kind:     "User",
-			})
-		}
+	for _, userName := range d.Get("user_names").([]interface{}) {
+		rb.Spec.Subjects = append(rb.Spec.Subjects, v1alpha1.Subject{
+			APIGroup: "cloud.streamnative.io",
+			Name:     userName.(string),
+			Kind:     "User",
+		})
 	}
-
-	if resourceNameRestriction != nil && len(resourceNameRestriction) > 0 {
+	resourceNameRestriction := d.Get("resource_name_restriction").([]interface{})
+	if len(resourceNameRestriction) > 0 {
 		if restriction, updated := rbac.ParseToResourceNameRestriction(resourceNameRestriction[0].(map[string]interface{})); updated {
 			rb.Spec.ResourceNameRestriction = restriction
 		}
 	}
-
-	conditionSet(namespace, d, rb)
-
-	if _, err := clientSet.CloudV1alpha1().RoleBindings(namespace).Create(ctx, rb, metav1.CreateOptions{
-		FieldManager: "terraform-create",
-	}); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_ROLEBINDING: %w", err))
-	}
-	d.SetId(fmt.Sprintf("%s/%s", namespace, name))
-	err = retry.RetryContext(ctx, 10*time.Minute, func() *retry.RetryError {
-		dia := resourceRoleBindingRead(ctx, d, m)
-		if dia.HasError() {
-			return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_CREATE_ROLEBINDING: %s", dia[0].Summary))
-		}
-		ready := d.Get("ready")
-		if ready == false {
-			return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_CREATE_ROLEBINDING"))
-		}
-		return nil
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_CREATE_CREATE_ROLEBINDING: %w", err))
-	}
+	conditionSet(rb.Namespace, d, rb)
 	return nil
 }
 
-func resourceRoleBindingDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	clientSet, err := getClientSet(getFactoryFromMeta(m))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_DELETE_ROLEBINDING: %w", err))
+// flattenRoleBinding only sets the readiness, the role, subjects and conditions are read on import
+func flattenRoleBinding(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, rb *v1alpha1.RoleBinding,
+) error {
+	if err := d.Set("ready", roleBindingReady(rb)); err != nil {
+		return fmt.Errorf("ERROR_SET_READY: %w", err)
 	}
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	_, err = clientSet.CloudV1alpha1().RoleBindings(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_READ_ROLEBINDING: %w", err))
-	}
-	err = clientSet.CloudV1alpha1().RoleBindings(namespace).Delete(ctx, name, metav1.DeleteOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_DELETE_ROLEBINDING: %w", err))
-	}
-	_ = d.Set("name", "")
-	d.SetId(fmt.Sprintf("%s/%s", namespace, name))
 	return nil
 }
 
-func resourceRoleBindingUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	userNames := d.Get("user_names").([]interface{})
-	clientSet, err := getClientSet(getFactoryFromMeta(m))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_ROLEBINDING: %w", err))
-	}
-	roleBinding, err := clientSet.CloudV1alpha1().RoleBindings(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_READ_ROLEBINDING: %w", err))
-	}
-
-	serviceAccountNames := d.Get("service_account_names").([]interface{})
-
-	roleBinding.Spec.Subjects = []v1alpha1.Subject{}
-
-	if serviceAccountNames != nil {
-		for _, serviceAccountName := range serviceAccountNames {
-			roleBinding.Spec.Subjects = append(roleBinding.Spec.Subjects, v1alpha1.Subject{
-				APIGroup: "cloud.streamnative.io",
-				Name:     serviceAccountName.(string),
-				Kind:     "ServiceAccount",
-			})
+func roleBindingReady(rb *v1alpha1.RoleBinding) bool {
+	for _, condition := range rb.Status.Conditions {
+		if condition.Type == "Ready" && condition.Status == "True" {
+			return true
 		}
 	}
-	if userNames != nil {
-		for _, userName := range userNames {
-			roleBinding.Spec.Subjects = append(roleBinding.Spec.Subjects, v1alpha1.Subject{
-				APIGroup: "cloud.streamnative.io",
-				Name:     userName.(string),
-				Kind:     "User",
-			})
-		}
-	}
-
-	conditionSet(namespace, d, roleBinding)
-	_, err = clientSet.CloudV1alpha1().RoleBindings(namespace).Update(ctx, roleBinding, metav1.UpdateOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_ROLEBINDING: %w", err))
-	}
-	err = retry.RetryContext(ctx, 10*time.Minute, func() *retry.RetryError {
-		dia := resourceRoleBindingRead(ctx, d, m)
-		if dia.HasError() {
-			return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_UPDATE_ROLEBINDING: %s", dia[0].Summary))
-		}
-		ready := d.Get("ready")
-		if ready == false {
-			return retry.RetryableError(fmt.Errorf("CONTINUE_RETRY_CREATE_ROLEBINDING"))
-		}
-		return nil
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_CREATE_ROLEBINDING: %w", err))
-	}
-	d.SetId(fmt.Sprintf("%s/%s", roleBinding.Namespace, roleBinding.Name))
-	return nil
+	return false
 }
 
-func resourceRoleBindingRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+// resourceRoleBindingImportRead reads the role, subjects and conditions which aren't refreshed by the read
+func resourceRoleBindingImportRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if diags := roleBindingEngine.read(ctx, d, m); diags.HasError() || d.Id() == "" {
+		return diags
+	}
 	namespace := d.Get("organization").(string)
 	name := d.Get("name").(string)
 	clientSet, err := getClientSet(getFactoryFromMeta(m))
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_ROLEBINDING: %w", err))
+		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_IMPORT_ROLEBINDING: %w", err))
 	}
-
 	roleBinding, err := clientSet.CloudV1alpha1().RoleBindings(namespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		if apierrors.IsNotFound(err) {
-			d.SetId("")
-			return nil
-		}
 		return diag.FromErr(fmt.Errorf("ERROR_READ_ROLEBINDING: %w", err))
 	}
-	if err = d.Set("organization", namespace); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_ORGANIZATION: %w", err))
-	}
-	if err = d.Set("name", roleBinding.Name); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_NAME: %w", err))
-	}
-	if err = d.Set("ready", false); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_READY: %w", err))
-	}
-	if len(roleBinding.Status.Conditions) >= 1 {
-		for _, condition := range roleBinding.Status.Conditions {
-			if condition.Type == "Ready" && condition.Status == "True" {
-				if err = d.Set("ready", true); err != nil {
-					return diag.FromErr(fmt.Errorf("ERROR_SET_READY: %w", err))
-				}
-			}
-		}
+	if err = setRoleBindingSpec(namespace, roleBinding, d); err != nil {
+		return diag.FromErr(err)
 	}
-	d.SetId(fmt.Sprintf("%s/%s", roleBinding.Namespace, roleBinding.Name))
 	return nil
 }
 
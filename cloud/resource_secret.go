@@ -17,10 +17,18 @@ package cloud
 import (
 	"context"
 	"fmt"
-	"strings"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -28,209 +36,338 @@ import (
 	"github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
 )
 
-func resourceSecret() *schema.Resource {
-	return &schema.Resource{
-		CreateContext: resourceSecretCreate,
-		ReadContext:   resourceSecretRead,
-		UpdateContext: resourceSecretUpdate,
-		DeleteContext: resourceSecretDelete,
-		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				parts := strings.Split(d.Id(), "/")
-				if len(parts) != 2 {
-					return nil, fmt.Errorf("invalid import id %q, expected <organization>/<name>", d.Id())
-				}
-				_ = d.Set("organization", parts[0])
-				_ = d.Set("name", parts[1])
-				if diags := resourceSecretRead(ctx, d, meta); diags.HasError() {
-					return nil, fmt.Errorf("import %q: %s", d.Id(), diags[0].Summary)
-				}
-				return []*schema.ResourceData{d}, nil
+type secretResource struct {
+	factory *providerFactory
+}
+
+type secretResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Organization   types.String `tfsdk:"organization"`
+	Name           types.String `tfsdk:"name"`
+	InstanceName   types.String `tfsdk:"instance_name"`
+	Location       types.String `tfsdk:"location"`
+	PoolMemberName types.String `tfsdk:"pool_member_name"`
+	Type           types.String `tfsdk:"type"`
+	Data           types.Map    `tfsdk:"data"`
+	StringData     types.Map    `tfsdk:"string_data"`
+	DataWO         types.Map    `tfsdk:"data_wo"`
+	StringDataWO   types.Map    `tfsdk:"string_data_wo"`
+	DataWOVersion  types.Int64  `tfsdk:"data_wo_version"`
+}
+
+var (
+	_ resource.ResourceWithConfigure   = &secretResource{}
+	_ resource.ResourceWithImportState = &secretResource{}
+	_ resource.ResourceWithIdentity    = &secretResource{}
+)
+
+func NewSecretResource() resource.Resource {
+	return &secretResource{}
+}
+
+func (r *secretResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_secret"
+}
+
+func (r *secretResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ID of this resource.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-		},
-		Schema: map[string]*schema.Schema{
-			"organization": {
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     true,
-				Description:  descriptions["organization"],
-				ValidateFunc: validateNotBlank,
+			"organization": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["organization"],
+				Validators:  []validator.String{notBlankValidator{}},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"name": {
-				Type:         schema.TypeString,
-				Required:     true,
-				ForceNew:     true,
-				Description:  descriptions["secret_name"],
-				ValidateFunc: validateNotBlank,
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: descriptions["secret_name"],
+				Validators:  []validator.String{notBlankValidator{}},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"instance_name": {
-				Type:        schema.TypeString,
+			"instance_name": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
 				Description: descriptions["instance_name"],
+				// The instance name is a part of the resource identity, which can't change in place
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
-			"location": {
-				Type:        schema.TypeString,
+			"location": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
 				Description: descriptions["location"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"pool_member_name": {
-				Type:        schema.TypeString,
+			"pool_member_name": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
 				Description: descriptions["pool_member_name"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"type": {
-				Type:        schema.TypeString,
+			"type": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
 				Description: descriptions["secret_type"],
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
-			"data": {
-				Type:         schema.TypeMap,
-				Optional:     true,
-				Computed:     true,
-				Sensitive:    true,
-				ForceNew:     true,
-				AtLeastOneOf: []string{"data", "string_data"},
-				Description:  descriptions["secret_data"],
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+			"data": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Computed:    true,
+				Sensitive:   true,
+				Description: descriptions["secret_data"],
+				Validators: []validator.Map{
+					mapvalidator.AtLeastOneOf(
+						path.MatchRoot("string_data"), path.MatchRoot("data_wo"), path.MatchRoot("string_data_wo")),
+				},
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.UseStateForUnknown(),
+					requiresReplaceIfMapChanged(),
 				},
 			},
-			"string_data": {
-				Type:         schema.TypeMap,
-				Optional:     true,
-				Sensitive:    true,
-				ForceNew:     true,
-				AtLeastOneOf: []string{"data", "string_data"},
-				Description:  descriptions["secret_string_data"],
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
+			"string_data": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Sensitive:   true,
+				Description: descriptions["secret_string_data"],
+				Validators: []validator.Map{
+					mapvalidator.AtLeastOneOf(
+						path.MatchRoot("data"), path.MatchRoot("data_wo"), path.MatchRoot("string_data_wo")),
+				},
+				PlanModifiers: []planmodifier.Map{
+					requiresReplaceIfMapChanged(),
+				},
+			},
+			"data_wo": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				WriteOnly:   true,
+				Description: descriptions["secret_data_wo"],
+				Validators: []validator.Map{
+					mapvalidator.ConflictsWith(path.MatchRoot("data")),
+					mapvalidator.AlsoRequires(path.MatchRoot("data_wo_version")),
+				},
+			},
+			"string_data_wo": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				WriteOnly:   true,
+				Description: descriptions["secret_string_data_wo"],
+				Validators: []validator.Map{
+					mapvalidator.ConflictsWith(path.MatchRoot("string_data")),
+					mapvalidator.AlsoRequires(path.MatchRoot("data_wo_version")),
+				},
+			},
+			"data_wo_version": schema.Int64Attribute{
+				Optional:    true,
+				Description: descriptions["secret_data_wo_version"],
+				Validators: []validator.Int64{
+					int64validator.AtLeastOneOf(path.MatchRoot("data_wo"), path.MatchRoot("string_data_wo")),
 				},
 			},
 		},
 	}
 }
 
-func resourceSecretCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
+func (r *secretResource) IdentitySchema(
+	_ context.Context, _ resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
+	resp.IdentitySchema = instanceResourceIdentitySchema()
+}
+
+func (r *secretResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	r.factory = getFactoryFromMeta(req.ProviderData)
+}
+
+func (r *secretResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan, config secretResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// The write-only values are only available in the configuration
+	plan.DataWO = config.DataWO
+	plan.StringDataWO = config.StringDataWO
+	clientSet, err := getClientSet(r.factory)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_CREATE_SECRET: %w", err))
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_CREATE_SECRET", err.Error())
+		return
 	}
 
-	secret := buildSecretFromResourceData(d)
+	secret, diags := buildSecretFromPlan(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	created, err := clientSet.CloudV1alpha1().Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{
 		FieldManager: "terraform-create",
 	})
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_SECRET: %w", err))
+		resp.Diagnostics.AddError("ERROR_CREATE_SECRET", err.Error())
+		return
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s", created.Namespace, created.Name))
-	return resourceSecretRead(ctx, d, meta)
+	resp.Diagnostics.Append(setSecretState(ctx, &plan, created)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(setInstanceResourceIdentity(
+		ctx, resp.Identity, plan.Organization, plan.Name, plan.InstanceName)...)
 }
 
-func resourceSecretRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
+func (r *secretResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state secretResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	clientSet, err := getClientSet(r.factory)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_SECRET: %w", err))
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_READ_SECRET", err.Error())
+		return
 	}
 
-	secret, err := clientSet.CloudV1alpha1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	secret, err := clientSet.CloudV1alpha1().Secrets(state.Organization.ValueString()).Get(
+		ctx, state.Name.ValueString(), metav1.GetOptions{})
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			d.SetId("")
-			return nil
+			resp.State.RemoveResource(ctx)
+			return
 		}
-		return diag.FromErr(fmt.Errorf("ERROR_READ_SECRET: %w", err))
+		resp.Diagnostics.AddError("ERROR_READ_SECRET", err.Error())
+		return
 	}
 
-	return setSecretState(d, secret)
+	resp.Diagnostics.Append(setSecretState(ctx, &state, secret)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	resp.Diagnostics.Append(setInstanceResourceIdentity(
+		ctx, resp.Identity, state.Organization, state.Name, state.InstanceName)...)
 }
 
-func resourceSecretUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_UPDATE_SECRET: %w", err))
+func (r *secretResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state, config secretResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	secret, err := clientSet.CloudV1alpha1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	// The write-only values are only available in the configuration, they're sent again when
+	// 'data_wo_version' changes
+	plan.DataWO = config.DataWO
+	plan.StringDataWO = config.StringDataWO
+	namespace := plan.Organization.ValueString()
+	clientSet, err := getClientSet(r.factory)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_GET_SECRET_ON_UPDATE: %w", err))
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_UPDATE_SECRET", err.Error())
+		return
 	}
 
-	applySecretPlan(secret, d, false)
-	updated, err := clientSet.CloudV1alpha1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{
-		FieldManager: "terraform-update",
-	})
+	secrets := clientSet.CloudV1alpha1().Secrets(namespace)
+	updated, err := retryUpdateOnConflict(ctx, r.factory, plan.Name.ValueString(), secrets.Get, secrets.Update,
+		func(secret *v1alpha1.Secret) error {
+			if diags := applySecretPlan(ctx, secret, &plan, &state); diags.HasError() {
+				resp.Diagnostics.Append(diags...)
+				return fmt.Errorf("failed to apply the plan to the secret %s", secret.Name)
+			}
+			return nil
+		}, metav1.UpdateOptions{
+			FieldManager: "terraform-update",
+		})
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_UPDATE_SECRET: %w", err))
+		resp.Diagnostics.AddError("ERROR_UPDATE_SECRET", err.Error())
+		return
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s", updated.Namespace, updated.Name))
-	return resourceSecretRead(ctx, d, meta)
+	resp.Diagnostics.Append(setSecretState(ctx, &plan, updated)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	resp.Diagnostics.Append(setInstanceResourceIdentity(
+		ctx, resp.Identity, plan.Organization, plan.Name, plan.InstanceName)...)
 }
 
-func resourceSecretDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
+func (r *secretResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state secretResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	clientSet, err := getClientSet(r.factory)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_DELETE_SECRET: %w", err))
+		resp.Diagnostics.AddError("ERROR_INIT_CLIENT_ON_DELETE_SECRET", err.Error())
+		return
 	}
 
-	if err := clientSet.CloudV1alpha1().Secrets(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
-		if apierrors.IsNotFound(err) {
-			d.SetId("")
-			return nil
-		}
-		return diag.FromErr(fmt.Errorf("ERROR_DELETE_SECRET: %w", err))
+	err = clientSet.CloudV1alpha1().Secrets(state.Organization.ValueString()).Delete(
+		ctx, state.Name.ValueString(), metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		resp.Diagnostics.AddError("ERROR_DELETE_SECRET", err.Error())
 	}
+}
 
-	d.SetId("")
-	return nil
+func (r *secretResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	importInstanceResourceState(ctx, req, resp, "ERROR_IMPORT_SECRET")
 }
 
-func buildSecretFromResourceData(d *schema.ResourceData) *v1alpha1.Secret {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
+func buildSecretFromPlan(ctx context.Context, plan *secretResourceModel) (*v1alpha1.Secret, diag.Diagnostics) {
 	secret := &v1alpha1.Secret{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Secret",
 			APIVersion: v1alpha1.SchemeGroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
+			Name:      plan.Name.ValueString(),
+			Namespace: plan.Organization.ValueString(),
 		},
 	}
 
-	applySecretPlan(secret, d, true)
-	return secret
+	diags := applySecretPlan(ctx, secret, plan, nil)
+	return secret, diags
 }
 
-func applySecretPlan(secret *v1alpha1.Secret, d *schema.ResourceData, includeUnset bool) {
-	secret.InstanceName = d.Get("instance_name").(string)
-	secret.Location = d.Get("location").(string)
+// applySecretPlan applies the planned values to the secret, only the changed values are applied
+// when the prior state is given
+func applySecretPlan(
+	ctx context.Context, secret *v1alpha1.Secret, plan, state *secretResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	includeUnset := state == nil
+	secret.InstanceName = plan.InstanceName.ValueString()
+	secret.Location = plan.Location.ValueString()
 
-	poolMemberName := d.Get("pool_member_name").(string)
+	poolMemberName := plan.PoolMemberName.ValueString()
 	if poolMemberName != "" {
 		secret.PoolMemberRef = &v1alpha1.PoolMemberReference{
 			Name:      poolMemberName,
 			Namespace: secret.Namespace,
 		}
-	} else if includeUnset || d.HasChange("pool_member_name") {
+	} else if includeUnset || !plan.PoolMemberName.Equal(state.PoolMemberName) {
 		secret.PoolMemberRef = nil
 	}
 
-	if includeUnset || d.HasChange("type") {
-		secretType := d.Get("type").(string)
+	if includeUnset || !plan.Type.Equal(state.Type) {
+		secretType := plan.Type.ValueString()
 		if secretType != "" {
 			t := corev1.SecretType(secretType)
 			secret.Type = &t
@@ -239,67 +376,60 @@ func applySecretPlan(secret *v1alpha1.Secret, d *schema.ResourceData, includeUns
 		}
 	}
 
-	if includeUnset || d.HasChange("data") {
-		if dataRaw, ok := d.GetOk("data"); ok {
-			secret.Data = convertToStringMap(dataRaw.(map[string]interface{}))
-		} else {
-			secret.Data = nil
-		}
+	if includeUnset || !plan.Data.Equal(state.Data) {
+		secret.Data, diags = stringMapFromValue(ctx, plan.Data)
 	}
 
-	if includeUnset || d.HasChange("string_data") {
-		if stringDataRaw, ok := d.GetOk("string_data"); ok {
-			secret.StringData = convertToStringMap(stringDataRaw.(map[string]interface{}))
-		} else {
-			secret.StringData = nil
+	if includeUnset || !plan.StringData.Equal(state.StringData) {
+		stringData, d := stringMapFromValue(ctx, plan.StringData)
+		diags.Append(d...)
+		secret.StringData = stringData
+	}
+
+	// The write-only values are sent again only when 'data_wo_version' changes
+	if includeUnset || !plan.DataWOVersion.Equal(state.DataWOVersion) {
+		if !plan.DataWO.IsNull() {
+			data, d := stringMapFromValue(ctx, plan.DataWO)
+			diags.Append(d...)
+			secret.Data = data
+		}
+		if !plan.StringDataWO.IsNull() {
+			stringData, d := stringMapFromValue(ctx, plan.StringDataWO)
+			diags.Append(d...)
+			secret.StringData = stringData
 		}
 	}
+	return diags
 }
 
-func setSecretState(d *schema.ResourceData, secret *v1alpha1.Secret) diag.Diagnostics {
-	if err := d.Set("organization", secret.Namespace); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_ORGANIZATION: %w", err))
-	}
-	if err := d.Set("name", secret.Name); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_NAME: %w", err))
-	}
-	if err := d.Set("instance_name", secret.InstanceName); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_INSTANCE_NAME: %w", err))
-	}
-	if err := d.Set("location", secret.Location); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_LOCATION: %w", err))
-	}
-	if err := d.Set("data", secret.Data); err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_SET_DATA: %w", err))
+func setSecretState(ctx context.Context, model *secretResourceModel, secret *v1alpha1.Secret) diag.Diagnostics {
+	var diags diag.Diagnostics
+	model.ID = types.StringValue(fmt.Sprintf("%s/%s", secret.Namespace, secret.Name))
+	model.Organization = types.StringValue(secret.Namespace)
+	model.Name = types.StringValue(secret.Name)
+	model.InstanceName = types.StringValue(secret.InstanceName)
+	model.Location = types.StringValue(secret.Location)
+	// The data written by 'data_wo' is kept out of the state
+	if !model.DataWOVersion.IsNull() && (model.Data.IsNull() || model.Data.IsUnknown()) {
+		model.Data = types.MapNull(types.StringType)
+	} else {
+		model.Data, diags = types.MapValueFrom(ctx, types.StringType, secret.Data)
 	}
+	model.DataWO = types.MapNull(types.StringType)
+	model.StringDataWO = types.MapNull(types.StringType)
 
 	if secret.PoolMemberRef != nil {
-		if err := d.Set("pool_member_name", secret.PoolMemberRef.Name); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_POOL_MEMBER_NAME: %w", err))
-		}
+		model.PoolMemberName = types.StringValue(secret.PoolMemberRef.Name)
 	} else {
-		if err := d.Set("pool_member_name", ""); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_RESET_POOL_MEMBER_NAME: %w", err))
-		}
+		model.PoolMemberName = types.StringValue("")
 	}
 
 	if secret.Type != nil {
-		if err := d.Set("type", string(*secret.Type)); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_TYPE: %w", err))
-		}
+		model.Type = types.StringValue(string(*secret.Type))
 	} else {
-		if err := d.Set("type", ""); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_RESET_TYPE: %w", err))
-		}
-	}
-
-	// Preserve user-supplied string_data without attempting to read it from the API server.
-	if stringData, ok := d.GetOk("string_data"); ok {
-		if err := d.Set("string_data", stringData); err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_SET_STRING_DATA: %w", err))
-		}
+		model.Type = types.StringValue("")
 	}
 
-	d.SetId(fmt.Sprintf("%s/%s", secret.Namespace, secret.Name))
-	return nil
+	// The user-supplied string_data is preserved without attempting to read it from the API server.
+	return diags
 }
@@ -0,0 +1,180 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testSecretSchema() schema.Schema {
+	resp := &resource.SchemaResponse{}
+	NewSecretResource().Schema(context.Background(), resource.SchemaRequest{}, resp)
+	return resp.Schema
+}
+
+func testSecretObjectValue(s schema.Schema, attributes map[string]tftypes.Value) tftypes.Value {
+	values := map[string]tftypes.Value{
+		"organization": tftypes.NewValue(tftypes.String, "sndev"),
+		"name":         tftypes.NewValue(tftypes.String, "secret"),
+	}
+	for name, value := range attributes {
+		values[name] = value
+	}
+	return nullObjectValue(s.Type().TerraformType(context.Background()), values)
+}
+
+func testStringMapValue(values map[string]string) tftypes.Value {
+	elements := map[string]tftypes.Value{}
+	for key, value := range values {
+		elements[key] = tftypes.NewValue(tftypes.String, value)
+	}
+	return tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, elements)
+}
+
+func TestSecretDataValidators(t *testing.T) {
+	ctx := context.Background()
+	s := testSecretSchema()
+	validate := func(attributes map[string]tftypes.Value) bool {
+		config := tfsdk.Config{Schema: s, Raw: testSecretObjectValue(s, attributes)}
+		hasError := false
+		for _, name := range []string{"data", "string_data"} {
+			var value types.Map
+			assert.False(t, config.GetAttribute(ctx, path.Root(name), &value).HasError())
+			for _, v := range s.Attributes[name].(schema.MapAttribute).Validators {
+				resp := &validator.MapResponse{}
+				v.ValidateMap(ctx, validator.MapRequest{
+					Path:           path.Root(name),
+					PathExpression: path.MatchRoot(name),
+					Config:         config,
+					ConfigValue:    value,
+				}, resp)
+				hasError = hasError || resp.Diagnostics.HasError()
+			}
+		}
+		return hasError
+	}
+	data := testStringMapValue(map[string]string{"password": "secret"})
+	version := tftypes.NewValue(tftypes.Number, 1)
+	// Any of the data attributes is enough, including only the write-only ones
+	assert.False(t, validate(map[string]tftypes.Value{"data": data}))
+	assert.False(t, validate(map[string]tftypes.Value{"string_data": data}))
+	assert.False(t, validate(map[string]tftypes.Value{"data_wo": data, "data_wo_version": version}))
+	assert.False(t, validate(map[string]tftypes.Value{"string_data_wo": data, "data_wo_version": version}))
+	assert.True(t, validate(map[string]tftypes.Value{}))
+}
+
+func TestSecretUpdateWriteOnlyData(t *testing.T) {
+	ctx := context.Background()
+	s := testSecretSchema()
+	meta, clientSet := newFakeProviderMeta(&cloudv1alpha1.Secret{
+		ObjectMeta:   metav1.ObjectMeta{Name: "secret", Namespace: "sndev"},
+		InstanceName: "instance",
+		Data:         map[string]string{"password": "old"},
+	})
+	r := NewSecretResource()
+	r.(resource.ResourceWithConfigure).Configure(ctx, resource.ConfigureRequest{ProviderData: meta},
+		&resource.ConfigureResponse{})
+	update := func(priorVersion, version int, password string) {
+		prior := map[string]tftypes.Value{
+			"id":               tftypes.NewValue(tftypes.String, "sndev/secret"),
+			"instance_name":    tftypes.NewValue(tftypes.String, "instance"),
+			"location":         tftypes.NewValue(tftypes.String, ""),
+			"pool_member_name": tftypes.NewValue(tftypes.String, ""),
+			"type":             tftypes.NewValue(tftypes.String, ""),
+			"data_wo_version":  tftypes.NewValue(tftypes.Number, priorVersion),
+		}
+		planned := map[string]tftypes.Value{}
+		for name, value := range prior {
+			planned[name] = value
+		}
+		planned["data_wo_version"] = tftypes.NewValue(tftypes.Number, version)
+		configured := map[string]tftypes.Value{
+			"instance_name":   tftypes.NewValue(tftypes.String, "instance"),
+			"data_wo":         testStringMapValue(map[string]string{"password": password}),
+			"data_wo_version": tftypes.NewValue(tftypes.Number, version),
+		}
+		resp := &resource.UpdateResponse{
+			State: tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)},
+		}
+		r.Update(ctx, resource.UpdateRequest{
+			State:  tfsdk.State{Schema: s, Raw: testSecretObjectValue(s, prior)},
+			Plan:   tfsdk.Plan{Schema: s, Raw: testSecretObjectValue(s, planned)},
+			Config: tfsdk.Config{Schema: s, Raw: testSecretObjectValue(s, configured)},
+		}, resp)
+		assert.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+		// The write-only data is never saved in the state
+		var data types.Map
+		assert.False(t, resp.State.GetAttribute(ctx, path.Root("data_wo"), &data).HasError())
+		assert.True(t, data.IsNull())
+	}
+
+	// The write-only data is sent to the API server when data_wo_version changes
+	update(1, 2, "new")
+	secret, err := clientSet.CloudV1alpha1().Secrets("sndev").Get(ctx, "secret", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"password": "new"}, secret.Data)
+
+	// The write-only data isn't sent again until data_wo_version changes
+	update(2, 2, "newer")
+	secret, err = clientSet.CloudV1alpha1().Secrets("sndev").Get(ctx, "secret", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"password": "new"}, secret.Data)
+}
+
+func TestSecretInstanceNameRequiresReplace(t *testing.T) {
+	ctx := context.Background()
+	s := testSecretSchema()
+	planInstanceName := func(prior, planned string) *planmodifier.StringResponse {
+		state := testSecretObjectValue(s, map[string]tftypes.Value{
+			"instance_name": tftypes.NewValue(tftypes.String, prior),
+		})
+		plan := testSecretObjectValue(s, map[string]tftypes.Value{
+			"instance_name": tftypes.NewValue(tftypes.String, planned),
+		})
+		req := planmodifier.StringRequest{
+			Path:        path.Root("instance_name"),
+			State:       tfsdk.State{Schema: s, Raw: state},
+			Plan:        tfsdk.Plan{Schema: s, Raw: plan},
+			Config:      tfsdk.Config{Schema: s, Raw: plan},
+			StateValue:  types.StringValue(prior),
+			PlanValue:   types.StringValue(planned),
+			ConfigValue: types.StringValue(planned),
+		}
+		resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+		for _, modifier := range s.Attributes["instance_name"].(schema.StringAttribute).PlanModifiers {
+			modifier.PlanModifyString(ctx, req, resp)
+		}
+		return resp
+	}
+	// Moving the secret to another instance changes its identity, so the secret is replaced
+	resp := planInstanceName("pulsar-instance-a", "pulsar-instance-b")
+	assert.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	assert.True(t, resp.RequiresReplace)
+	resp = planInstanceName("pulsar-instance-a", "pulsar-instance-a")
+	assert.False(t, resp.RequiresReplace)
+}
@@ -17,23 +17,20 @@ package cloud
 import (
 	"context"
 	"fmt"
-	"strings"
-	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	cloudclient "github.com/streamnative/cloud-api-server/pkg/client/clientset_generated/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func resourceServiceAccountBinding() *schema.Resource {
 	return &schema.Resource{
-		CreateContext: resourceServiceAccountBindingCreate,
-		ReadContext:   resourceServiceAccountBindingRead,
-		UpdateContext: resourceServiceAccountBindingUpdate,
-		DeleteContext: resourceServiceAccountBindingDelete,
+		CreateContext: withResourceIdentity(serviceAccountBindingEngine.create, organizationNameIdentity),
+		ReadContext:   withResourceIdentity(serviceAccountBindingEngine.read, organizationNameIdentity),
+		UpdateContext: withResourceIdentity(serviceAccountBindingEngine.update, organizationNameIdentity),
+		DeleteContext: serviceAccountBindingEngine.delete,
+		Identity:      newResourceIdentity(organizationNameIdentity),
 		CustomizeDiff: func(ctx context.Context, diff *schema.ResourceDiff, i interface{}) error {
 			oldOrg, _ := diff.GetChange("organization")
 			oldName, _ := diff.GetChange("name")
@@ -51,18 +48,8 @@ func resourceServiceAccountBinding() *schema.Resource {
 			}
 			return nil
 		},
-		Importer: &schema.ResourceImporter{
-			StateContext: func(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
-				organizationServiceAccount := strings.Split(d.Id(), "/")
-				_ = d.Set("organization", organizationServiceAccount[0])
-				_ = d.Set("name", organizationServiceAccount[1])
-				err := resourceServiceAccountBindingRead(ctx, d, meta)
-				if err.HasError() {
-					return nil, fmt.Errorf("import %q: %s", d.Id(), err[0].Summary)
-				}
-				return []*schema.ResourceData{d}, nil
-			},
-		},
+		Importer: resourceImporter(serviceAccountBindingEngine.read, resourceServiceAccountBinding),
+		Timeouts: resourceEngineTimeouts(),
 		Schema: map[string]*schema.Schema{
 			"organization": {
 				Type:         schema.TypeString,
@@ -115,122 +102,68 @@ func resourceServiceAccountBinding() *schema.Resource {
 	}
 }
 
-func resourceServiceAccountBindingCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
+var serviceAccountBindingEngine = &resourceEngine[*v1alpha1.ServiceAccountBinding]{
+	kind: "SERVICE_ACCOUNT_BINDING",
+	client: func(clientSet cloudclient.Interface, namespace string) resourceClient[*v1alpha1.ServiceAccountBinding] {
+		return clientSet.CloudV1alpha1().ServiceAccountBindings(namespace)
+	},
+	newObject: func() *v1alpha1.ServiceAccountBinding {
+		return &v1alpha1.ServiceAccountBinding{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "ServiceAccountBinding",
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			},
+		}
+	},
+	expand:    expandServiceAccountBinding,
+	flatten:   flattenServiceAccountBinding,
+	immutable: true,
+}
+
+// expandServiceAccountBinding sets the binding from the configuration, the pool member is the one of the
+// cluster when the cluster name is set and the name of the binding is derived from the pool member
+func expandServiceAccountBinding(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, sab *v1alpha1.ServiceAccountBinding,
+) error {
 	serviceAccountName := d.Get("service_account_name").(string)
 	clusterName := d.Get("cluster_name").(string)
 	poolMemberName := d.Get("pool_member_name").(string)
 	poolMemberNamespace := d.Get("pool_member_namespace").(string)
 	if poolMemberName == "" && poolMemberNamespace == "" && clusterName == "" {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_SERVICE_ACCOUNT_BINDING: " +
-			"either (pool_member_name & pool_member_namespace) or cluster_name must be provided"))
-	}
-	enableIAMAccountCreation := d.Get("enable_iam_account_creation").(bool)
-	awsAssumeRoleARNRawList := d.Get("aws_assume_role_arns").([]interface{})
-	awsAssumeRoleARNs := make([]string, len(awsAssumeRoleARNRawList))
-	for i, v := range awsAssumeRoleARNRawList {
-		awsAssumeRoleARNs[i] = v.(string)
-	}
-
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_CREATE_SERVICE_ACCOUNT_BINDING: %w", err))
+		return fmt.Errorf("either (pool_member_name & pool_member_namespace) or cluster_name must be provided")
 	}
-
 	if clusterName != "" {
-		pulsarCluster, err := clientSet.CloudV1alpha1().PulsarClusters(namespace).Get(ctx, clusterName, metav1.GetOptions{})
+		pulsarCluster, err := clientSet.CloudV1alpha1().PulsarClusters(sab.Namespace).Get(ctx, clusterName, metav1.GetOptions{})
 		if err != nil {
-			return diag.FromErr(fmt.Errorf("ERROR_READ_PULSAR_CLUSTER: %w", err))
+			return fmt.Errorf("ERROR_READ_PULSAR_CLUSTER: %w", err)
 		}
-
 		poolMemberNamespace = pulsarCluster.Spec.PoolMemberRef.Namespace
 		poolMemberName = pulsarCluster.Spec.PoolMemberRef.Name
 	}
-
-	name := fmt.Sprintf("%s.%s.%s", serviceAccountName, poolMemberNamespace, poolMemberName)
-	sab := &v1alpha1.ServiceAccountBinding{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "ServiceAccount",
-			APIVersion: v1alpha1.SchemeGroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-		},
-		Spec: v1alpha1.ServiceAccountBindingSpec{
-			ServiceAccountName: serviceAccountName,
-			PoolMemberRef: v1alpha1.PoolMemberReference{
-				Name:      poolMemberName,
-				Namespace: poolMemberNamespace,
-			},
-			EnableIAMAccountCreation: enableIAMAccountCreation,
-			AWSAssumeRoleARNs:        awsAssumeRoleARNs,
-		},
-	}
-	serviceAccountBinding, err := clientSet.CloudV1alpha1().ServiceAccountBindings(namespace).Create(ctx, sab, metav1.CreateOptions{
-		FieldManager: "terraform-create",
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_CREATE_SERVICE_ACCOUNT_BINDING: %w", err))
-	}
-	_ = d.Set("name", serviceAccountBinding.Name)
-	// Don't retry too frequently to avoid affecting the api-server.
-	err = retry.RetryContext(ctx, 5*time.Second, func() *retry.RetryError {
-		dia := resourceServiceAccountBindingRead(ctx, d, meta)
-		if dia.HasError() {
-			return retry.NonRetryableError(fmt.Errorf("ERROR_RETRY_CREATE_SERVICE_ACCOUNT_BINDING: %s", dia[0].Summary))
-		}
-		return nil
-	})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_RETRY_CREATE_SERVICE_ACCOUNT_BINDING: %w", err))
-	}
-	return nil
-}
-
-func resourceServiceAccountBindingRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_READ_SERVICE_ACCOUNT_BINDING: %w", err))
-	}
-	serviceAccountBinding, err := clientSet.CloudV1alpha1().ServiceAccountBindings(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			d.SetId("")
-			return nil
-		}
-		return diag.FromErr(fmt.Errorf("ERROR_READ_SERVICE_ACCOUNT_BINDING: %w", err))
-	}
-	_ = d.Set("name", serviceAccountBinding.Name)
-	_ = d.Set("organization", serviceAccountBinding.Namespace)
-	_ = d.Set("service_account_name", serviceAccountBinding.Spec.ServiceAccountName)
-	_ = d.Set("pool_member_name", serviceAccountBinding.Spec.PoolMemberRef.Name)
-	_ = d.Set("pool_member_namespace", serviceAccountBinding.Spec.PoolMemberRef.Namespace)
-	_ = d.Set("enable_iam_account_creation", serviceAccountBinding.Spec.EnableIAMAccountCreation)
-	_ = d.Set("aws_assume_role_arns", flattenStringSlice(serviceAccountBinding.Spec.AWSAssumeRoleARNs))
-	d.SetId(fmt.Sprintf("%s/%s", serviceAccountBinding.Namespace, serviceAccountBinding.Name))
-
-	return nil
-}
-
-func resourceServiceAccountBindingDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	clientSet, err := getClientSet(getFactoryFromMeta(meta))
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("ERROR_INIT_CLIENT_ON_DELETE_SERVICE_ACCOUNT_BINDING: %w", err))
+	awsAssumeRoleARNRawList := d.Get("aws_assume_role_arns").([]interface{})
+	awsAssumeRoleARNs := make([]string, len(awsAssumeRoleARNRawList))
+	for i, v := range awsAssumeRoleARNRawList {
+		awsAssumeRoleARNs[i] = v.(string)
 	}
-	namespace := d.Get("organization").(string)
-	name := d.Get("name").(string)
-	err = clientSet.CloudV1alpha1().ServiceAccountBindings(namespace).Delete(ctx, name, metav1.DeleteOptions{})
-	if err != nil {
-		return diag.FromErr(fmt.Errorf("DELETE_SERVICE_ACCOUNT_BINDING: %w", err))
+	sab.Name = fmt.Sprintf("%s.%s.%s", serviceAccountName, poolMemberNamespace, poolMemberName)
+	sab.Spec = v1alpha1.ServiceAccountBindingSpec{
+		ServiceAccountName: serviceAccountName,
+		PoolMemberRef: v1alpha1.PoolMemberReference{
+			Name:      poolMemberName,
+			Namespace: poolMemberNamespace,
+		},
+		EnableIAMAccountCreation: d.Get("enable_iam_account_creation").(bool),
+		AWSAssumeRoleARNs:        awsAssumeRoleARNs,
 	}
-	_ = d.Set("name", "")
 	return nil
 }
 
-func resourceServiceAccountBindingUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
-	return diag.FromErr(fmt.Errorf("ERROR_UPDATE_SERVICE_ACCOUNT_BINDING: " +
-		"The service account binding does not support updates, please recreate it"))
+func flattenServiceAccountBinding(
+	ctx context.Context, d *schema.ResourceData, clientSet cloudclient.Interface, sab *v1alpha1.ServiceAccountBinding,
+) error {
+	_ = d.Set("service_account_name", sab.Spec.ServiceAccountName)
+	_ = d.Set("pool_member_name", sab.Spec.PoolMemberRef.Name)
+	_ = d.Set("pool_member_namespace", sab.Spec.PoolMemberRef.Namespace)
+	_ = d.Set("enable_iam_account_creation", sab.Spec.EnableIAMAccountCreation)
+	return d.Set("aws_assume_role_arns", flattenStringSlice(sab.Spec.AWSAssumeRoleARNs))
 }
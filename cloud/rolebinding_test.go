@@ -3,13 +3,15 @@ package cloud
 import (
 	"context"
 	"fmt"
-	"github.com/google/uuid"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
 	"k8s.io/utils/pointer"
 	"strings"
 	"testing"
@@ -17,14 +19,12 @@ import (
 )
 
 func TestPredefinedBinding(t *testing.T) {
-	serviceAccount, err := uuid.NewRandom()
-	assert.NoError(t, err)
-	rolebindingName, err := uuid.NewRandom()
-	assert.NoError(t, err)
+	serviceAccount := testAccRandomString(t, 16)
+	rolebindingName := testAccRandomString(t, 16)
 
 	resource.Test(t, resource.TestCase{
-		PreCheck:          func() { testAccPreCheck(t) },
-		ProviderFactories: testAccProviderFactories,
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
 		CheckDestroy: func(state *terraform.State) error {
 			time.Sleep(5 * time.Second)
 			for _, rs := range state.RootModule().Resources {
@@ -74,7 +74,7 @@ data "streamnative_rolebinding" "rolebinding_demo" {
   organization = streamnative_rolebinding.rolebinding_demo.organization
   name         = streamnative_rolebinding.rolebinding_demo.name
 }
-`, serviceAccount, rolebindingName, serviceAccount),
+`, "tf-acc-"+serviceAccount, "tf-acc-"+rolebindingName, "tf-acc-"+serviceAccount),
 				Check: func(state *terraform.State) error {
 					rs, ok := state.RootModule().Resources["streamnative_rolebinding.rolebinding_demo"]
 					if !ok {
@@ -144,3 +144,79 @@ func TestRoleBinding_ConditionParse(t *testing.T) {
 	assert.Equal(t, expectRoleBinding.Spec, requestBinding.Spec)
 
 }
+
+func TestRoleBindingCreate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config map[string]interface{}
+		err    error
+		spec   v1alpha1.RoleBindingSpec
+	}{
+		{
+			name: "subjects",
+			config: map[string]interface{}{
+				"cluster_role_name":     "metrics-viewer",
+				"service_account_names": []interface{}{"sa-1"},
+				"user_names":            []interface{}{"user-1"},
+			},
+			spec: v1alpha1.RoleBindingSpec{
+				RoleRef: v1alpha1.RoleRef{APIGroup: "cloud.streamnative.io", Kind: "ClusterRole", Name: "metrics-viewer"},
+				Subjects: []v1alpha1.Subject{
+					{APIGroup: "cloud.streamnative.io", Kind: "ServiceAccount", Name: "sa-1"},
+					{APIGroup: "cloud.streamnative.io", Kind: "User", Name: "user-1"},
+				},
+			},
+		},
+		{
+			name: "conditions",
+			config: map[string]interface{}{
+				"cluster_role_name": "tenant-admin",
+				"condition_cel":     "srn.instance == 'a'",
+				"condition_resource_names": []interface{}{
+					map[string]interface{}{"instance": "ins-1", "cluster": "cluster-1"},
+				},
+			},
+			spec: v1alpha1.RoleBindingSpec{
+				RoleRef:  v1alpha1.RoleRef{APIGroup: "cloud.streamnative.io", Kind: "ClusterRole", Name: "tenant-admin"},
+				Subjects: []v1alpha1.Subject{},
+				CEL:      pointer.String("srn.instance == 'a'"),
+				ResourceNames: []v1alpha1.ResourceName{
+					{Organization: "sndev", Instance: "ins-1", Cluster: "cluster-1"},
+				},
+			},
+		},
+		{
+			name:   "api error",
+			config: map[string]interface{}{"cluster_role_name": "metrics-viewer"},
+			err: errors.NewForbidden(
+				v1alpha1.SchemeGroupVersion.WithResource("rolebindings").GroupResource(), "rb", fmt.Errorf("denied")),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			meta, clientSet := newFakeProviderMeta()
+			clientSet.PrependReactor("create", "rolebindings", markCreatedReady)
+			if tc.err != nil {
+				clientSet.PrependReactor("create", "rolebindings",
+					func(action k8stesting.Action) (bool, runtime.Object, error) {
+						return true, nil, tc.err
+					})
+			}
+			tc.config["organization"] = "sndev"
+			tc.config["name"] = "rb"
+			d := schema.TestResourceDataRaw(t, resourceRoleBinding().Schema, tc.config)
+			diags := roleBindingEngine.create(context.Background(), d, meta)
+			if tc.err != nil {
+				assert.True(t, diags.HasError())
+				assert.Contains(t, diags[0].Summary, "ERROR_CREATE_ROLEBINDING")
+				return
+			}
+			assert.False(t, diags.HasError(), diags)
+			assert.Equal(t, "sndev/rb", d.Id())
+			assert.Equal(t, true, d.Get("ready"))
+			roleBinding, err := clientSet.CloudV1alpha1().RoleBindings("sndev").Get(
+				context.Background(), "rb", metav1.GetOptions{})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.spec, roleBinding.Spec)
+		})
+	}
+}
@@ -22,7 +22,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -34,13 +33,13 @@ func TestSecret(t *testing.T) {
 		"username": "tf-user",
 		"password": "tf-password",
 	}
-	secretName := randomSecretName("terraform-test-secret")
+	secretName := randomSecretName(t, "tf-acc-secret")
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckSecretDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckSecretDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourceSecret("sndev", secretName, data),
@@ -57,13 +56,13 @@ func TestSecretStringData(t *testing.T) {
 		"username": "tf-user-string",
 		"password": "tf-password-string",
 	}
-	secretName := randomSecretName("terraform-test-secret-stringdata")
+	secretName := randomSecretName(t, "tf-acc-secret-stringdata")
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckSecretDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckSecretDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourceSecretWithStringData("sndev", secretName, stringData),
@@ -75,17 +74,58 @@ func TestSecretStringData(t *testing.T) {
 	})
 }
 
+func TestSecretWriteOnly(t *testing.T) {
+	data := map[string]string{
+		"username": "tf-user-wo",
+		"password": "tf-password-wo",
+	}
+	rotatedData := map[string]string{
+		"username": "tf-user-wo",
+		"password": "tf-password-wo-rotated",
+	}
+	secretName := randomSecretName(t, "tf-acc-secret-wo")
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckSecretDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testResourceSecretWriteOnly("sndev", secretName, data, 1),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSecretExists("streamnative_secret.test-secret", data),
+					resource.TestCheckNoResourceAttr("streamnative_secret.test-secret", "data.%"),
+					resource.TestCheckNoResourceAttr("streamnative_secret.test-secret", "data_wo.%"),
+				),
+			},
+			{
+				// The value isn't sent again until the version changes
+				Config:   testResourceSecretWriteOnly("sndev", secretName, rotatedData, 1),
+				PlanOnly: true,
+			},
+			{
+				Config: testResourceSecretWriteOnly("sndev", secretName, rotatedData, 2),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckSecretExists("streamnative_secret.test-secret", rotatedData),
+					resource.TestCheckResourceAttr("streamnative_secret.test-secret", "data_wo_version", "2"),
+				),
+			},
+		},
+	})
+}
+
 func TestSecretRemovedExternally(t *testing.T) {
 	data := map[string]string{
 		"token": "removed-secret",
 	}
-	secretName := randomSecretName("terraform-test-secret-remove")
+	secretName := randomSecretName(t, "tf-acc-secret-remove")
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckSecretDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckSecretDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourceSecret("sndev", secretName, data),
@@ -124,7 +164,7 @@ func TestSecretUpdate(t *testing.T) {
 		"username": "tf-user-updated",
 		"password": "tf-password-updated",
 	}
-	secretName := randomSecretName("terraform-test-secret-update")
+	secretName := randomSecretName(t, "tf-acc-secret-update")
 	initialType := "Opaque"
 	updatedType := "kubernetes.io/basic-auth"
 	initialInstance := "pulsar-instance-a"
@@ -134,8 +174,8 @@ func TestSecretUpdate(t *testing.T) {
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckSecretDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckSecretDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourceSecretWithParams("sndev", secretName, initialData, nil, initialType, initialInstance),
@@ -144,6 +184,7 @@ func TestSecretUpdate(t *testing.T) {
 				),
 			},
 			{
+				// The secret is replaced because the instance name is a part of its identity
 				Config: testResourceDataSourceSecretWithParams("sndev", secretName, nil, updatedStringData, updatedType, updatedInstance),
 				Check: resource.ComposeTestCheckFunc(
 					testCheckSecretStateWithEncryptedData("streamnative_secret.test-secret", updatedStringData, &updatedType, &updatedInstance),
@@ -269,6 +310,21 @@ data "streamnative_secret" "test-secret" {
 `, resourceBuilder.String())
 }
 
+func testResourceSecretWriteOnly(organization string, name string, data map[string]string, version int) string {
+	return fmt.Sprintf(`
+provider "streamnative" {
+}
+
+resource "streamnative_secret" "test-secret" {
+  organization = "%s"
+  name = "%s"
+  data_wo = {
+%s  }
+  data_wo_version = %d
+}
+`, organization, name, buildHCLMap(data), version)
+}
+
 func buildHCLMap(values map[string]string) string {
 	keys := make([]string, 0, len(values))
 	for k := range values {
@@ -359,6 +415,6 @@ func checkSecretData(secretData map[string]string, expectedData map[string]strin
 	return nil
 }
 
-func randomSecretName(prefix string) string {
-	return fmt.Sprintf("%s-%s", prefix, acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum))
+func randomSecretName(t *testing.T, prefix string) string {
+	return fmt.Sprintf("%s-%s", prefix, testAccRandomString(t, 8))
 }
@@ -17,7 +17,6 @@ package cloud
 import (
 	"context"
 	"fmt"
-	"math/rand"
 	"strings"
 	"testing"
 	"time"
@@ -28,15 +27,15 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-var saGeneratedName = fmt.Sprintf("t-%d-%d", rand.Intn(1000), rand.Intn(100))
-
 func TestServiceAccountBinding(t *testing.T) {
+	var saGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckServiceAccountBindingDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckServiceAccountBindingDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourceServiceAccountBinding(
@@ -53,14 +52,16 @@ func TestServiceAccountBinding(t *testing.T) {
 }
 
 func TestServiceAccountBindingRemovedExternally(t *testing.T) {
+	var saGeneratedName = fmt.Sprintf("tf-acc-%d-%d",
+		testAccRandomInt(t, 1000), testAccRandomInt(t, 100))
 	// This test case is to simulate the situation that the service account binding is removed externally
 	// and the terraform state still has the resource
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckServiceAccountBindingDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckServiceAccountBindingDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourceServiceAccountBinding(
@@ -21,7 +21,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -29,13 +28,13 @@ import (
 )
 
 func TestServiceAccount(t *testing.T) {
-	serviceAccountName := randomServiceAccountName("terraform-test-service-account")
+	serviceAccountName := randomServiceAccountName(t, "tf-acc-service-account")
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckServiceAccountDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckServiceAccountDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourceServiceAccount(
@@ -49,15 +48,15 @@ func TestServiceAccount(t *testing.T) {
 }
 
 func TestServiceAccountRemovedExternally(t *testing.T) {
-	serviceAccountName := randomServiceAccountName("terraform-test-service-account-remove")
+	serviceAccountName := randomServiceAccountName(t, "tf-acc-service-account-remove")
 	// This test case is to simulate the situation that the service account is removed externally
 	// and the terraform state still has the resource
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
 		},
-		ProviderFactories: testAccProviderFactories,
-		CheckDestroy:      testCheckServiceAccountDestroy,
+		ProtoV5ProviderFactories: testAccProtoV5ProviderFactories,
+		CheckDestroy:             testCheckServiceAccountDestroy,
 		Steps: []resource.TestStep{
 			{
 				Config: testResourceDataSourceServiceAccount(
@@ -165,6 +164,6 @@ data "streamnative_service_account" "test-service-account" {
 `, organization, name, admin)
 }
 
-func randomServiceAccountName(prefix string) string {
-	return fmt.Sprintf("%s-%s", prefix, acctest.RandStringFromCharSet(8, acctest.CharSetAlphaNum))
+func randomServiceAccountName(t *testing.T, prefix string) string {
+	return fmt.Sprintf("%s-%s", prefix, testAccRandomString(t, 8))
 }
@@ -0,0 +1,211 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	cloudv1alpha1 "github.com/streamnative/cloud-api-server/pkg/apis/cloud/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sschema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// testAccNamePrefix is the prefix of the names generated by the acceptance tests, the sweepers only
+// delete the objects whose names start with it
+const testAccNamePrefix = "tf-acc-"
+
+// TestMain runs the sweepers instead of the tests with `go test ./cloud -v -sweep=<organization>`
+func TestMain(m *testing.M) {
+	resource.TestMain(m)
+}
+
+func init() {
+	// The dependencies are swept first, e.g. the clusters are deleted before their instance
+	resource.AddTestSweepers("streamnative_apikey", &resource.Sweeper{
+		Name: "streamnative_apikey",
+		F:    sweepApiKeys,
+	})
+	addTestSweeper("rolebinding", "rolebindings")
+	addTestSweeper("service_account_binding", "serviceaccountbindings")
+	addTestSweeper("secret", "secrets")
+	addTestSweeper("pulsar_cluster", "pulsarclusters")
+	addTestSweeper("pulsar_gateway", "pulsargateways", "pulsar_cluster")
+	addTestSweeper("volume", "volumes", "pulsar_cluster")
+	addTestSweeper("catalog", "catalogs", "pulsar_cluster")
+	addTestSweeper("pulsar_instance", "pulsarinstances", "pulsar_cluster", "apikey", "secret")
+	addTestSweeper("service_account", "serviceaccounts", "apikey", "rolebinding", "service_account_binding")
+}
+
+// addTestSweeper adds the sweeper of a resource whose objects are deleted by sweepKind, the
+// dependencies are the type names of the resources without the provider prefix
+func addTestSweeper(typeName, apiResource string, dependencies ...string) {
+	name := "streamnative_" + typeName
+	for i, dependency := range dependencies {
+		dependencies[i] = "streamnative_" + dependency
+	}
+	resource.AddTestSweepers(name, &resource.Sweeper{
+		Name:         name,
+		Dependencies: dependencies,
+		F:            sweepKind(strings.ReplaceAll(typeName, "_", " "), apiResource),
+	})
+}
+
+// sweepKind returns the sweeper of the objects of an API resource, e.g. pulsarclusters, the objects
+// are listed and deleted by the dynamic client so every kind is swept the same way
+func sweepKind(kind, apiResource string) func(string) error {
+	return sweeper(func(ctx context.Context, factory *providerFactory, organization string) error {
+		dynamicClient, err := getDynamicClient(factory)
+		if err != nil {
+			return err
+		}
+		objects := dynamicClient.Resource(k8sschema.GroupVersionResource{
+			Group:    cloudv1alpha1.ApiVersion.GroupVersion.Group,
+			Version:  cloudv1alpha1.ApiVersion.GroupVersion.Version,
+			Resource: apiResource,
+		}).Namespace(organization)
+		list, err := objects.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(list.Items))
+		for _, item := range list.Items {
+			names = append(names, item.GetName())
+		}
+		return sweepObjects(ctx, organization, kind, names,
+			func(ctx context.Context, name string, options metav1.GetOptions) (*unstructured.Unstructured, error) {
+				return objects.Get(ctx, name, options)
+			},
+			func(ctx context.Context, name string, options metav1.DeleteOptions) error {
+				return objects.Delete(ctx, name, options)
+			})
+	})
+}
+
+// sweepApiKeys revokes the api keys before deleting them, so the leaked tokens of the aborted
+// tests can't be used while the api keys are being deleted
+func sweepApiKeys(organization string) error {
+	return sweeper(func(ctx context.Context, factory *providerFactory, organization string) error {
+		clientSet, err := getClientSet(factory)
+		if err != nil {
+			return err
+		}
+		apiKeys := clientSet.CloudV1alpha1().APIKeys(organization)
+		list, err := apiKeys.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(list.Items))
+		var errs []error
+		for i := range list.Items {
+			apiKey := &list.Items[i]
+			if !isTestAccName(apiKey.Name) {
+				continue
+			}
+			names = append(names, apiKey.Name)
+			if apiKey.Spec.Revoke {
+				continue
+			}
+			apiKey.Spec.Revoke = true
+			if _, err := apiKeys.Update(ctx, apiKey, metav1.UpdateOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to revoke the api key %s/%s: %w", organization, apiKey.Name, err))
+			}
+		}
+		errs = append(errs, sweepObjects(ctx, organization, "api key", names, apiKeys.Get, apiKeys.Delete))
+		return errors.Join(errs...)
+	})(organization)
+}
+
+// sweeper returns the function of a sweeper, the client is configured like the acceptance tests
+func sweeper(
+	sweep func(ctx context.Context, factory *providerFactory, organization string) error) func(string) error {
+	return func(organization string) error {
+		provider := Provider()
+		diags := provider.Configure(context.Background(), terraform.NewResourceConfigRaw(map[string]interface{}{}))
+		if diags.HasError() {
+			return fmt.Errorf("failed to configure the provider: %v", diags)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		return sweep(ctx, getFactoryFromMeta(provider.Meta()), organization)
+	}
+}
+
+// sweepObjects deletes the objects created by the acceptance tests and waits until they are gone,
+// the objects which depend on them can't be deleted before
+func sweepObjects[T any](
+	ctx context.Context,
+	organization, kind string,
+	names []string,
+	get func(context.Context, string, metav1.GetOptions) (T, error),
+	del func(context.Context, string, metav1.DeleteOptions) error,
+) error {
+	var errs []error
+	var deleted []string
+	for _, name := range names {
+		if !isTestAccName(name) {
+			continue
+		}
+		log.Printf("[INFO] Deleting the %s %s/%s", kind, organization, name)
+		if err := del(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete the %s %s/%s: %w", kind, organization, name, err))
+			continue
+		}
+		deleted = append(deleted, name)
+	}
+	for _, name := range deleted {
+		err := retry.RetryContext(ctx, 20*time.Minute, func() *retry.RetryError {
+			_, err := get(ctx, name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			if err != nil {
+				return retry.NonRetryableError(err)
+			}
+			return retry.RetryableError(fmt.Errorf("the %s %s/%s is being deleted", kind, organization, name))
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isTestAccName(name string) bool {
+	return strings.HasPrefix(name, testAccNamePrefix)
+}
+
+func TestIsTestAccName(t *testing.T) {
+	for name, expected := range map[string]bool{
+		randomSecretName(t, "tf-acc-secret"):                                             true,
+		fmt.Sprintf("tf-acc-%d-%d", testAccRandomInt(t, 1000), testAccRandomInt(t, 100)): true,
+		"production-cluster":    false,
+		"terraform-test-secret": false,
+		"t-123-45":              false,
+	} {
+		assert.Equal(t, expected, isTestAccName(name), name)
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	httpLogSubsystem  = "streamnative_http"
+	logMaskedValue    = "***"
+	httpClientTimeout = 30 * time.Second
+)
+
+// sensitiveLogFieldKeys are the headers and the body fields which values are never logged, i.e. the
+// credentials, the service account private keys, the API key tokens and JWE payloads and the secret data
+var sensitiveLogFieldKeys = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"private_key_data",
+	"privateKeyData",
+	"token",
+	"jwe",
+	"encryptedToken",
+	"access_token",
+	"refresh_token",
+	"data",
+	"string_data",
+	"stringData",
+}
+
+// newBackoff returns the exponential backoff with jitter used to retry the API calls
+func newBackoff(maxRetries int) wait.Backoff {
+	return wait.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Jitter:   0.5,
+		Steps:    maxRetries + 1,
+		Cap:      30 * time.Second,
+	}
+}
+
+// newHTTPClient returns the client used for the calls outside the API server, e.g. the token exchange
+// with the issuer, the certificate authority data of the API server is trusted on top of the system roots
+func newHTTPClient(certificateAuthorityData string) (*http.Client, error) {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if certificateAuthorityData != "" && !rootCAs.AppendCertsFromPEM([]byte(certificateAuthorityData)) {
+		return nil, fmt.Errorf("failed to parse the certificate authority data")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    rootCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   httpClientTimeout,
+	}, nil
+}
+
+// headerTransport sets the headers on all the requests
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func newHeaderTransport(base http.RoundTripper, headers map[string]string) http.RoundTripper {
+	return &headerTransport{
+		base:    base,
+		headers: headers,
+	}
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// retryTransport retries the requests throttled(429) or failed(5xx) by the API server with the
+// exponential backoff and jitter, the failed requests are only retried for the idempotent methods
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	backoff    wait.Backoff
+}
+
+func newRetryTransport(base http.RoundTripper, maxRetries int) http.RoundTripper {
+	return &retryTransport{
+		base:       base,
+		maxRetries: maxRetries,
+		backoff:    newBackoff(maxRetries),
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	backoff := t.backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.maxRetries || !shouldRetry(req, resp) {
+			return resp, err
+		}
+		// The request can't be retried if the body can't be replayed
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, err
+		}
+		delay := backoff.Step()
+		if retryAfter := retryAfterDelay(resp); retryAfter > delay {
+			delay = retryAfter
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+func shouldRetry(req *http.Request, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		switch req.Method {
+		case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+			return true
+		}
+	}
+	return false
+}
+
+func retryAfterDelay(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// loggingTransport logs the API requests and responses through the tflog subsystem, the method, URL,
+// status and latency are logged at the DEBUG level and the headers and bodies at the TRACE level
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func newLoggingTransport(base http.RoundTripper) http.RoundTripper {
+	return &loggingTransport{base: base}
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := tflog.NewSubsystem(req.Context(), httpLogSubsystem)
+	ctx = tflog.SubsystemMaskFieldValuesWithFieldKeys(ctx, httpLogSubsystem, sensitiveLogFieldKeys...)
+	fields := map[string]interface{}{
+		"http.method": req.Method,
+		"http.url":    req.URL.String(),
+	}
+	tflog.SubsystemDebug(ctx, httpLogSubsystem, "Sending API request", fields)
+
+	// The bodies are only read when the TRACE logs are written, the request of the caller is never changed
+	trace := traceLogEnabled()
+	if trace {
+		req = req.Clone(req.Context())
+		body, err := readBody(&req.Body)
+		if err != nil {
+			return nil, err
+		}
+		tflog.SubsystemTrace(ctx, httpLogSubsystem, "API request details",
+			headerFields(req.Header), map[string]interface{}{"http.request.body": redactBody(body)})
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	fields["http.duration_ms"] = time.Since(start).Milliseconds()
+	if err != nil {
+		fields["error"] = err.Error()
+		tflog.SubsystemDebug(ctx, httpLogSubsystem, "API request failed", fields)
+		return resp, err
+	}
+	fields["http.status_code"] = resp.StatusCode
+	tflog.SubsystemDebug(ctx, httpLogSubsystem, "Received API response", fields)
+
+	if trace {
+		body, err := readBody(&resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		tflog.SubsystemTrace(ctx, httpLogSubsystem, "API response details",
+			headerFields(resp.Header), map[string]interface{}{"http.response.body": redactBody(body)})
+	}
+	return resp, nil
+}
+
+// traceLogEnabled reports whether the TRACE logs of the provider are written, the level is read from
+// the most specific of the environment variables of Terraform like the provider logger does
+func traceLogEnabled() bool {
+	for _, name := range []string{"TF_LOG_PROVIDER_STREAMNATIVE", "TF_LOG_PROVIDER", "TF_LOG"} {
+		if level := os.Getenv(name); level != "" {
+			// The JSON format of TF_LOG writes the logs at the TRACE level
+			return strings.EqualFold(level, "TRACE") || strings.EqualFold(level, "JSON")
+		}
+	}
+	return false
+}
+
+// readBody reads the whole body and replaces it with a copy so it can still be consumed
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// headerFields returns the headers as the log fields keyed by the header names, so the sensitive
+// headers are masked by the subsystem
+func headerFields(header http.Header) map[string]interface{} {
+	fields := make(map[string]interface{}, len(header))
+	for key, values := range header {
+		if len(values) == 1 {
+			fields[key] = values[0]
+		} else {
+			fields[key] = values
+		}
+	}
+	return fields
+}
+
+// redactBody masks the sensitive fields in the JSON body, tflog only masks the top level log fields
+// so the nested fields of the API objects are masked here with the same keys
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var object interface{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		// The bodies are JSON for the API calls, any other content is not logged in case it is sensitive
+		return logMaskedValue
+	}
+	redacted, err := json.Marshal(redactValue(object))
+	if err != nil {
+		return logMaskedValue
+	}
+	return string(redacted)
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitiveLogField(key) {
+				v[key] = logMaskedValue
+			} else {
+				v[key] = redactValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	return value
+}
+
+func isSensitiveLogField(key string) bool {
+	for _, sensitiveKey := range sensitiveLogFieldKeys {
+		if key == sensitiveKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func newTestRetryTransport(maxRetries int) *retryTransport {
+	return &retryTransport{
+		base:       http.DefaultTransport,
+		maxRetries: maxRetries,
+		backoff:    wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: maxRetries + 1},
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"kind":"Secret"}`, string(body))
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newTestRetryTransport(3)}
+	req, err := http.NewRequest(http.MethodPut, server.URL, strings.NewReader(`{"kind":"Secret"}`))
+	assert.NoError(t, err)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetryTransportNonIdempotent(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newTestRetryTransport(3)}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	resp, err = client.Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&calls))
+}
+
+func TestHeaderTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "correlation-id", r.Header.Get(CorrelationIDHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newHeaderTransport(http.DefaultTransport, map[string]string{
+		CorrelationIDHeader: "correlation-id",
+	})}
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestLoggingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"kind":"Secret"}`, string(body))
+		_, _ = w.Write([]byte(`{"kind":"Secret","data":{"key":"value"}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("TF_LOG_PROVIDER", "")
+	t.Setenv("TF_LOG_PROVIDER_STREAMNATIVE", "")
+	transport := newLoggingTransport(http.DefaultTransport)
+	for _, level := range []string{"TRACE", "DEBUG", ""} {
+		t.Setenv("TF_LOG", level)
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"kind":"Secret"}`))
+		assert.NoError(t, err)
+		requestBody := req.Body
+		resp, err := transport.RoundTrip(req)
+		assert.NoError(t, err)
+		// The request of the caller is never changed
+		assert.Same(t, requestBody, req.Body, level)
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"kind":"Secret","data":{"key":"value"}}`, string(body), level)
+	}
+}
+
+func TestTraceLogEnabled(t *testing.T) {
+	t.Setenv("TF_LOG", "")
+	t.Setenv("TF_LOG_PROVIDER", "")
+	t.Setenv("TF_LOG_PROVIDER_STREAMNATIVE", "")
+	assert.False(t, traceLogEnabled())
+	t.Setenv("TF_LOG", "trace")
+	assert.True(t, traceLogEnabled())
+	t.Setenv("TF_LOG", "JSON")
+	assert.True(t, traceLogEnabled())
+	// The level of the provider overrides the level of Terraform
+	t.Setenv("TF_LOG_PROVIDER", "DEBUG")
+	assert.False(t, traceLogEnabled())
+	t.Setenv("TF_LOG_PROVIDER_STREAMNATIVE", "TRACE")
+	assert.True(t, traceLogEnabled())
+}
+
+func TestRedactBody(t *testing.T) {
+	assert.Equal(t, "", redactBody(nil))
+	assert.Equal(t, logMaskedValue, redactBody([]byte("client_secret=secret")))
+	assert.JSONEq(t, `{
+		"kind": "APIKey",
+		"metadata": {"name": "test"},
+		"status": {"encryptedToken": "***", "privateKeyData": "***"},
+		"items": [{"data": "***", "stringData": "***", "type": "Opaque"}]
+	}`, redactBody([]byte(`{
+		"kind": "APIKey",
+		"metadata": {"name": "test"},
+		"status": {"encryptedToken": {"jwe": "payload"}, "privateKeyData": "key"},
+		"items": [{"data": {"key": "dmFsdWU="}, "stringData": {"key": "value"}, "type": "Opaque"}]
+	}`)))
+}
@@ -15,12 +15,14 @@
 package cloud
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	utilValidation "k8s.io/apimachinery/pkg/util/validation"
 )
 
@@ -32,6 +34,29 @@ func validateNotBlank(val interface{}, key string) (warns []string, errs []error
 	return
 }
 
+// notBlankValidator is the validateNotBlank of the framework resources
+type notBlankValidator struct{}
+
+var _ validator.String = notBlankValidator{}
+
+func (v notBlankValidator) Description(_ context.Context) string {
+	return "must not be empty"
+}
+
+func (v notBlankValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v notBlankValidator) ValidateString(
+	_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, errs := validateNotBlank(req.ConfigValue.ValueString(), req.Path.String()); len(errs) > 0 {
+		resp.Diagnostics.AddAttributeError(req.Path, "Invalid Attribute Value", errs[0].Error())
+	}
+}
+
 func validateEngine(val interface{}, key string) (warns []string, errs []error) {
 	v := val.(string)
 	if v != "" && v != "ursa" {
@@ -0,0 +1,175 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/streamnative/cloud-cli/pkg/auth"
+	"golang.org/x/oauth2"
+)
+
+const (
+	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	GrantTypeJWTBearer     = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	TokenTypeJWT           = "urn:ietf:params:oauth:token-type:jwt"
+	TokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// workloadIdentityGrantTypes maps the provider 'grant_type' values to the OAuth2 grant types
+var workloadIdentityGrantTypes = map[string]string{
+	"token-exchange": GrantTypeTokenExchange,
+	"jwt-bearer":     GrantTypeJWTBearer,
+}
+
+// workloadIdentityExchanger exchanges the JWT issued to the workload by an external identity provider,
+// e.g. GitHub Actions or Kubernetes, for a StreamNative Cloud access token
+type workloadIdentityExchanger struct {
+	issuer     auth.Issuer
+	grantType  string
+	tokenFile  string
+	tokenEnv   string
+	httpClient *http.Client
+}
+
+type tokenEndpointResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func newWorkloadIdentityExchanger(
+	config map[string]interface{}, issuerEndpoint, audience string,
+	httpClient *http.Client) (*workloadIdentityExchanger, error) {
+	tokenFile := config["token_file"].(string)
+	tokenEnv := config["token_env"].(string)
+	if (tokenFile == "") == (tokenEnv == "") {
+		return nil, fmt.Errorf("exactly one of 'token_file' and 'token_env' must be set for workload identity")
+	}
+	grantType, ok := workloadIdentityGrantTypes[config["grant_type"].(string)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported workload identity grant type %q", config["grant_type"])
+	}
+	return &workloadIdentityExchanger{
+		issuer: auth.Issuer{
+			IssuerEndpoint: issuerEndpoint,
+			ClientID:       config["client_id"].(string),
+			Audience:       audience,
+		},
+		grantType:  grantType,
+		tokenFile:  tokenFile,
+		tokenEnv:   tokenEnv,
+		httpClient: httpClient,
+	}, nil
+}
+
+// Refresh exchanges the current workload JWT for a new grant, the JWT is read again on every exchange
+// as the identity providers rotate it
+func (e *workloadIdentityExchanger) Refresh(_ *auth.AuthorizationGrant) (*auth.AuthorizationGrant, error) {
+	subjectToken, err := e.readSubjectToken()
+	if err != nil {
+		return nil, err
+	}
+	tokenEndpoint, err := e.discoverTokenEndpoint()
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", e.grantType)
+	form.Set("audience", e.issuer.Audience)
+	if e.issuer.ClientID != "" {
+		form.Set("client_id", e.issuer.ClientID)
+	}
+	if e.grantType == GrantTypeTokenExchange {
+		form.Set("subject_token", subjectToken)
+		form.Set("subject_token_type", TokenTypeJWT)
+		form.Set("requested_token_type", TokenTypeAccessToken)
+	} else {
+		form.Set("assertion", subjectToken)
+	}
+	resp, err := e.httpClient.PostForm(tokenEndpoint, form)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange the workload identity token: %v", err)
+	}
+	defer resp.Body.Close()
+	tokenResponse := &tokenEndpointResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(tokenResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode the token endpoint response (%s): %v", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK || tokenResponse.AccessToken == "" {
+		return nil, fmt.Errorf("failed to exchange the workload identity token (%s): %s %s",
+			resp.Status, tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+	token := &oauth2.Token{
+		AccessToken: tokenResponse.AccessToken,
+		TokenType:   tokenResponse.TokenType,
+	}
+	if tokenResponse.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+	}
+	return &auth.AuthorizationGrant{
+		Type:  auth.AuthorizationGrantType(e.grantType),
+		Token: token,
+	}, nil
+}
+
+func (e *workloadIdentityExchanger) readSubjectToken() (string, error) {
+	var subjectToken string
+	if e.tokenFile != "" {
+		data, err := os.ReadFile(e.tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the workload identity token file: %v", err)
+		}
+		subjectToken = string(data)
+	} else {
+		subjectToken = os.Getenv(e.tokenEnv)
+	}
+	subjectToken = strings.TrimSpace(subjectToken)
+	if subjectToken == "" {
+		return "", fmt.Errorf("the workload identity token is empty")
+	}
+	return subjectToken, nil
+}
+
+// discoverTokenEndpoint finds the token endpoint from the OpenID configuration of the issuer
+func (e *workloadIdentityExchanger) discoverTokenEndpoint() (string, error) {
+	wellKnown := strings.TrimSuffix(e.issuer.IssuerEndpoint, "/") + "/.well-known/openid-configuration"
+	resp, err := e.httpClient.Get(wellKnown)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover the token endpoint: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to discover the token endpoint from %s: %s", wellKnown, resp.Status)
+	}
+	metadata := struct {
+		TokenEndpoint string `json:"token_endpoint"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return "", fmt.Errorf("failed to decode the OpenID configuration: %v", err)
+	}
+	if metadata.TokenEndpoint == "" {
+		return "", fmt.Errorf("the OpenID configuration of %s has no token endpoint", e.issuer.IssuerEndpoint)
+	}
+	return metadata.TokenEndpoint, nil
+}
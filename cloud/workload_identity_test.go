@@ -0,0 +1,163 @@
+// Copyright 2024 StreamNative, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloud
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newStubIssuer(t *testing.T, check func(r *http.Request)) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":         server.URL + "/",
+			"token_endpoint": server.URL + "/oauth/token",
+		})
+	})
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		check(r)
+		if r.PostForm.Get("subject_token") == "invalid" || r.PostForm.Get("assertion") == "invalid" {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"error":             "access_denied",
+				"error_description": "the subject token is not trusted",
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "exchanged-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+		})
+	})
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWorkloadIdentityTokenExchange(t *testing.T) {
+	server := newStubIssuer(t, func(r *http.Request) {
+		assert.Equal(t, GrantTypeTokenExchange, r.PostForm.Get("grant_type"))
+		assert.Equal(t, "workload-jwt", r.PostForm.Get("subject_token"))
+		assert.Equal(t, TokenTypeJWT, r.PostForm.Get("subject_token_type"))
+		assert.Equal(t, GlobalDefaultAudience, r.PostForm.Get("audience"))
+		assert.Equal(t, "workload-client", r.PostForm.Get("client_id"))
+	})
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("workload-jwt\n"), 0600))
+
+	exchanger, err := newWorkloadIdentityExchanger(map[string]interface{}{
+		"token_file": tokenFile,
+		"token_env":  "",
+		"grant_type": "token-exchange",
+		"client_id":  "workload-client",
+	}, server.URL+"/", GlobalDefaultAudience, server.Client())
+	assert.NoError(t, err)
+	grant, err := exchanger.Refresh(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "exchanged-token", grant.Token.AccessToken)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), grant.Token.Expiry, time.Minute)
+}
+
+func TestWorkloadIdentityJWTBearer(t *testing.T) {
+	server := newStubIssuer(t, func(r *http.Request) {
+		assert.Equal(t, GrantTypeJWTBearer, r.PostForm.Get("grant_type"))
+		assert.Empty(t, r.PostForm.Get("subject_token"))
+		assert.Empty(t, r.PostForm.Get("client_id"))
+	})
+	t.Setenv("TEST_WORKLOAD_IDENTITY_TOKEN", "workload-jwt")
+
+	exchanger, err := newWorkloadIdentityExchanger(map[string]interface{}{
+		"token_file": "",
+		"token_env":  "TEST_WORKLOAD_IDENTITY_TOKEN",
+		"grant_type": "jwt-bearer",
+		"client_id":  "",
+	}, server.URL, GlobalDefaultAudience, server.Client())
+	assert.NoError(t, err)
+	grant, err := exchanger.Refresh(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "exchanged-token", grant.Token.AccessToken)
+
+	t.Setenv("TEST_WORKLOAD_IDENTITY_TOKEN", "invalid")
+	_, err = exchanger.Refresh(nil)
+	assert.ErrorContains(t, err, "the subject token is not trusted")
+
+	t.Setenv("TEST_WORKLOAD_IDENTITY_TOKEN", "")
+	_, err = exchanger.Refresh(nil)
+	assert.ErrorContains(t, err, "the workload identity token is empty")
+}
+
+func TestWorkloadIdentityConfig(t *testing.T) {
+	_, err := newWorkloadIdentityExchanger(map[string]interface{}{
+		"token_file": "/var/run/secrets/token",
+		"token_env":  "TOKEN",
+		"grant_type": "token-exchange",
+		"client_id":  "",
+	}, GlobalDefaultIssuer, GlobalDefaultAudience, http.DefaultClient)
+	assert.Error(t, err)
+
+	_, err = newWorkloadIdentityExchanger(map[string]interface{}{
+		"token_file": "",
+		"token_env":  "",
+		"grant_type": "token-exchange",
+		"client_id":  "",
+	}, GlobalDefaultIssuer, GlobalDefaultAudience, http.DefaultClient)
+	assert.Error(t, err)
+}
+
+func TestWorkloadIdentityCertificateAuthorityData(t *testing.T) {
+	server := newStubIssuer(t, func(r *http.Request) {})
+	t.Setenv("TEST_WORKLOAD_IDENTITY_TOKEN", "workload-jwt")
+	config := map[string]interface{}{
+		"token_file": "",
+		"token_env":  "TEST_WORKLOAD_IDENTITY_TOKEN",
+		"grant_type": "token-exchange",
+		"client_id":  "",
+	}
+
+	// The issuer is trusted with the certificate authority data of the provider
+	certificateAuthorityData := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	}))
+	httpClient, err := newHTTPClient(certificateAuthorityData)
+	assert.NoError(t, err)
+	assert.Equal(t, httpClientTimeout, httpClient.Timeout)
+	exchanger, err := newWorkloadIdentityExchanger(config, server.URL, GlobalDefaultAudience, httpClient)
+	assert.NoError(t, err)
+	grant, err := exchanger.Refresh(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "exchanged-token", grant.Token.AccessToken)
+
+	httpClient, err = newHTTPClient("")
+	assert.NoError(t, err)
+	exchanger, err = newWorkloadIdentityExchanger(config, server.URL, GlobalDefaultAudience, httpClient)
+	assert.NoError(t, err)
+	_, err = exchanger.Refresh(nil)
+	assert.ErrorContains(t, err, "certificate")
+
+	_, err = newHTTPClient("invalid")
+	assert.ErrorContains(t, err, "failed to parse the certificate authority data")
+}
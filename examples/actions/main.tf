@@ -0,0 +1,52 @@
+# // Licensed to the Apache Software Foundation (ASF) under one
+# // or more contributor license agreements.  See the NOTICE file
+# // distributed with this work for additional information
+# // regarding copyright ownership.  The ASF licenses this file
+# // to you under the Apache License, Version 2.0 (the
+# // "License"); you may not use this file except in compliance
+# // with the License.  You may obtain a copy of the License at
+# //
+# //   http://www.apache.org/licenses/LICENSE-2.0
+# //
+# // Unless required by applicable law or agreed to in writing,
+# // software distributed under the License is distributed on an
+# // "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+# // KIND, either express or implied.  See the License for the
+# // specific language governing permissions and limitations
+# // under the License.
+
+terraform {
+  required_providers {
+    streamnative = {
+      source = "streamnative/streamnative"
+    }
+  }
+}
+
+provider "streamnative" {
+  # Please replace path use your own key file path
+  key_file_path = "/path/to/your/service/account/key.json"
+}
+
+# Run `terraform apply -invoke=action.streamnative_pulsar_cluster_restart.restart` with Terraform 1.14
+# or later to restart the cluster
+action "streamnative_pulsar_cluster_restart" "restart" {
+  config {
+    organization = "sndev"
+    name = "test-cluster"
+  }
+}
+
+action "streamnative_apikey_revoke" "revoke" {
+  config {
+    organization = "sndev"
+    name = "test-apikey"
+  }
+}
+
+action "streamnative_service_account_rotate_key" "rotate" {
+  config {
+    organization = "sndev"
+    name = "test-service-account"
+  }
+}
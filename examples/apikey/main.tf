@@ -58,3 +58,12 @@ output "apikey" {
   sensitive = true
   value = data.streamnative_apikey.test-admin-a
 }
+
+# The token of the ephemeral api key is never saved in the state, the short-lived api key is revoked
+# and deleted after the run, it requires Terraform 1.10 or later
+ephemeral "streamnative_apikey_token" "test-admin-a" {
+  organization = "sndev"
+  instance_name = "terraform-test-api-key-pulsar-instance"
+  service_account_name = "test-tf-admin"
+  expiration_time = "1h"
+}
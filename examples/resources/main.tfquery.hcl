@@ -0,0 +1,35 @@
+# // Licensed to the Apache Software Foundation (ASF) under one
+# // or more contributor license agreements.  See the NOTICE file
+# // distributed with this work for additional information
+# // regarding copyright ownership.  The ASF licenses this file
+# // to you under the Apache License, Version 2.0 (the
+# // "License"); you may not use this file except in compliance
+# // with the License.  You may obtain a copy of the License at
+# //
+# //   http://www.apache.org/licenses/LICENSE-2.0
+# //
+# // Unless required by applicable law or agreed to in writing,
+# // software distributed under the License is distributed on an
+# // "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+# // KIND, either express or implied.  See the License for the
+# // specific language governing permissions and limitations
+# // under the License.
+
+# Run `terraform query -generate-config-out=generated.tf` with Terraform 1.14 or later to generate
+# the configuration and the import blocks of the listed resources
+
+list "streamnative_pulsar_cluster" "pulsarclusters" {
+  provider = streamnative
+  config {
+    organization = "sndev"
+  }
+}
+
+list "streamnative_service_account" "serviceaccounts" {
+  provider = streamnative
+  include_resource = true
+  config {
+    organization = "sndev"
+    label_selector = "env=prod"
+  }
+}
@@ -45,3 +45,17 @@ data "streamnative_secret" "example" {
   organization = streamnative_secret.example.organization
   name         = streamnative_secret.example.name
 }
+
+# The write-only data is never saved in the state, it requires Terraform 1.11 or later.
+# Increase data_wo_version to update the secret with the new value.
+resource "streamnative_secret" "write_only" {
+  organization  = "sndev"
+  name          = "tf-secret-wo"
+  instance_name = "pulsar-instance-name"
+  location      = "us-west2"
+  data_wo = {
+    username = "demo-user"
+    password = "demo-password"
+  }
+  data_wo_version = 1
+}
@@ -15,14 +15,20 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"log"
 
-	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov5/tf5server"
 	"github.com/streamnative/terraform-provider-streamnative/cloud"
 )
 
 var (
 	debugMode bool
+
+	// version and commit are set by goreleaser
+	version = "dev"
+	commit  = ""
 )
 
 func init() {
@@ -31,12 +37,16 @@ func init() {
 }
 
 func main() {
-	opts := &plugin.ServeOpts{
-		ProviderFunc: cloud.Provider,
+	cloud.ProviderVersion = version
+	server, err := cloud.NewProviderServer(context.Background(), cloud.Provider())
+	if err != nil {
+		log.Fatal(err)
 	}
+	var opts []tf5server.ServeOpt
 	if debugMode {
-		opts.Debug = true
-		opts.ProviderAddr = "registry.terraform.io/streamnative/streamnative"
+		opts = append(opts, tf5server.WithManagedDebug())
+	}
+	if err = tf5server.Serve("registry.terraform.io/streamnative/streamnative", server, opts...); err != nil {
+		log.Fatal(err)
 	}
-	plugin.Serve(opts)
 }